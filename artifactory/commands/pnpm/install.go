@@ -0,0 +1,381 @@
+package pnpm
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/build-info-go/build"
+	biutils "github.com/jfrog/build-info-go/build/utils"
+	"github.com/jfrog/build-info-go/entities"
+	gofrogcmd "github.com/jfrog/gofrog/io"
+	commandUtils "github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils/pnpm"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/auth"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/exp/slices"
+)
+
+const npmrcFileName = ".npmrc"
+const npmrcBackupFileName = "jfrog.npmrc.backup"
+
+type PnpmInstallCommand struct {
+	configFilePath   string
+	executablePath   string
+	workingDirectory string
+	repo             string
+	// Npm registry as exposed by Artifactory.
+	registry string
+	// Npm auth details generated by Artifactory using the user's provided credentials.
+	npmAuth            string
+	pnpmArgs           []string
+	threads            int
+	collectBuildInfo   bool
+	buildInfoBuild     *build.Build
+	moduleName         string
+	restoreNpmrcFunc   func() error
+	serverDetails      *config.ServerDetails
+	authArtDetails     auth.ServiceDetails
+	buildConfiguration *utils.BuildConfiguration
+}
+
+func NewPnpmInstallCommand() *PnpmInstallCommand {
+	return &PnpmInstallCommand{threads: 3}
+}
+
+func (pic *PnpmInstallCommand) CommandName() string {
+	return "rt_pnpm_install"
+}
+
+func (pic *PnpmInstallCommand) SetConfigFilePath(configFilePath string) *PnpmInstallCommand {
+	pic.configFilePath = configFilePath
+	return pic
+}
+
+func (pic *PnpmInstallCommand) SetArgs(args []string) *PnpmInstallCommand {
+	pic.pnpmArgs = args
+	return pic
+}
+
+func (pic *PnpmInstallCommand) SetThreads(threads int) *PnpmInstallCommand {
+	pic.threads = threads
+	return pic
+}
+
+func (pic *PnpmInstallCommand) SetRepo(repo string) *PnpmInstallCommand {
+	pic.repo = repo
+	return pic
+}
+
+func (pic *PnpmInstallCommand) SetServerDetails(serverDetails *config.ServerDetails) *PnpmInstallCommand {
+	pic.serverDetails = serverDetails
+	return pic
+}
+
+func (pic *PnpmInstallCommand) SetBuildConfiguration(buildConfiguration *utils.BuildConfiguration) *PnpmInstallCommand {
+	pic.buildConfiguration = buildConfiguration
+	return pic
+}
+
+func (pic *PnpmInstallCommand) SetRepoConfig(conf *utils.RepositoryConfig) *PnpmInstallCommand {
+	serverDetails, _ := conf.ServerDetails()
+	return pic.SetRepo(conf.TargetRepo()).SetServerDetails(serverDetails)
+}
+
+func (pic *PnpmInstallCommand) Init() error {
+	log.Info("Running pnpm install.")
+	// Read config file.
+	log.Debug("Preparing to read the config file", pic.configFilePath)
+	vConfig, err := utils.ReadConfigFile(pic.configFilePath, utils.YAML)
+	if err != nil {
+		return err
+	}
+	// Extract resolution params.
+	resolverParams, err := utils.GetRepoConfigByPrefix(pic.configFilePath, utils.ProjectConfigResolverPrefix, vConfig)
+	if err != nil {
+		return err
+	}
+	threads, filteredPnpmArgs, buildConfiguration, err := extractPnpmOptionsFromArgs(pic.pnpmArgs)
+	if err != nil {
+		return err
+	}
+	pic.SetRepoConfig(resolverParams).SetArgs(filteredPnpmArgs).SetThreads(threads).SetBuildConfiguration(buildConfiguration)
+	return nil
+}
+
+// Extracts the threads and the build-info options from the args, and returns the args left for pnpm.
+// The '--detailed-summary', '--scan' and '--format' options of the publish commands aren't supported, since pnpm install doesn't deploy artifacts.
+func extractPnpmOptionsFromArgs(args []string) (threads int, cleanArgs []string, buildConfiguration *utils.BuildConfiguration, err error) {
+	_, _, format, err := coreutils.FindFlag("--format", args)
+	if err != nil {
+		return
+	}
+	var detailedSummary, xrayScan bool
+	threads, detailedSummary, xrayScan, _, cleanArgs, buildConfiguration, err = commandUtils.ExtractYarnOptionsFromArgs(args)
+	if err != nil {
+		return
+	}
+	if detailedSummary || xrayScan || format != "" {
+		err = errorutils.CheckErrorf("the --detailed-summary, --scan and --format options are not supported by the pnpm install command")
+	}
+	return
+}
+
+func (pic *PnpmInstallCommand) ServerDetails() (*config.ServerDetails, error) {
+	return pic.serverDetails, nil
+}
+
+func (pic *PnpmInstallCommand) Run() (err error) {
+	if err = pic.preparePrerequisites(); err != nil {
+		return
+	}
+
+	if err = pic.prepareBuildInfo(); err != nil {
+		return
+	}
+
+	pic.restoreNpmrcFunc, err = commandUtils.BackupFile(filepath.Join(pic.workingDirectory, npmrcFileName), filepath.Join(pic.workingDirectory, npmrcBackupFileName))
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := pic.restoreNpmrcFunc()
+		if err == nil {
+			err = e
+		}
+	}()
+	if err = pic.createTempNpmrc(); err != nil {
+		return
+	}
+
+	if err = pic.runInstall(); err != nil {
+		return
+	}
+
+	if pic.collectBuildInfo {
+		if err = pic.collectDependencies(); err != nil {
+			return
+		}
+	}
+	log.Info("pnpm install finished successfully.")
+	return
+}
+
+func (pic *PnpmInstallCommand) preparePrerequisites() error {
+	log.Debug("Preparing prerequisites...")
+	var err error
+	pic.executablePath, err = exec.LookPath("pnpm")
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	log.Debug("Found pnpm executable at:", pic.executablePath)
+
+	pic.workingDirectory, err = coreutils.GetWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	log.Debug("Working directory set to:", pic.workingDirectory)
+
+	authArtDetails, err := pic.serverDetails.CreateArtAuthConfig()
+	if err != nil {
+		return err
+	}
+	if authArtDetails.GetSshAuthHeaders() != nil {
+		return errorutils.CheckErrorf("SSH authentication is not supported in this command")
+	}
+	pic.authArtDetails = authArtDetails
+
+	pic.npmAuth, pic.registry, err = commandUtils.GetArtifactoryNpmRepoDetails(pic.repo, &pic.authArtDetails)
+	return err
+}
+
+func (pic *PnpmInstallCommand) prepareBuildInfo() error {
+	var err error
+	pic.collectBuildInfo, err = pic.buildConfiguration.IsCollectBuildInfo()
+	if err != nil || !pic.collectBuildInfo {
+		return err
+	}
+	buildName, err := pic.buildConfiguration.GetBuildName()
+	if err != nil {
+		return err
+	}
+	buildNumber, err := pic.buildConfiguration.GetBuildNumber()
+	if err != nil {
+		return err
+	}
+	buildInfoService := utils.CreateBuildInfoService()
+	pic.buildInfoBuild, err = buildInfoService.GetOrCreateBuildWithProject(buildName, buildNumber, pic.buildConfiguration.GetProject())
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	pic.moduleName = pic.buildConfiguration.GetModule()
+	if pic.moduleName == "" {
+		packageInfo, err := biutils.ReadPackageInfoFromPackageJson(pic.workingDirectory, nil)
+		if err != nil {
+			return errorutils.CheckError(err)
+		}
+		pic.moduleName = packageInfo.BuildInfoModuleId()
+	}
+	return nil
+}
+
+// In order to make sure pnpm resolves packages from Artifactory, we create a .npmrc file in the project's directory.
+// The settings of the existing .npmrc file are kept, except for the registries and their credentials.
+func (pic *PnpmInstallCommand) createTempNpmrc() error {
+	log.Debug("Creating project .npmrc file.")
+	npmrcPath := filepath.Join(pic.workingDirectory, npmrcFileName)
+	existingConfig, err := os.ReadFile(npmrcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errorutils.CheckError(err)
+	}
+	configData, err := pic.prepareConfigData(existingConfig)
+	if err != nil {
+		return err
+	}
+	return errorutils.CheckError(os.WriteFile(npmrcPath, configData, 0600))
+}
+
+// Transforms the existing .npmrc configuration to a configuration that resolves all packages (including scoped packages) from Artifactory.
+func (pic *PnpmInstallCommand) prepareConfigData(existingConfig []byte) ([]byte, error) {
+	authIdent, err := extractAuthIdentFromNpmAuth(pic.npmAuth)
+	if err != nil {
+		return nil, err
+	}
+	registryUrl, err := url.Parse(pic.registry)
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	var npmrcConfig []string
+	scanner := bufio.NewScanner(strings.NewReader(string(existingConfig)))
+	for scanner.Scan() {
+		currOption := strings.TrimSpace(scanner.Text())
+		key := strings.TrimSpace(strings.SplitN(currOption, "=", 2)[0])
+		switch {
+		case key == "registry" || strings.HasPrefix(key, "//"):
+			// Replaced by the Artifactory registry and its credentials
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			// Override scoped registries (@scope:registry = xyz)
+			npmrcConfig = append(npmrcConfig, key+" = "+pic.registry)
+		default:
+			npmrcConfig = append(npmrcConfig, currOption)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	registryPath := "//" + registryUrl.Host + strings.TrimSuffix(registryUrl.Path, "/") + "/"
+	npmrcConfig = append(npmrcConfig, "registry = "+pic.registry, registryPath+":_auth = "+authIdent, registryPath+":always-auth = true")
+	return []byte(strings.Join(npmrcConfig, "\n") + "\n"), nil
+}
+
+func (pic *PnpmInstallCommand) runInstall() error {
+	log.Debug("Running pnpm install command.")
+	pnpmCmdConfig := &pnpm.PnpmConfig{
+		Executable: pic.executablePath,
+		Command:    append([]string{"install"}, pic.pnpmArgs...),
+	}
+	return errorutils.CheckError(gofrogcmd.RunCmd(pnpmCmdConfig))
+}
+
+// Collects the dependencies of the project from its pnpm-lock.yaml, and saves them in the build-info with their checksums from Artifactory.
+// The dependencies of the other projects in the workspace aren't collected.
+func (pic *PnpmInstallCommand) collectDependencies() error {
+	log.Info("Collecting dependencies information... For the first run of the build, this may take a few minutes. Subsequent runs should be faster.")
+	lockfile, err := pnpm.ReadLockfile(pic.workingDirectory)
+	if err != nil {
+		return err
+	}
+	importer, exists := lockfile.GetImporters()[pnpm.RootImporterPath]
+	if !exists {
+		return errorutils.CheckErrorf("couldn't find the project's dependencies in %s", pnpm.LockfileName)
+	}
+	dependenciesMap := getBuildInfoDependencies(lockfile, importer, pic.moduleName)
+
+	servicesManager, err := utils.CreateServiceManager(pic.serverDetails, -1, 0, false)
+	if err != nil {
+		return err
+	}
+	// Collect checksums from last build to decrease requests to Artifactory
+	buildName, err := pic.buildConfiguration.GetBuildName()
+	if err != nil {
+		return err
+	}
+	previousBuildDependencies, err := commandUtils.GetDependenciesFromLatestBuild(servicesManager, buildName)
+	if err != nil {
+		return err
+	}
+	missingDepsChan := make(chan string)
+	var missingDependencies []string
+	missingDepsDone := make(chan struct{})
+	go func() {
+		for depId := range missingDepsChan {
+			missingDependencies = append(missingDependencies, depId)
+		}
+		close(missingDepsDone)
+	}()
+	collectChecksumsFunc := commandUtils.CreateCollectChecksumsFunc(previousBuildDependencies, servicesManager, missingDepsChan)
+	buildInfoDependencies, err := biutils.TraverseDependencies(dependenciesMap, collectChecksumsFunc, pic.threads)
+	close(missingDepsChan)
+	<-missingDepsDone
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	commandUtils.PrintMissingDependencies(missingDependencies)
+
+	buildInfoModule := entities.Module{Id: pic.moduleName, Type: entities.Npm, Dependencies: buildInfoDependencies}
+	buildInfo := &entities.BuildInfo{Modules: []entities.Module{buildInfoModule}}
+	return errorutils.CheckError(pic.buildInfoBuild.SaveBuildInfo(buildInfo))
+}
+
+// Returns the build-info dependencies of the project by their IDs, with their scopes ('prod' or 'dev') and the paths from the project to each of them.
+func getBuildInfoDependencies(lockfile *pnpm.Lockfile, importer *pnpm.LockfileImporter, moduleName string) map[string]*entities.Dependency {
+	dependencies := make(map[string]*entities.Dependency)
+	for _, scope := range []string{"prod", "dev"} {
+		directDependencies, dependenciesGraph := lockfile.GetDependenciesGraph(importer, scope == "prod", scope == "dev")
+		for _, id := range directDependencies {
+			appendDependencyRecursively(id, []string{moduleName}, scope, dependenciesGraph, dependencies)
+		}
+	}
+	return dependencies
+}
+
+func appendDependencyRecursively(id string, pathToRoot []string, scope string, dependenciesGraph map[string][]string, dependencies map[string]*entities.Dependency) {
+	// To avoid infinite loops in case of circular dependencies, the dependency won't be added if it's already in pathToRoot
+	if slices.Contains(pathToRoot, id) {
+		return
+	}
+	dependency, exists := dependencies[id]
+	if !exists {
+		dependency = &entities.Dependency{Id: id}
+		dependencies[id] = dependency
+	}
+	if !slices.Contains(dependency.Scopes, scope) {
+		dependency.Scopes = append(dependency.Scopes, scope)
+	}
+	if len(dependency.RequestedBy) >= entities.RequestedByMaxLength {
+		return
+	}
+	dependency.RequestedBy = append(dependency.RequestedBy, pathToRoot)
+	for _, childId := range dependenciesGraph[id] {
+		appendDependencyRecursively(childId, append([]string{id}, pathToRoot...), scope, dependenciesGraph, dependencies)
+	}
+}
+
+// npmAuth we get back from Artifactory includes several fields, but we need only the field '_auth'
+func extractAuthIdentFromNpmAuth(npmAuth string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(npmAuth))
+	for scanner.Scan() {
+		lineParts := strings.SplitN(scanner.Text(), "=", 2)
+		if len(lineParts) == 2 && strings.TrimSpace(lineParts[0]) == "_auth" {
+			return strings.TrimSpace(lineParts[1]), nil
+		}
+	}
+	return "", errorutils.CheckErrorf("failed while retrieving npm auth details from Artifactory")
+}
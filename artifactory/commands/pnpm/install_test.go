@@ -0,0 +1,94 @@
+package pnpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/build-info-go/entities"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils/pnpm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareConfigData(t *testing.T) {
+	configBefore := []byte(
+		"auto-install-peers=true\n" +
+			"registry=http://somebadregistry\n" +
+			"//somebadregistry/:_authToken=ddddd\n" +
+			"@jfrog:registry=http://somebadregistry\n" +
+			"strict-peer-dependencies=false\n")
+
+	pic := PnpmInstallCommand{registry: "http://goodRegistry/artifactory/api/npm/npm-remote", npmAuth: "_auth = YWRtaW46cGFzc3dvcmQ=\nalways-auth = true\nemail = admin@jfrog.com"}
+	configAfter, err := pic.prepareConfigData(configBefore)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"auto-install-peers=true\n"+
+			"@jfrog:registry = http://goodRegistry/artifactory/api/npm/npm-remote\n"+
+			"strict-peer-dependencies=false\n"+
+			"registry = http://goodRegistry/artifactory/api/npm/npm-remote\n"+
+			"//goodRegistry/artifactory/api/npm/npm-remote/:_auth = YWRtaW46cGFzc3dvcmQ=\n"+
+			"//goodRegistry/artifactory/api/npm/npm-remote/:always-auth = true\n",
+		string(configAfter))
+
+	pic.npmAuth = "always-auth = true"
+	_, err = pic.prepareConfigData(configBefore)
+	assert.ErrorContains(t, err, "failed while retrieving npm auth details from Artifactory")
+}
+
+func TestExtractPnpmOptionsFromArgs(t *testing.T) {
+	threads, cleanArgs, buildConfiguration, err := extractPnpmOptionsFromArgs([]string{"--frozen-lockfile", "--threads=5", "--build-name=name", "--build-number=1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, threads)
+	assert.Equal(t, []string{"--frozen-lockfile"}, cleanArgs)
+	buildName, err := buildConfiguration.GetBuildName()
+	assert.NoError(t, err)
+	assert.Equal(t, "name", buildName)
+
+	for _, args := range [][]string{{"--detailed-summary"}, {"--scan"}, {"--format", "json"}, {"--format=table"}} {
+		_, _, _, err = extractPnpmOptionsFromArgs(args)
+		assert.ErrorContains(t, err, "not supported by the pnpm install command")
+	}
+}
+
+func TestGetBuildInfoDependencies(t *testing.T) {
+	projectDir := t.TempDir()
+	lockfileContent := `lockfileVersion: '6.0'
+
+dependencies:
+  react:
+    specifier: ^18.2.0
+    version: 18.2.0
+
+devDependencies:
+  loose-envify:
+    specifier: ^1.4.0
+    version: 1.4.0
+
+packages:
+
+  /js-tokens@4.0.0:
+    resolution: {integrity: sha512-1}
+
+  /loose-envify@1.4.0:
+    resolution: {integrity: sha512-2}
+    dependencies:
+      js-tokens: 4.0.0
+
+  /react@18.2.0:
+    resolution: {integrity: sha512-3}
+    dependencies:
+      loose-envify: 1.4.0
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, pnpm.LockfileName), []byte(lockfileContent), 0600))
+	lockfile, err := pnpm.ReadLockfile(projectDir)
+	assert.NoError(t, err)
+
+	dependencies := getBuildInfoDependencies(lockfile, lockfile.GetImporters()[pnpm.RootImporterPath], "my-project:1.0.0")
+	assert.Equal(t, map[string]*entities.Dependency{
+		"react:18.2.0": {Id: "react:18.2.0", Scopes: []string{"prod"}, RequestedBy: [][]string{{"my-project:1.0.0"}}},
+		"loose-envify:1.4.0": {Id: "loose-envify:1.4.0", Scopes: []string{"prod", "dev"},
+			RequestedBy: [][]string{{"react:18.2.0", "my-project:1.0.0"}, {"my-project:1.0.0"}}},
+		"js-tokens:4.0.0": {Id: "js-tokens:4.0.0", Scopes: []string{"prod", "dev"},
+			RequestedBy: [][]string{{"loose-envify:1.4.0", "react:18.2.0", "my-project:1.0.0"}, {"loose-envify:1.4.0", "my-project:1.0.0"}}},
+	}, dependencies)
+}
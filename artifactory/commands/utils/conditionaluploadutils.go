@@ -65,6 +65,14 @@ func GetXrayOutputFormat(formatFlagVal string) (format xrutils.OutputFormat, err
 			format = xrutils.SimpleJson
 		case string(xrutils.Sarif):
 			format = xrutils.Sarif
+		case string(xrutils.CycloneDx):
+			format = xrutils.CycloneDx
+		case string(xrutils.Spdx):
+			format = xrutils.Spdx
+		case string(xrutils.SpdxTagValue):
+			format = xrutils.SpdxTagValue
+		case string(xrutils.Junit):
+			format = xrutils.Junit
 		default:
 			err = errorutils.CheckErrorf("only the following output formats are supported: " + coreutils.ListToText(xrutils.OutputFormats))
 		}
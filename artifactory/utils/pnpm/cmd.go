@@ -0,0 +1,34 @@
+package pnpm
+
+import (
+	"io"
+	"os/exec"
+)
+
+type PnpmConfig struct {
+	Executable   string
+	Command      []string
+	CommandFlags []string
+	StrWriter    io.WriteCloser
+	ErrWriter    io.WriteCloser
+}
+
+func (pc *PnpmConfig) GetCmd() *exec.Cmd {
+	var cmd []string
+	cmd = append(cmd, pc.Executable)
+	cmd = append(cmd, pc.Command...)
+	cmd = append(cmd, pc.CommandFlags...)
+	return exec.Command(cmd[0], cmd[1:]...)
+}
+
+func (pc *PnpmConfig) GetEnv() map[string]string {
+	return map[string]string{}
+}
+
+func (pc *PnpmConfig) GetStdWriter() io.WriteCloser {
+	return pc.StrWriter
+}
+
+func (pc *PnpmConfig) GetErrWriter() io.WriteCloser {
+	return pc.ErrWriter
+}
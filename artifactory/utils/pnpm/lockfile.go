@@ -0,0 +1,208 @@
+package pnpm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	LockfileName = "pnpm-lock.yaml"
+	// The path of the root project in the importers of the lockfile.
+	RootImporterPath = "."
+)
+
+type Lockfile struct {
+	// A number in lockfile version 5 (like 5.4), and a string in later versions (like '6.0').
+	LockfileVersion interface{} `yaml:"lockfileVersion"`
+	// The projects of a workspace, by their paths relative to the lockfile.
+	// Lockfiles of projects that aren't workspaces (before lockfile version 9) have the root project's dependencies at the top level instead.
+	Importers        map[string]*LockfileImporter `yaml:"importers"`
+	LockfileImporter `yaml:",inline"`
+	// The installed packages, like '/lodash/4.17.21' (version 5), '/lodash@4.17.21' (version 6) or 'lodash@4.17.21' (version 9).
+	Packages map[string]*LockfilePackage `yaml:"packages"`
+	// The dependencies of the installed packages, in lockfile version 9 and above.
+	Snapshots map[string]*LockfilePackage `yaml:"snapshots"`
+}
+
+type LockfileImporter struct {
+	Dependencies         map[string]ImporterDependency `yaml:"dependencies"`
+	DevDependencies      map[string]ImporterDependency `yaml:"devDependencies"`
+	OptionalDependencies map[string]ImporterDependency `yaml:"optionalDependencies"`
+}
+
+// ImporterDependency is the resolved version of a project's dependency.
+type ImporterDependency string
+
+// Lockfile version 5 has the resolved versions as strings, and later versions have them with the specifiers, like {specifier: ^4.17.0, version: 4.17.21}.
+func (id *ImporterDependency) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var version string
+	if err := unmarshal(&version); err == nil {
+		*id = ImporterDependency(version)
+		return nil
+	}
+	var dependency struct {
+		Version string `yaml:"version"`
+	}
+	if err := unmarshal(&dependency); err != nil {
+		return err
+	}
+	*id = ImporterDependency(dependency.Version)
+	return nil
+}
+
+type LockfilePackage struct {
+	// The name and version are recorded only for packages that aren't installed from the registry, like Git repositories and tarballs.
+	Name                 string            `yaml:"name"`
+	Version              string            `yaml:"version"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// ReadLockfile reads the pnpm-lock.yaml file in projectDir.
+func ReadLockfile(projectDir string) (*Lockfile, error) {
+	lockfilePath := filepath.Join(projectDir, LockfileName)
+	exists, err := fileutils.IsFileExists(lockfilePath, false)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errorutils.CheckErrorf("couldn't find %s in %s. Run 'pnpm install' to create it.", LockfileName, projectDir)
+	}
+	content, err := os.ReadFile(lockfilePath)
+	if errorutils.CheckError(err) != nil {
+		return nil, err
+	}
+	lockfile := new(Lockfile)
+	if err = yaml.Unmarshal(content, lockfile); err != nil {
+		return nil, errorutils.CheckErrorf("failed to read %s: %s", lockfilePath, err.Error())
+	}
+	return lockfile, nil
+}
+
+// GetImporters returns the projects in the lockfile by their paths relative to it. The root project's path is RootImporterPath.
+func (lf *Lockfile) GetImporters() map[string]*LockfileImporter {
+	if len(lf.Importers) > 0 {
+		return lf.Importers
+	}
+	return map[string]*LockfileImporter{RootImporterPath: &lf.LockfileImporter}
+}
+
+// GetDependenciesGraph returns the direct dependencies of the importer in the given scopes, and the dependencies of each of the packages the importer
+// depends on, directly or indirectly. The packages are identified by 'name:version'.
+// Dependencies linked to local directories, like the other projects of a workspace, are skipped.
+func (lf *Lockfile) GetDependenciesGraph(importer *LockfileImporter, includeProd, includeDev bool) (directDependencies []string, dependenciesGraph map[string][]string) {
+	var scopes []map[string]ImporterDependency
+	if includeProd {
+		scopes = append(scopes, importer.Dependencies, importer.OptionalDependencies)
+	}
+	if includeDev {
+		scopes = append(scopes, importer.DevDependencies)
+	}
+	var directKeys []string
+	for _, scope := range scopes {
+		for name, version := range scope {
+			if key := lf.getPackageKey(name, string(version)); key != "" {
+				directKeys = coreutils.AppendUnique(directKeys, key)
+			}
+		}
+	}
+	dependenciesGraph = make(map[string][]string)
+	visited := make(map[string]bool)
+	var visit func(key string)
+	visit = func(key string) {
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		id := lf.getPackageId(key)
+		if _, exists := dependenciesGraph[id]; !exists {
+			dependenciesGraph[id] = []string{}
+		}
+		lockfilePackage := lf.getDependenciesPackages()[key]
+		if lockfilePackage == nil {
+			return
+		}
+		for _, dependencies := range []map[string]string{lockfilePackage.Dependencies, lockfilePackage.OptionalDependencies} {
+			for name, version := range dependencies {
+				dependencyKey := lf.getPackageKey(name, version)
+				if dependencyKey == "" {
+					continue
+				}
+				dependenciesGraph[id] = coreutils.AppendUnique(dependenciesGraph[id], lf.getPackageId(dependencyKey))
+				visit(dependencyKey)
+			}
+		}
+	}
+	for _, key := range directKeys {
+		directDependencies = coreutils.AppendUnique(directDependencies, lf.getPackageId(key))
+		visit(key)
+	}
+	sort.Strings(directDependencies)
+	for _, dependencies := range dependenciesGraph {
+		sort.Strings(dependencies)
+	}
+	return
+}
+
+// Returns the key of the package a dependency is resolved to, or an empty string if the package isn't installed (missing optional dependencies),
+// or if it's linked to a local directory.
+// The resolved version is usually the package's version (with the versions of its peer dependencies), but it's the full key of aliased packages
+// (like '/real-name/1.0.0') and packages that aren't installed from the registry.
+func (lf *Lockfile) getPackageKey(name, version string) string {
+	if version == "" || strings.HasPrefix(version, "link:") {
+		return ""
+	}
+	packages := lf.getDependenciesPackages()
+	for _, candidate := range []string{version, "/" + name + "@" + version, "/" + name + "/" + version, name + "@" + version} {
+		if _, exists := packages[candidate]; exists {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Returns the packages that have the dependencies of the installed packages.
+func (lf *Lockfile) getDependenciesPackages() map[string]*LockfilePackage {
+	if len(lf.Snapshots) > 0 {
+		return lf.Snapshots
+	}
+	return lf.Packages
+}
+
+// Returns the 'name:version' ID of the package with the given key, without the versions of its peer dependencies.
+func (lf *Lockfile) getPackageId(key string) string {
+	// The peer dependencies suffix is '(peer@1.0.0)' (version 6 and above) or '_peer@1.0.0' (version 5)
+	keyWithoutPeers, _, _ := strings.Cut(key, "(")
+	if lockfilePackage, exists := lf.Packages[keyWithoutPeers]; exists && lockfilePackage.Name != "" && lockfilePackage.Version != "" {
+		return lockfilePackage.Name + ":" + lockfilePackage.Version
+	}
+	if lockfilePackage, exists := lf.Packages[key]; exists && lockfilePackage.Name != "" && lockfilePackage.Version != "" {
+		return lockfilePackage.Name + ":" + lockfilePackage.Version
+	}
+	keyWithoutPeers = strings.TrimPrefix(keyWithoutPeers, "/")
+	if lf.isVersion5() {
+		separatorIndex := strings.LastIndex(keyWithoutPeers, "/")
+		if separatorIndex < 0 {
+			return keyWithoutPeers
+		}
+		version, _, _ := strings.Cut(keyWithoutPeers[separatorIndex+1:], "_")
+		return keyWithoutPeers[:separatorIndex] + ":" + version
+	}
+	separatorIndex := strings.LastIndex(keyWithoutPeers, "@")
+	if separatorIndex <= 0 {
+		return keyWithoutPeers
+	}
+	return keyWithoutPeers[:separatorIndex] + ":" + keyWithoutPeers[separatorIndex+1:]
+}
+
+func (lf *Lockfile) isVersion5() bool {
+	return strings.HasPrefix(fmt.Sprint(lf.LockfileVersion), "5")
+}
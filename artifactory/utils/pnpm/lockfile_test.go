@@ -0,0 +1,123 @@
+package pnpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lockfileVersion5 = `lockfileVersion: 5.4
+
+specifiers:
+  '@babel/core': ^7.20.0
+  react-dom: ^18.2.0
+  my-ms: npm:ms@^2.1.3
+
+dependencies:
+  react-dom: 18.2.0_react@18.2.0
+  my-ms: /ms/2.1.3
+
+devDependencies:
+  '@babel/core': 7.20.0
+
+packages:
+
+  /@babel/core/7.20.0:
+    resolution: {integrity: sha512-1}
+    dependencies:
+      ms: 2.1.3
+    dev: true
+
+  /ms/2.1.3:
+    resolution: {integrity: sha512-2}
+
+  /react-dom/18.2.0_react@18.2.0:
+    resolution: {integrity: sha512-3}
+    dependencies:
+      react: 18.2.0
+    dev: false
+
+  /react/18.2.0:
+    resolution: {integrity: sha512-4}
+    dev: false
+`
+
+const lockfileVersion9 = `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react-dom:
+        specifier: ^18.2.0
+        version: 18.2.0(react@18.2.0)
+      private-lib:
+        specifier: github:jfrog/private-lib
+        version: https://codeload.github.com/jfrog/private-lib/tar.gz/abc123
+
+packages:
+
+  private-lib@https://codeload.github.com/jfrog/private-lib/tar.gz/abc123:
+    resolution: {tarball: https://codeload.github.com/jfrog/private-lib/tar.gz/abc123}
+    name: private-lib
+    version: 2.0.0
+
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-3}
+
+  react@18.2.0:
+    resolution: {integrity: sha512-4}
+
+snapshots:
+
+  private-lib@https://codeload.github.com/jfrog/private-lib/tar.gz/abc123:
+    dependencies:
+      react: 18.2.0
+
+  react-dom@18.2.0(react@18.2.0):
+    dependencies:
+      react: 18.2.0
+
+  react@18.2.0: {}
+`
+
+func TestGetDependenciesGraph(t *testing.T) {
+	testCases := []struct {
+		name           string
+		lockfile       string
+		includeProd    bool
+		includeDev     bool
+		expectedDirect []string
+		expectedGraph  map[string][]string
+	}{
+		{"version5", lockfileVersion5, true, true,
+			[]string{"@babel/core:7.20.0", "ms:2.1.3", "react-dom:18.2.0"},
+			map[string][]string{"@babel/core:7.20.0": {"ms:2.1.3"}, "ms:2.1.3": {}, "react-dom:18.2.0": {"react:18.2.0"}, "react:18.2.0": {}}},
+		{"version5ProdOnly", lockfileVersion5, true, false,
+			[]string{"ms:2.1.3", "react-dom:18.2.0"},
+			map[string][]string{"ms:2.1.3": {}, "react-dom:18.2.0": {"react:18.2.0"}, "react:18.2.0": {}}},
+		{"version9", lockfileVersion9, true, true,
+			[]string{"private-lib:2.0.0", "react-dom:18.2.0"},
+			map[string][]string{"private-lib:2.0.0": {"react:18.2.0"}, "react-dom:18.2.0": {"react:18.2.0"}, "react:18.2.0": {}}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(projectDir, LockfileName), []byte(testCase.lockfile), 0600))
+			lockfile, err := ReadLockfile(projectDir)
+			assert.NoError(t, err)
+			importers := lockfile.GetImporters()
+			assert.Len(t, importers, 1)
+			directDependencies, dependenciesGraph := lockfile.GetDependenciesGraph(importers[RootImporterPath], testCase.includeProd, testCase.includeDev)
+			assert.Equal(t, testCase.expectedDirect, directDependencies)
+			assert.Equal(t, testCase.expectedGraph, dependenciesGraph)
+		})
+	}
+}
+
+func TestReadLockfileMissing(t *testing.T) {
+	_, err := ReadLockfile(t.TempDir())
+	assert.ErrorContains(t, err, "couldn't find pnpm-lock.yaml")
+}
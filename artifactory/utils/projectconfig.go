@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 
@@ -79,8 +80,18 @@ type RepositoryConfig struct {
 // If configuration file exists in the working dir or in one of its parent dirs return its path,
 // otherwise return the global configuration file path
 func GetProjectConfFilePath(projectType ProjectType) (confFilePath string, exists bool, err error) {
+	wd, err := os.Getwd()
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	return GetProjectConfFilePathFromDir(projectType, wd)
+}
+
+// Same as GetProjectConfFilePath, but looks for the configuration file in dir or in one of its parent dirs, instead of the working dir.
+// Unlike GetProjectConfFilePath, the working directory of the process is never changed, so it is safe to call this function concurrently.
+func GetProjectConfFilePathFromDir(projectType ProjectType, dir string) (confFilePath string, exists bool, err error) {
 	confFileName := filepath.Join("projects", projectType.String()+".yaml")
-	projectDir, exists, err := fileutils.FindUpstream(".jfrog", fileutils.Dir)
+	projectDir, exists, err := findUpstreamDir(dir, ".jfrog")
 	if err != nil {
 		return
 	}
@@ -109,6 +120,26 @@ func GetProjectConfFilePath(projectType ProjectType) (confFilePath string, exist
 	return
 }
 
+// Looks for a directory named dirName in startDir or in one of its parent dirs, and returns the directory containing it.
+func findUpstreamDir(startDir, dirName string) (parentDir string, exists bool, err error) {
+	parentDir, err = filepath.Abs(startDir)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	for {
+		exists, err = fileutils.IsDirExists(filepath.Join(parentDir, dirName), false)
+		if err != nil || exists {
+			return
+		}
+		nextDir := filepath.Dir(parentDir)
+		if nextDir == parentDir {
+			// Reached the root of the file system
+			return "", false, nil
+		}
+		parentDir = nextDir
+	}
+}
+
 func GetRepoConfigByPrefix(configFilePath, prefix string, vConfig *viper.Viper) (*RepositoryConfig, error) {
 	if !vConfig.IsSet(prefix) {
 		return nil, errorutils.CheckErrorf("%s information is missing within %s", prefix, configFilePath)
@@ -16,6 +16,7 @@ const (
 	JfrogSecurityDirName                = "security"
 	JfrogSecurityConfFile               = "security.yaml"
 	JfrogBackupDirName                  = "backup"
+	JfrogCacheDirName                   = "cache"
 	JfrogLogsDirName                    = "logs"
 	JfrogLocksDirName                   = "locks"
 	JfrogPluginsDirName                 = "plugins"
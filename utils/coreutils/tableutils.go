@@ -28,6 +28,7 @@ var DefaultMaxColWidth = 25
 // In case the struct you want to print contains a field that is a slice of other structs,
 // you can print it in the table too with the 'embed-table' tag which can be set on slices of structs only.
 // Fields with the 'extended' tag will be printed iff the 'printExtended' bool input is true.
+// Columns of fields with the 'omitempty' tag will be printed only if at least one of the rows has a non-empty value in them.
 //
 // Example:
 // These are the structs Customer and Product:
@@ -145,6 +146,9 @@ func PrepareTable(rows interface{}, emptyTableMessage string, printExtended bool
 		if !columnNameExist && !embedTableExist {
 			continue
 		}
+		if omitEmpty, omitEmptyExist := field.Tag.Lookup("omitempty"); omitEmptyExist && omitEmpty == "true" && isColumnEmpty(rowsSliceValue, i) {
+			continue
+		}
 
 		if embedTable == "true" {
 			var subfieldsProperties []subfieldProperties
@@ -184,6 +188,16 @@ func PrepareTable(rows interface{}, emptyTableMessage string, printExtended bool
 	return tableWriter, nil
 }
 
+// Returns true if the field in the given index is empty in all the rows.
+func isColumnEmpty(rowsSliceValue reflect.Value, fieldIndex int) bool {
+	for i := 0; i < rowsSliceValue.Len(); i++ {
+		if !rowsSliceValue.Index(i).Field(fieldIndex).IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
 type fieldProperties struct {
 	index     int                  // The location of the field inside the row struct
 	subfields []subfieldProperties // If this field is an embedded table, this will contain the fields in it
@@ -1,8 +1,10 @@
 package coreutils
 
 import (
-	"github.com/magiconair/properties/assert"
+	"strings"
 	"testing"
+
+	"github.com/magiconair/properties/assert"
 )
 
 func TestCountLinesInCell(t *testing.T) {
@@ -36,3 +38,17 @@ func TestCountLinesInCell(t *testing.T) {
 		assert.Equal(t, test.expectedNumberOfLines, actualNumberOfLines)
 	}
 }
+
+func TestPrepareTableOmitEmpty(t *testing.T) {
+	type row struct {
+		Name        string `col-name:"Name"`
+		Description string `col-name:"Description" omitempty:"true"`
+	}
+	tableWriter, err := PrepareTable([]row{{Name: "a"}, {Name: "b"}}, "", false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, strings.Contains(tableWriter.Render(), "DESCRIPTION"))
+
+	tableWriter, err = PrepareTable([]row{{Name: "a"}, {Name: "b", Description: "c"}}, "", false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.Contains(tableWriter.Render(), "DESCRIPTION"))
+}
@@ -1,28 +1,38 @@
 package coreutils
 
 import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
+	"golang.org/x/exp/slices"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 )
 
 type Technology string
 
 const (
-	Maven  Technology = "maven"
-	Gradle Technology = "gradle"
-	Npm    Technology = "npm"
-	Yarn   Technology = "yarn"
-	Go     Technology = "go"
-	Pip    Technology = "pip"
-	Pipenv Technology = "pipenv"
-	Poetry Technology = "poetry"
-	Nuget  Technology = "nuget"
-	Dotnet Technology = "dotnet"
-	Docker Technology = "docker"
+	Maven    Technology = "maven"
+	Gradle   Technology = "gradle"
+	Npm      Technology = "npm"
+	Yarn     Technology = "yarn"
+	Pnpm     Technology = "pnpm"
+	Go       Technology = "go"
+	Pip      Technology = "pip"
+	Pipenv   Technology = "pipenv"
+	Poetry   Technology = "poetry"
+	Nuget    Technology = "nuget"
+	Dotnet   Technology = "dotnet"
+	Docker   Technology = "docker"
+	Cargo    Technology = "cargo"
+	Composer Technology = "composer"
+	Bundler  Technology = "bundler"
 )
 
 const Pypi = "pypi"
@@ -44,6 +54,12 @@ type TechData struct {
 	formal string
 	// The executable name of the technology
 	execCommand string
+	// Whether projects of this technology may contain sub-projects (modules) of the same technology, which are handled by the build tool of the parent project.
+	aggregatesModules bool
+	// The file that marks a project of this technology as a workspace. If set, only workspaces aggregate modules.
+	workspaceIndicator string
+	// Other technologies the modules of this technology's projects are detected as, like the members of a pnpm workspace, which are detected as npm projects.
+	modulesTechnologies []Technology
 }
 
 var technologiesData = map[Technology]TechData{
@@ -52,15 +68,17 @@ var technologiesData = map[Technology]TechData{
 		ciSetupSupport:    true,
 		packageDescriptor: "pom.xml",
 		execCommand:       "mvn",
+		aggregatesModules: true,
 	},
 	Gradle: {
 		indicators:        []string{".gradle"},
 		ciSetupSupport:    true,
 		packageDescriptor: "build.gradle",
+		aggregatesModules: true,
 	},
 	Npm: {
 		indicators:        []string{"package.json", "package-lock.json", "npm-shrinkwrap.json"},
-		exclude:           []string{".yarnrc.yml", "yarn.lock", ".yarn"},
+		exclude:           []string{".yarnrc.yml", "yarn.lock", ".yarn", "pnpm-lock.yaml", "pnpm-workspace.yaml"},
 		ciSetupSupport:    true,
 		packageDescriptor: "package.json",
 		formal:            string(Npm),
@@ -69,6 +87,16 @@ var technologiesData = map[Technology]TechData{
 		indicators:        []string{".yarnrc.yml", "yarn.lock", ".yarn"},
 		packageDescriptor: "package.json",
 	},
+	Pnpm: {
+		packageType:       string(Npm),
+		indicators:        []string{"pnpm-lock.yaml", "pnpm-workspace.yaml"},
+		packageDescriptor: "package.json",
+		formal:            string(Pnpm),
+		// The lockfile of a pnpm workspace includes the dependencies of all the workspace's projects
+		aggregatesModules:   true,
+		workspaceIndicator:  "pnpm-workspace.yaml",
+		modulesTechnologies: []Technology{Npm},
+	},
 	Go: {
 		indicators:        []string{"go.mod"},
 		packageDescriptor: "go.mod",
@@ -88,12 +116,31 @@ var technologiesData = map[Technology]TechData{
 		indicators:  []string{"pyproject.toml", "poetry.lock"},
 	},
 	Nuget: {
-		indicators: []string{".sln", ".csproj"},
-		formal:     "NuGet",
+		indicators:        []string{".sln", ".csproj"},
+		formal:            "NuGet",
+		aggregatesModules: true,
 	},
 	Dotnet: {
-		indicators: []string{".sln", ".csproj"},
-		formal:     ".NET",
+		packageType:       string(Nuget),
+		indicators:        []string{".sln", ".csproj"},
+		formal:            ".NET",
+		aggregatesModules: true,
+	},
+	Cargo: {
+		indicators:        []string{"Cargo.toml", "Cargo.lock"},
+		packageDescriptor: "Cargo.toml",
+		// The crates of a Cargo workspace share the workspace's Cargo.lock
+		aggregatesModules: true,
+	},
+	Composer: {
+		indicators:        []string{"composer.json", "composer.lock"},
+		packageDescriptor: "composer.json",
+		formal:            "Composer",
+	},
+	Bundler: {
+		packageType:       "gem",
+		indicators:        []string{"Gemfile", "Gemfile.lock"},
+		packageDescriptor: "Gemfile",
 	},
 }
 
@@ -151,6 +198,143 @@ func DetectTechnologies(path string, isCiSetup, recursive bool) (map[Technology]
 	return detectedTechnologies, nil
 }
 
+// DefaultProjectsExcludePatterns holds the directories that are skipped by DetectProjects, in addition to the given exclude patterns.
+var DefaultProjectsExcludePatterns = []string{"node_modules", ".git", ".idea", ".jfrog", "venv", ".venv", "target"}
+
+// DetectedProject holds the technologies detected in a project's directory.
+type DetectedProject struct {
+	// The project's directory.
+	Dir string
+	// The path of the descriptor file of each of the technologies detected in the directory, like the project's pom.xml or package.json.
+	Descriptors map[Technology]string
+}
+
+// DetectProjects looks for projects in rootPath and in all of its subdirectories, and detects the technologies used by each of them.
+// Directories whose name, or path relative to rootPath, matches one of the glob excludePatterns or one of the DefaultProjectsExcludePatterns are skipped along with their subdirectories.
+// Maven, Gradle and NuGet projects nested in a project of the same technology are modules of the parent project, and are not returned.
+// Likewise, npm and pnpm projects nested in a pnpm workspace are members of the workspace, and are not returned.
+func DetectProjects(rootPath string, excludePatterns []string) (projects []DetectedProject, err error) {
+	rootPath, err = filepath.Abs(rootPath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	excludePatterns = append(append([]string{}, DefaultProjectsExcludePatterns...), excludePatterns...)
+	for _, pattern := range excludePatterns {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, errorutils.CheckErrorf("invalid exclude pattern '%s': %s", pattern, err.Error())
+		}
+	}
+	// The directories of the detected projects that may contain modules, by technology.
+	aggregatingProjects := make(map[Technology][]string)
+	err = filepath.WalkDir(rootPath, func(currentPath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if currentPath != rootPath && isExcludedDir(rootPath, currentPath, excludePatterns) {
+			return filepath.SkipDir
+		}
+		entries, e := os.ReadDir(currentPath)
+		if e != nil {
+			return e
+		}
+		var names []string
+		for _, dirEntry := range entries {
+			names = append(names, dirEntry.Name())
+		}
+		project := DetectedProject{Dir: currentPath, Descriptors: make(map[Technology]string)}
+		for tech := range detectTechnologiesByFilePaths(names, false) {
+			if !isModuleOfAggregatingProject(tech, currentPath, aggregatingProjects) {
+				project.Descriptors[tech] = filepath.Join(currentPath, getDescriptorFileName(tech, names))
+			}
+		}
+		for tech := range project.Descriptors {
+			if techData := technologiesData[tech]; techData.aggregatesModules && (techData.workspaceIndicator == "" || slices.Contains(names, techData.workspaceIndicator)) {
+				aggregatingProjects[tech] = append(aggregatingProjects[tech], currentPath)
+			}
+		}
+		if len(project.Descriptors) > 0 {
+			projects = append(projects, project)
+		}
+		return nil
+	})
+	return projects, errorutils.CheckError(err)
+}
+
+// GetDescriptorPath returns the path of the descriptor file of the given technology in the project's directory.
+// If no such file is found, the project's directory is returned.
+func GetDescriptorPath(projectDir string, tech Technology) string {
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return projectDir
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return filepath.Join(projectDir, getDescriptorFileName(tech, names))
+}
+
+// Returns the name of the technology's descriptor file out of the given file names, preferring the technology's package descriptor.
+// An empty string is returned if none of the files is a descriptor of the technology.
+func getDescriptorFileName(tech Technology, fileNames []string) string {
+	techData := technologiesData[tech]
+	for _, fileName := range fileNames {
+		if techData.packageDescriptor != "" && fileName == techData.packageDescriptor {
+			return fileName
+		}
+	}
+	for _, indicator := range techData.indicators {
+		for _, fileName := range fileNames {
+			if strings.HasSuffix(fileName, indicator) {
+				return fileName
+			}
+		}
+	}
+	return ""
+}
+
+// Returns true if a project of the given technology in dirPath is a module of one of the aggregatingProjects, of its own technology or of
+// a technology whose modules are detected as the given technology.
+func isModuleOfAggregatingProject(tech Technology, dirPath string, aggregatingProjects map[Technology][]string) bool {
+	for aggregatingTech, dirs := range aggregatingProjects {
+		if (aggregatingTech == tech || slices.Contains(technologiesData[aggregatingTech].modulesTechnologies, tech)) && isInOneOfDirs(dirPath, dirs) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the name of the directory, or its path relative to rootPath, matches one of the glob patterns.
+func isExcludedDir(rootPath, dirPath string, excludePatterns []string) bool {
+	relativePath, err := filepath.Rel(rootPath, dirPath)
+	if err != nil {
+		return false
+	}
+	relativePath = filepath.ToSlash(relativePath)
+	for _, pattern := range excludePatterns {
+		if matched, _ := path.Match(pattern, filepath.Base(dirPath)); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, relativePath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if dirPath is one of the dirs, or is nested in one of them.
+func isInOneOfDirs(dirPath string, dirs []string) bool {
+	for _, dir := range dirs {
+		if relativePath, err := filepath.Rel(dir, dirPath); err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
 func detectTechnologiesByFilePaths(paths []string, isCiSetup bool) (detected map[Technology]bool) {
 	detected = make(map[Technology]bool)
 	exclude := make(map[Technology]bool)
@@ -1,9 +1,12 @@
 package coreutils
 
 import (
-	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDetectTechnologiesByFilePaths(t *testing.T) {
@@ -15,11 +18,15 @@ func TestDetectTechnologiesByFilePaths(t *testing.T) {
 		{"simpleMavenTest", []string{"pom.xml"}, map[Technology]bool{Maven: true}},
 		{"npmTest", []string{"../package.json"}, map[Technology]bool{Npm: true}},
 		{"yarnTest", []string{"./package.json", "./.yarn"}, map[Technology]bool{Yarn: true}},
+		{"pnpmTest", []string{"./package.json", "./pnpm-lock.yaml"}, map[Technology]bool{Pnpm: true}},
 		{"windowsGradleTest", []string{"c:\\users\\test\\package\\build.gradle"}, map[Technology]bool{Gradle: true}},
 		{"windowsPipTest", []string{"c:\\users\\test\\package\\setup.py"}, map[Technology]bool{Pip: true}},
 		{"windowsPipenvTest", []string{"c:\\users\\test\\package\\Pipfile"}, map[Technology]bool{Pipenv: true}},
 		{"golangTest", []string{"/Users/eco/dev/jfrog-cli-core/go.mod"}, map[Technology]bool{Go: true}},
 		{"windowsNugetTest", []string{"c:\\users\\test\\package\\project.sln"}, map[Technology]bool{Nuget: true, Dotnet: true}},
+		{"cargoTest", []string{"/Users/eco/dev/crate/Cargo.lock"}, map[Technology]bool{Cargo: true}},
+		{"composerTest", []string{"composer.lock"}, map[Technology]bool{Composer: true}},
+		{"bundlerTest", []string{"Gemfile"}, map[Technology]bool{Bundler: true}},
 		{"noTechTest", []string{"pomxml"}, map[Technology]bool{}},
 	}
 
@@ -30,3 +37,52 @@ func TestDetectTechnologiesByFilePaths(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectProjects(t *testing.T) {
+	rootDir := t.TempDir()
+	files := []string{
+		filepath.Join("backend", "pom.xml"),
+		filepath.Join("backend", "module", "pom.xml"),
+		filepath.Join("frontend", "package.json"),
+		filepath.Join("frontend", "node_modules", "lodash", "package.json"),
+		filepath.Join("services", "api", "go.mod"),
+		filepath.Join("services", "legacy", "setup.py"),
+		filepath.Join("scripts", "requirements.txt"),
+	}
+	for _, file := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, filepath.Dir(file)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(rootDir, file), []byte{}, 0644))
+	}
+
+	projects, err := DetectProjects(rootDir, []string{"services/legacy"})
+	assert.NoError(t, err)
+	descriptors := make(map[string]Technology)
+	for _, project := range projects {
+		for tech, descriptor := range project.Descriptors {
+			descriptors[descriptor] = tech
+		}
+	}
+	// The Maven module, the node_modules directory and the excluded directory are skipped
+	assert.Equal(t, map[string]Technology{
+		filepath.Join(rootDir, "backend", "pom.xml"):          Maven,
+		filepath.Join(rootDir, "frontend", "package.json"):    Npm,
+		filepath.Join(rootDir, "services", "api", "go.mod"):   Go,
+		filepath.Join(rootDir, "scripts", "requirements.txt"): Pip,
+	}, descriptors)
+
+	_, err = DetectProjects(rootDir, []string{"[invalid"})
+	assert.Error(t, err)
+}
+
+func TestDetectProjectsPnpmWorkspace(t *testing.T) {
+	rootDir := filepath.Join("..", "..", "xray", "commands", "testdata", "pnpm-project")
+	projects, err := DetectProjects(rootDir, nil)
+	assert.NoError(t, err)
+	absRootDir, err := filepath.Abs(rootDir)
+	assert.NoError(t, err)
+	// The members of the workspace are audited with the workspace, so they aren't detected as npm projects
+	if assert.Len(t, projects, 1) {
+		assert.Equal(t, absRootDir, projects[0].Dir)
+		assert.Equal(t, map[Technology]string{Pnpm: filepath.Join(absRootDir, "package.json")}, projects[0].Descriptors)
+	}
+}
@@ -2,6 +2,7 @@ package coreutils
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,7 @@ import (
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -150,6 +152,28 @@ func SumTrueValues(boolArr []bool) int {
 	return counter
 }
 
+// AppendUnique appends the new values that aren't in values already, keeping their order.
+func AppendUnique(values []string, newValues ...string) []string {
+	for _, value := range newValues {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ReadJsonFile reads the JSON file at filePath into target.
+func ReadJsonFile(filePath string, target interface{}) error {
+	content, err := os.ReadFile(filePath)
+	if errorutils.CheckError(err) != nil {
+		return err
+	}
+	if err = json.Unmarshal(content, target); err != nil {
+		return errorutils.CheckErrorf("failed to read %s: %s", filePath, err.Error())
+	}
+	return nil
+}
+
 func SpecVarsStringToMap(rawVars string) map[string]string {
 	if len(rawVars) == 0 {
 		return nil
@@ -285,6 +309,14 @@ func GetJfrogBackupDir() (string, error) {
 	return filepath.Join(homeDir, JfrogBackupDirName), nil
 }
 
+func GetJfrogCacheDir() (string, error) {
+	homeDir, err := GetJfrogHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, JfrogCacheDirName), nil
+}
+
 func GetJfrogPluginsDir() (string, error) {
 	homeDir, err := GetJfrogHomeDir()
 	if err != nil {
@@ -18,6 +18,11 @@ const (
 )
 
 func RunGradle(vConfig *viper.Viper, tasks, deployableArtifactsFile string, configuration *utils.BuildConfiguration, threads int, useWrapperIfMissingConfig, disableDeploy bool) error {
+	return RunGradleInDir(vConfig, "", tasks, deployableArtifactsFile, configuration, threads, useWrapperIfMissingConfig, disableDeploy)
+}
+
+// RunGradleInDir runs the Gradle project found in srcPath, like RunGradle runs the project in the working directory.
+func RunGradleInDir(vConfig *viper.Viper, srcPath, tasks, deployableArtifactsFile string, configuration *utils.BuildConfiguration, threads int, useWrapperIfMissingConfig, disableDeploy bool) error {
 	buildInfoService := utils.CreateBuildInfoService()
 	buildName, err := configuration.GetBuildName()
 	if err != nil {
@@ -31,7 +36,7 @@ func RunGradle(vConfig *viper.Viper, tasks, deployableArtifactsFile string, conf
 	if err != nil {
 		return errorutils.CheckError(err)
 	}
-	gradleModule, err := gradleBuild.AddGradleModule("")
+	gradleModule, err := gradleBuild.AddGradleModule(srcPath)
 	if err != nil {
 		return errorutils.CheckError(err)
 	}
@@ -15,6 +15,11 @@ import (
 )
 
 func RunMvn(vConfig *viper.Viper, buildArtifactsDetailsFile string, buildConf *utils.BuildConfiguration, goals []string, threads int, insecureTls, disableDeploy bool) error {
+	return RunMvnInDir(vConfig, "", buildArtifactsDetailsFile, buildConf, goals, threads, insecureTls, disableDeploy)
+}
+
+// RunMvnInDir runs the Maven project found in srcPath, like RunMvn runs the project in the working directory.
+func RunMvnInDir(vConfig *viper.Viper, srcPath, buildArtifactsDetailsFile string, buildConf *utils.BuildConfiguration, goals []string, threads int, insecureTls, disableDeploy bool) error {
 	buildInfoService := utils.CreateBuildInfoService()
 	buildName, err := buildConf.GetBuildName()
 	if err != nil {
@@ -28,7 +33,7 @@ func RunMvn(vConfig *viper.Viper, buildArtifactsDetailsFile string, buildConf *u
 	if err != nil {
 		return errorutils.CheckError(err)
 	}
-	mavenModule, err := mvnBuild.AddMavenModule("")
+	mavenModule, err := mvnBuild.AddMavenModule(srcPath)
 	if err != nil {
 		return errorutils.CheckError(err)
 	}
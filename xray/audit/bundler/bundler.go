@@ -0,0 +1,125 @@
+package bundler
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	gemPackageTypeIdentifier = "gem://"
+	gemfileLockFileName      = "Gemfile.lock"
+	// The indentation of the gems in the 'specs' of the sources sections, and of their dependencies.
+	specIndent           = "    "
+	specDependencyIndent = "      "
+)
+
+// The gems locked in Gemfile.lock, and the direct dependencies of the project.
+type gemfileLock struct {
+	// The versions of the gems, by name.
+	versions map[string]string
+	// The names of the dependencies of each gem, by name.
+	dependencies map[string][]string
+	// The names of the gems listed in the 'DEPENDENCIES' section.
+	directDependencies []string
+}
+
+// BuildDependencyTree builds the dependency tree of the Bundler project in projectDir from its Gemfile.lock, without running Ruby.
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
+	lockFilePath := filepath.Join(projectDir, gemfileLockFileName)
+	exists, err := fileutils.IsFileExists(lockFilePath, false)
+	if err != nil {
+		return
+	}
+	if !exists {
+		return nil, errorutils.CheckErrorf("couldn't find %s in %s. Run 'bundle lock' to create it.", gemfileLockFileName, projectDir)
+	}
+	content, err := os.ReadFile(lockFilePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	lock := parseGemfileLock(content)
+	getIds := func(names []string) (ids []string) {
+		for _, name := range names {
+			if version, exists := lock.versions[name]; exists {
+				ids = append(ids, gemPackageTypeIdentifier+name+":"+version)
+			}
+		}
+		sort.Strings(ids)
+		return
+	}
+	// Gemfile has no project name, so the project's directory name is used
+	rootId := gemPackageTypeIdentifier + filepath.Base(projectDir)
+	treeMap := map[string][]string{rootId: getIds(lock.directDependencies)}
+	for name, version := range lock.versions {
+		treeMap[gemPackageTypeIdentifier+name+":"+version] = getIds(lock.dependencies[name])
+	}
+	return []*services.GraphNode{audit.BuildXrayDependencyTree(treeMap, rootId)}, nil
+}
+
+// Parses the 'specs' of the GEM, GIT and PATH sections, and the DEPENDENCIES section. For example:
+//
+//	GEM
+//	  remote: https://rubygems.org/
+//	  specs:
+//	    actionpack (7.0.4)
+//	      rack (~> 2.0, >= 2.2.0)
+//	    nokogiri (1.13.9-x86_64-linux)
+//
+//	DEPENDENCIES
+//	  actionpack (~> 7.0)
+//	  my-gem!
+func parseGemfileLock(content []byte) *gemfileLock {
+	lock := &gemfileLock{versions: make(map[string]string), dependencies: make(map[string][]string)}
+	var section, currentGem string
+	inSpecs := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		switch {
+		case line == "":
+			continue
+		case !strings.HasPrefix(line, " "):
+			section, currentGem, inSpecs = line, "", false
+		case section == "DEPENDENCIES":
+			name, _ := parseGemfileLockEntry(line)
+			lock.directDependencies = append(lock.directDependencies, strings.TrimSuffix(name, "!"))
+		case strings.TrimSpace(line) == "specs:":
+			inSpecs = true
+		case !inSpecs:
+			// The remote, revision and other attributes of the source
+		case strings.HasPrefix(line, specDependencyIndent):
+			if currentGem != "" {
+				name, _ := parseGemfileLockEntry(line)
+				lock.dependencies[currentGem] = append(lock.dependencies[currentGem], name)
+			}
+		case strings.HasPrefix(line, specIndent):
+			name, version := parseGemfileLockEntry(line)
+			currentGem = name
+			// Gems locked for several platforms are listed once for each platform, like 'nokogiri (1.13.9-x86_64-linux)'.
+			// RubyGems versions can't include dashes, so the platform is removed.
+			version, _, _ = strings.Cut(version, "-")
+			if _, exists := lock.versions[name]; exists {
+				// The dependencies of the other platforms are the same
+				currentGem = ""
+				continue
+			}
+			lock.versions[name] = version
+		}
+	}
+	return lock
+}
+
+// Parses an entry like 'name (version)' or 'name (requirements)' to its name and the text in the parentheses.
+func parseGemfileLockEntry(line string) (name, version string) {
+	name, version, _ = strings.Cut(strings.TrimSpace(line), " ")
+	return name, strings.Trim(version, "()")
+}
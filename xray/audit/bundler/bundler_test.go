@@ -0,0 +1,35 @@
+package bundler
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyTree(t *testing.T) {
+	trees, err := BuildDependencyTree(filepath.Join("..", "..", "commands", "testdata", "bundler-project"))
+	assert.NoError(t, err)
+	rack := &services.GraphNode{Id: "gem://rack:2.2.4", Nodes: []*services.GraphNode{}}
+	assert.Equal(t, []*services.GraphNode{{
+		Id: "gem://bundler-project",
+		Nodes: []*services.GraphNode{
+			{Id: "gem://local-gem:0.1.0", Nodes: []*services.GraphNode{rack}},
+			// The platform is removed from the version
+			{Id: "gem://nokogiri:1.13.9", Nodes: []*services.GraphNode{
+				{Id: "gem://racc:1.6.0", Nodes: []*services.GraphNode{}},
+			}},
+			rack,
+			{Id: "gem://rspec:3.12.0", Nodes: []*services.GraphNode{
+				{Id: "gem://rspec-core:3.12.0", Nodes: []*services.GraphNode{}},
+				{Id: "gem://rspec-expectations:3.12.0", Nodes: []*services.GraphNode{
+					{Id: "gem://diff-lcs:1.5.0", Nodes: []*services.GraphNode{}},
+				}},
+			}},
+		},
+	}}, trees)
+
+	_, err = BuildDependencyTree(t.TempDir())
+	assert.ErrorContains(t, err, "couldn't find Gemfile.lock")
+}
@@ -0,0 +1,163 @@
+package cargo
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	cargoPackageTypeIdentifier = "cargo://"
+	cargoLockFileName          = "Cargo.lock"
+)
+
+type cargoLock struct {
+	Package []cargoLockPackage `toml:"package"`
+}
+
+type cargoLockPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	// Empty for crates of the workspace and for crates referenced by a local path.
+	Source string `toml:"source"`
+	// The dependencies, as 'name', 'name version' or 'name version (source)'. The version and the source are included only when the name is ambiguous.
+	Dependencies []string `toml:"dependencies"`
+}
+
+// The relevant parts of the output of 'cargo metadata'.
+type cargoMetadata struct {
+	Packages []struct {
+		Id      string `json:"id"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"packages"`
+	WorkspaceMembers []string `json:"workspace_members"`
+	Resolve          struct {
+		Nodes []struct {
+			Id           string   `json:"id"`
+			Dependencies []string `json:"dependencies"`
+		} `json:"nodes"`
+	} `json:"resolve"`
+}
+
+// BuildDependencyTree builds a dependency tree for each of the crates of the Cargo project (or workspace) in projectDir.
+// The trees are built from the project's Cargo.lock. If the project has no Cargo.lock, the dependencies are resolved by running 'cargo metadata'.
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
+	lockFilePath := filepath.Join(projectDir, cargoLockFileName)
+	exists, err := fileutils.IsFileExists(lockFilePath, false)
+	if err != nil {
+		return
+	}
+	if exists {
+		return buildDependencyTreeFromLockFile(lockFilePath)
+	}
+	log.Debug("Couldn't find " + cargoLockFileName + " in " + projectDir + ". Running 'cargo metadata' to resolve the dependencies...")
+	cmd := exec.Command("cargo", "metadata", "--format-version", "1")
+	cmd.Dir = projectDir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, errorutils.CheckErrorf("'cargo metadata' command failed: %s - %s", err.Error(), exitErr.Stderr)
+		}
+		return nil, errorutils.CheckErrorf("'cargo metadata' command failed: %s", err.Error())
+	}
+	return parseCargoMetadata(output)
+}
+
+// The roots of the trees are the local crates (those without a source) that aren't dependencies of other crates.
+func buildDependencyTreeFromLockFile(lockFilePath string) (dependencyTree []*services.GraphNode, err error) {
+	var lock cargoLock
+	if _, err = toml.DecodeFile(lockFilePath, &lock); err != nil {
+		return nil, errorutils.CheckErrorf("failed to read %s: %s", lockFilePath, err.Error())
+	}
+	packagesByName := make(map[string][]cargoLockPackage)
+	for _, lockPackage := range lock.Package {
+		packagesByName[lockPackage.Name] = append(packagesByName[lockPackage.Name], lockPackage)
+	}
+	treeMap := make(map[string][]string)
+	isDependency := make(map[string]bool)
+	for _, lockPackage := range lock.Package {
+		packageId := getCargoPackageId(lockPackage.Name, lockPackage.Version)
+		for _, dependencyRef := range lockPackage.Dependencies {
+			dependencyId := resolveCargoDependency(packagesByName, dependencyRef)
+			if dependencyId == "" {
+				log.Debug("Couldn't find the dependency '" + dependencyRef + "' of " + packageId + " in " + lockFilePath)
+				continue
+			}
+			treeMap[packageId] = append(treeMap[packageId], dependencyId)
+			isDependency[dependencyId] = true
+		}
+	}
+	var rootsIds []string
+	for _, lockPackage := range lock.Package {
+		packageId := getCargoPackageId(lockPackage.Name, lockPackage.Version)
+		if lockPackage.Source == "" && !isDependency[packageId] {
+			rootsIds = append(rootsIds, packageId)
+		}
+	}
+	if len(rootsIds) == 0 {
+		return nil, errorutils.CheckErrorf("couldn't find the crates of the project in %s", lockFilePath)
+	}
+	return buildTrees(treeMap, rootsIds), nil
+}
+
+// Resolves a dependency of a Cargo.lock package, like 'serde', 'serde 1.0.147' or 'serde 1.0.147 (registry+https://github.com/rust-lang/crates.io-index)', to its package ID.
+func resolveCargoDependency(packagesByName map[string][]cargoLockPackage, dependencyRef string) string {
+	refParts := strings.Fields(dependencyRef)
+	if len(refParts) == 0 {
+		return ""
+	}
+	for _, candidate := range packagesByName[refParts[0]] {
+		if len(refParts) == 1 || candidate.Version == refParts[1] {
+			return getCargoPackageId(candidate.Name, candidate.Version)
+		}
+	}
+	return ""
+}
+
+// The roots of the trees are the members of the workspace.
+func parseCargoMetadata(output []byte) (dependencyTree []*services.GraphNode, err error) {
+	var metadata cargoMetadata
+	if err = json.Unmarshal(output, &metadata); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the output of 'cargo metadata': %s", err.Error())
+	}
+	packagesIds := make(map[string]string)
+	for _, metadataPackage := range metadata.Packages {
+		packagesIds[metadataPackage.Id] = getCargoPackageId(metadataPackage.Name, metadataPackage.Version)
+	}
+	treeMap := make(map[string][]string)
+	for _, node := range metadata.Resolve.Nodes {
+		for _, dependency := range node.Dependencies {
+			treeMap[packagesIds[node.Id]] = append(treeMap[packagesIds[node.Id]], packagesIds[dependency])
+		}
+	}
+	var rootsIds []string
+	for _, member := range metadata.WorkspaceMembers {
+		rootsIds = append(rootsIds, packagesIds[member])
+	}
+	return buildTrees(treeMap, rootsIds), nil
+}
+
+func buildTrees(treeMap map[string][]string, rootsIds []string) (dependencyTree []*services.GraphNode) {
+	for _, children := range treeMap {
+		sort.Strings(children)
+	}
+	sort.Strings(rootsIds)
+	for _, rootId := range rootsIds {
+		dependencyTree = append(dependencyTree, audit.BuildXrayDependencyTree(treeMap, rootId))
+	}
+	return
+}
+
+func getCargoPackageId(name, version string) string {
+	return cargoPackageTypeIdentifier + name + ":" + version
+}
@@ -0,0 +1,52 @@
+package cargo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyTreeFromLockFile(t *testing.T) {
+	trees, err := BuildDependencyTree(filepath.Join("..", "..", "commands", "testdata", "cargo-project"))
+	assert.NoError(t, err)
+	// The lib crate is a dependency of the app crate, so it isn't a separate root
+	assert.Equal(t, []*services.GraphNode{{
+		Id: "cargo://app:0.1.0",
+		Nodes: []*services.GraphNode{
+			{Id: "cargo://lib:0.2.0", Nodes: []*services.GraphNode{
+				{Id: "cargo://rand_core:0.5.1", Nodes: []*services.GraphNode{}},
+				{Id: "cargo://serde:1.0.147", Nodes: []*services.GraphNode{}},
+			}},
+			{Id: "cargo://rand:0.8.5", Nodes: []*services.GraphNode{
+				{Id: "cargo://rand_core:0.6.4", Nodes: []*services.GraphNode{}},
+			}},
+		},
+	}}, trees)
+}
+
+func TestParseCargoMetadata(t *testing.T) {
+	metadata := `{
+  "packages": [
+    {"id": "app 0.1.0 (path+file:///project/app)", "name": "app", "version": "0.1.0"},
+    {"id": "lib 0.2.0 (path+file:///project/lib)", "name": "lib", "version": "0.2.0"},
+    {"id": "registry+https://github.com/rust-lang/crates.io-index#serde@1.0.147", "name": "serde", "version": "1.0.147"}
+  ],
+  "workspace_members": ["lib 0.2.0 (path+file:///project/lib)", "app 0.1.0 (path+file:///project/app)"],
+  "resolve": {
+    "nodes": [
+      {"id": "app 0.1.0 (path+file:///project/app)", "dependencies": ["registry+https://github.com/rust-lang/crates.io-index#serde@1.0.147"]},
+      {"id": "lib 0.2.0 (path+file:///project/lib)", "dependencies": []},
+      {"id": "registry+https://github.com/rust-lang/crates.io-index#serde@1.0.147", "dependencies": []}
+    ]
+  }
+}`
+	trees, err := parseCargoMetadata([]byte(metadata))
+	assert.NoError(t, err)
+	// Each of the workspace members is a root
+	assert.Equal(t, []*services.GraphNode{
+		{Id: "cargo://app:0.1.0", Nodes: []*services.GraphNode{{Id: "cargo://serde:1.0.147", Nodes: []*services.GraphNode{}}}},
+		{Id: "cargo://lib:0.2.0", Nodes: []*services.GraphNode{}},
+	}, trees)
+}
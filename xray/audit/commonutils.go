@@ -46,7 +46,14 @@ func buildXrayDependencyTree(treeHelper map[string][]string, impactPath []string
 	return xrDependencyTree
 }
 
-func Audit(modulesDependencyTrees []*services.GraphNode, xrayGraphScanPrams services.XrayGraphScanParams, serverDetails *config.ServerDetails, progress ioUtils.ProgressMgr, technology coreutils.Technology) (results []services.ScanResponse, err error) {
+// ScanOptions holds the optional parameters of Audit.
+type ScanOptions struct {
+	// If not nil, the cached results of modules whose dependency trees were already scanned with the same parameters are used instead of scanning them again.
+	ScanCache *ScanCache
+}
+
+// Audit scans the dependency trees of the modules of a project with Xray.
+func Audit(modulesDependencyTrees []*services.GraphNode, xrayGraphScanPrams services.XrayGraphScanParams, serverDetails *config.ServerDetails, progress ioUtils.ProgressMgr, technology coreutils.Technology, options ScanOptions) (results []services.ScanResponse, err error) {
 	if len(modulesDependencyTrees) == 0 {
 		err = errorutils.CheckErrorf("No dependencies were found. Please try to build your project and re-run the audit command.")
 		return
@@ -56,26 +63,46 @@ func Audit(modulesDependencyTrees []*services.GraphNode, xrayGraphScanPrams serv
 		progress.SetHeadlineMsg("Scanning for vulnerabilities")
 	}
 
-	// Get Xray version
-	_, xrayVersion, err := xraycommands.CreateXrayServiceManagerAndGetVersion(serverDetails)
-	if err != nil {
-		return
+	// The Xray version is needed only if some of the modules weren't scanned before
+	cachedResults := make([]*services.ScanResponse, len(modulesDependencyTrees))
+	missingResults := false
+	for i, moduleDependencyTree := range modulesDependencyTrees {
+		xrayGraphScanPrams.Graph = moduleDependencyTree
+		if cachedResults[i] = options.ScanCache.Get(xrayGraphScanPrams, serverDetails); cachedResults[i] == nil {
+			missingResults = true
+		}
 	}
-	err = xraycommands.ValidateXrayMinimumVersion(xrayVersion, xraycommands.GraphScanMinXrayVersion)
-	if err != nil {
-		return
+	var xrayVersion string
+	if missingResults {
+		// Get Xray version
+		_, xrayVersion, err = xraycommands.CreateXrayServiceManagerAndGetVersion(serverDetails)
+		if err != nil {
+			return
+		}
+		err = xraycommands.ValidateXrayMinimumVersion(xrayVersion, xraycommands.GraphScanMinXrayVersion)
+		if err != nil {
+			return
+		}
+		log.Info("JFrog Xray version is:", xrayVersion)
 	}
-	log.Info("JFrog Xray version is:", xrayVersion)
-	for _, moduleDependencyTree := range modulesDependencyTrees {
+	for moduleIndex, moduleDependencyTree := range modulesDependencyTrees {
 		xrayGraphScanPrams.Graph = moduleDependencyTree
 		// Log the scanned module ID
 		moduleName := moduleDependencyTree.Id[strings.Index(moduleDependencyTree.Id, "//")+2:]
-		log.Info("Scanning module " + moduleName + "...")
-		var scanResults *services.ScanResponse
-		scanResults, err = xraycommands.RunScanGraphAndGetResults(serverDetails, xrayGraphScanPrams, xrayGraphScanPrams.IncludeVulnerabilities, xrayGraphScanPrams.IncludeLicenses, xrayVersion)
-		if err != nil {
-			err = errorutils.CheckErrorf("Scanning %s failed with error: %s", moduleName, err.Error())
-			return
+		scanResults := cachedResults[moduleIndex]
+		if scanResults != nil {
+			log.Info("Using the cached scan results of module " + moduleName + ".")
+		} else {
+			log.Info("Scanning module " + moduleName + "...")
+			scanResults, err = xraycommands.RunScanGraphAndGetResults(serverDetails, xrayGraphScanPrams, xrayGraphScanPrams.IncludeVulnerabilities, xrayGraphScanPrams.IncludeLicenses, xrayVersion)
+			if err != nil {
+				err = errorutils.CheckErrorf("Scanning %s failed with error: %s", moduleName, err.Error())
+				return
+			}
+			if cacheErr := options.ScanCache.Put(xrayGraphScanPrams, serverDetails, scanResults); cacheErr != nil {
+				// Failing to cache the results shouldn't fail the audit
+				log.Warn("Couldn't cache the scan results of module " + moduleName + ": " + cacheErr.Error())
+			}
 		}
 		for i := range scanResults.Vulnerabilities {
 			scanResults.Vulnerabilities[i].Technology = technology.ToString()
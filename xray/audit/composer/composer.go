@@ -0,0 +1,96 @@
+package composer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	composerPackageTypeIdentifier = "composer://"
+	composerJsonFileName          = "composer.json"
+	composerLockFileName          = "composer.lock"
+)
+
+type composerJson struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+type composerLock struct {
+	Packages    []composerLockPackage `json:"packages"`
+	PackagesDev []composerLockPackage `json:"packages-dev"`
+}
+
+type composerLockPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Require map[string]string `json:"require"`
+}
+
+// BuildDependencyTree builds the dependency tree of the Composer project in projectDir from its composer.json and composer.lock files.
+// The direct dependencies are the required packages in composer.json (including the development requirements), and the dependencies of each
+// package are the required packages of its entry in composer.lock.
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
+	var project composerJson
+	if err = coreutils.ReadJsonFile(filepath.Join(projectDir, composerJsonFileName), &project); err != nil {
+		return
+	}
+	lockFilePath := filepath.Join(projectDir, composerLockFileName)
+	exists, err := fileutils.IsFileExists(lockFilePath, false)
+	if err != nil {
+		return
+	}
+	if !exists {
+		return nil, errorutils.CheckErrorf("couldn't find %s in %s. Run 'composer update' to create it.", composerLockFileName, projectDir)
+	}
+	var lock composerLock
+	if err = coreutils.ReadJsonFile(lockFilePath, &lock); err != nil {
+		return
+	}
+
+	packagesIds := make(map[string]string)
+	lockPackages := append(append([]composerLockPackage{}, lock.Packages...), lock.PackagesDev...)
+	for _, lockPackage := range lockPackages {
+		packagesIds[strings.ToLower(lockPackage.Name)] = getComposerPackageId(lockPackage.Name, lockPackage.Version)
+	}
+	// Platform requirements, like 'php' and 'ext-json', aren't packages, so they aren't locked and are skipped
+	getRequiredIds := func(requirements ...map[string]string) (ids []string) {
+		for _, require := range requirements {
+			for name := range require {
+				if id, exists := packagesIds[strings.ToLower(name)]; exists {
+					ids = append(ids, id)
+				}
+			}
+		}
+		sort.Strings(ids)
+		return
+	}
+	rootId := getComposerPackageId(project.Name, project.Version)
+	if project.Name == "" {
+		rootId = composerPackageTypeIdentifier + filepath.Base(projectDir)
+	}
+	treeMap := map[string][]string{rootId: getRequiredIds(project.Require, project.RequireDev)}
+	for _, lockPackage := range lockPackages {
+		packageId := getComposerPackageId(lockPackage.Name, lockPackage.Version)
+		treeMap[packageId] = getRequiredIds(lockPackage.Require)
+	}
+	return []*services.GraphNode{audit.BuildXrayDependencyTree(treeMap, rootId)}, nil
+}
+
+// Versions of packages installed from tags, like 'v5.4.1', are converted to the versions Xray uses, like '5.4.1'.
+func getComposerPackageId(name, version string) string {
+	packageId := composerPackageTypeIdentifier + strings.ToLower(name)
+	if version != "" {
+		packageId += ":" + strings.TrimPrefix(version, "v")
+	}
+	return packageId
+}
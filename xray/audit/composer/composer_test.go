@@ -0,0 +1,34 @@
+package composer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyTree(t *testing.T) {
+	trees, err := BuildDependencyTree(filepath.Join("..", "..", "commands", "testdata", "composer-project"))
+	assert.NoError(t, err)
+	psrLog := &services.GraphNode{Id: "composer://psr/log:3.0.0", Nodes: []*services.GraphNode{}}
+	// Platform requirements are skipped, and package names are case-insensitive
+	assert.Equal(t, []*services.GraphNode{{
+		Id: "composer://acme/web-app:1.0.0",
+		Nodes: []*services.GraphNode{
+			{Id: "composer://guzzlehttp/guzzle:7.5.0", Nodes: []*services.GraphNode{
+				{Id: "composer://guzzlehttp/psr7:2.4.3", Nodes: []*services.GraphNode{psrLog}},
+			}},
+			{Id: "composer://monolog/monolog:2.8.0", Nodes: []*services.GraphNode{psrLog}},
+			{Id: "composer://phpunit/phpunit:9.5.26", Nodes: []*services.GraphNode{}},
+		},
+	}}, trees)
+}
+
+func TestBuildDependencyTreeWithoutLockFile(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, composerJsonFileName), []byte(`{"require": {"psr/log": "^3.0"}}`), 0644))
+	_, err := BuildDependencyTree(projectDir)
+	assert.ErrorContains(t, err, "couldn't find composer.lock")
+}
@@ -0,0 +1,342 @@
+package dotnet
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	nugetPackageTypeIdentifier = "nuget://"
+	// Created by 'dotnet restore' in the 'obj' directory of each project.
+	assetsFileName = "project.assets.json"
+	// Created by 'dotnet restore' next to the project file, if the project enables lock files.
+	packagesLockFileName = "packages.lock.json"
+	// Lists the packages of projects which don't use PackageReference items.
+	packagesConfigFileName = "packages.config"
+)
+
+// The extensions of the project files the dotnet CLI restores.
+var projectFileExtensions = []string{".csproj", ".fsproj", ".vbproj"}
+
+// The relevant parts of project.assets.json.
+type assetsFile struct {
+	// The resolved packages and projects of each target framework (and runtime identifier), by 'name/version'.
+	Targets map[string]map[string]assetsTargetLibrary `json:"targets"`
+	// The direct dependencies of each target framework, like 'Newtonsoft.Json >= 13.0.1'.
+	ProjectFileDependencyGroups map[string][]string `json:"projectFileDependencyGroups"`
+	Project                     struct {
+		Restore struct {
+			ProjectName string `json:"projectName"`
+		} `json:"restore"`
+	} `json:"project"`
+}
+
+type assetsTargetLibrary struct {
+	// 'package' or 'project'.
+	Type         string            `json:"type"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// The relevant parts of packages.lock.json.
+type packagesLockFile struct {
+	// The resolved packages and projects of each target framework (and runtime identifier), by name.
+	Dependencies map[string]map[string]packagesLockDependency `json:"dependencies"`
+}
+
+type packagesLockDependency struct {
+	// 'Direct', 'Transitive', 'CentralTransitive' or 'Project'.
+	Type         string            `json:"type"`
+	Resolved     string            `json:"resolved"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// The package references in a project file.
+type projectFileContent struct {
+	PackageReferences []struct {
+		Include string `xml:"Include,attr"`
+		Version string `xml:"Version,attr"`
+		// The version may also be declared as a child element.
+		VersionElement string `xml:"Version"`
+	} `xml:"ItemGroup>PackageReference"`
+}
+
+// The packages in packages.config.
+type packagesConfigContent struct {
+	Packages []struct {
+		Id      string `xml:"id,attr"`
+		Version string `xml:"version,attr"`
+	} `xml:"package"`
+}
+
+// BuildDependencyTree builds a dependency tree for each of the .NET projects in projectDir and in its subdirectories, from the files created by
+// 'dotnet restore': the project's obj/project.assets.json, or its packages.lock.json if the assets file doesn't exist.
+// The tree of a project includes the dependencies of all of its target frameworks. Referenced projects have trees of their own.
+// Projects that weren't restored get a tree of the packages they declare, in their project file or in their packages.config, with no transitive dependencies.
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
+	projectFiles, err := findProjectFiles(projectDir)
+	if err != nil {
+		return
+	}
+	if len(projectFiles) == 0 {
+		return nil, errorutils.CheckErrorf("couldn't find .NET project files in %s", projectDir)
+	}
+	var unrestoredProjects []string
+	for _, projectFile := range projectFiles {
+		var tree *services.GraphNode
+		if tree, err = buildProjectDependencyTree(projectFile); err != nil {
+			return
+		}
+		if tree == nil {
+			if tree, err = buildDeclaredDependencyTree(projectFile); err != nil {
+				return
+			}
+			unrestoredProjects = append(unrestoredProjects, projectFile)
+		}
+		dependencyTree = append(dependencyTree, tree)
+	}
+	if len(unrestoredProjects) > 0 {
+		log.Warn("Couldn't find the " + assetsFileName + " or " + packagesLockFileName + " files of the following .NET projects, so only the packages they declare are audited, without their transitive dependencies. " +
+			"Run 'dotnet restore' to audit all of their dependencies:\n" + strings.Join(unrestoredProjects, "\n"))
+	}
+	return
+}
+
+// HasRestoreFiles returns true if one of the .NET projects in projectDir and in its subdirectories has a project.assets.json or a packages.lock.json file.
+func HasRestoreFiles(projectDir string) (bool, error) {
+	projectFiles, err := findProjectFiles(projectDir)
+	if err != nil {
+		return false, err
+	}
+	for _, projectFile := range projectFiles {
+		assetsPath, lockPath := getRestoreFilesPaths(projectFile)
+		for _, restoreFilePath := range []string{assetsPath, lockPath} {
+			exists, err := fileutils.IsFileExists(restoreFilePath, false)
+			if err != nil || exists {
+				return exists, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// Returns the sorted paths of the project files in projectDir and in its subdirectories, skipping the build outputs.
+func findProjectFiles(projectDir string) (projectFiles []string, err error) {
+	err = filepath.WalkDir(projectDir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.IsDir() {
+			if name := entry.Name(); path != projectDir && (name == "obj" || name == "bin" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, extension := range projectFileExtensions {
+			if strings.HasSuffix(entry.Name(), extension) {
+				projectFiles = append(projectFiles, path)
+				break
+			}
+		}
+		return nil
+	})
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	sort.Strings(projectFiles)
+	return
+}
+
+func getRestoreFilesPaths(projectFile string) (assetsPath, lockPath string) {
+	projectFileDir := filepath.Dir(projectFile)
+	return filepath.Join(projectFileDir, "obj", assetsFileName), filepath.Join(projectFileDir, packagesLockFileName)
+}
+
+// Returns nil if the project has no restore files.
+func buildProjectDependencyTree(projectFile string) (*services.GraphNode, error) {
+	projectName := strings.TrimSuffix(filepath.Base(projectFile), filepath.Ext(projectFile))
+	assetsPath, lockPath := getRestoreFilesPaths(projectFile)
+	exists, err := fileutils.IsFileExists(assetsPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		var assets assetsFile
+		if err = coreutils.ReadJsonFile(assetsPath, &assets); err != nil {
+			return nil, err
+		}
+		if assets.Project.Restore.ProjectName != "" {
+			projectName = assets.Project.Restore.ProjectName
+		}
+		rootId := nugetPackageTypeIdentifier + projectName
+		return audit.BuildXrayDependencyTree(parseAssetsFile(&assets, rootId), rootId), nil
+	}
+	exists, err = fileutils.IsFileExists(lockPath, false)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var lock packagesLockFile
+	if err = coreutils.ReadJsonFile(lockPath, &lock); err != nil {
+		return nil, err
+	}
+	rootId := nugetPackageTypeIdentifier + projectName
+	return audit.BuildXrayDependencyTree(parsePackagesLockFile(&lock, rootId), rootId), nil
+}
+
+// Builds a tree of the packages referenced by the project file, or listed in the packages.config next to it.
+func buildDeclaredDependencyTree(projectFile string) (*services.GraphNode, error) {
+	projectName := strings.TrimSuffix(filepath.Base(projectFile), filepath.Ext(projectFile))
+	rootId := nugetPackageTypeIdentifier + projectName
+	treeMap := map[string][]string{rootId: {}}
+	var project projectFileContent
+	if err := readXmlFile(projectFile, &project); err != nil {
+		return nil, err
+	}
+	for _, reference := range project.PackageReferences {
+		version := reference.Version
+		if version == "" {
+			version = reference.VersionElement
+		}
+		if reference.Include == "" || version == "" {
+			// Versions of centrally managed packages are declared elsewhere and resolved only by 'dotnet restore'
+			log.Debug("The version of " + reference.Include + " isn't declared in " + projectFile + ". The package is skipped.")
+			continue
+		}
+		// Exact versions may be declared as ranges, like '[13.0.1]'
+		treeMap[rootId] = append(treeMap[rootId], getPackageId(reference.Include, strings.Trim(version, "[]")))
+	}
+	packagesConfigPath := filepath.Join(filepath.Dir(projectFile), packagesConfigFileName)
+	exists, err := fileutils.IsFileExists(packagesConfigPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		var packagesConfig packagesConfigContent
+		if err = readXmlFile(packagesConfigPath, &packagesConfig); err != nil {
+			return nil, err
+		}
+		for _, nugetPackage := range packagesConfig.Packages {
+			treeMap[rootId] = append(treeMap[rootId], getPackageId(nugetPackage.Id, nugetPackage.Version))
+		}
+	}
+	return audit.BuildXrayDependencyTree(sortTreeMap(treeMap), rootId), nil
+}
+
+// Builds the dependencies map (see audit.BuildXrayDependencyTree) of all the target frameworks in project.assets.json.
+// Targets of runtime identifiers, like 'net6.0/win-x64', get their direct dependencies from the target of their framework.
+func parseAssetsFile(assets *assetsFile, rootId string) map[string][]string {
+	treeMap := make(map[string][]string)
+	for targetName, target := range assets.Targets {
+		// Package names are case-insensitive
+		packagesIds := make(map[string]string)
+		for library, details := range target {
+			name, version, _ := strings.Cut(library, "/")
+			if details.Type == "package" {
+				packagesIds[strings.ToLower(name)] = getPackageId(name, version)
+			}
+		}
+		for library, details := range target {
+			name, version, _ := strings.Cut(library, "/")
+			if details.Type != "package" {
+				continue
+			}
+			packageId := getPackageId(name, version)
+			treeMap[packageId] = appendIds(treeMap[packageId], packagesIds, keys(details.Dependencies))
+		}
+		framework, _, _ := strings.Cut(targetName, "/")
+		var directDependencies []string
+		for _, dependency := range assets.ProjectFileDependencyGroups[framework] {
+			// Dependencies are declared with their version ranges, like 'Newtonsoft.Json >= 13.0.1'
+			directDependencies = append(directDependencies, strings.Fields(dependency)[0])
+		}
+		treeMap[rootId] = appendIds(treeMap[rootId], packagesIds, directDependencies)
+	}
+	return sortTreeMap(treeMap)
+}
+
+// Builds the dependencies map (see audit.BuildXrayDependencyTree) of all the target frameworks in packages.lock.json.
+func parsePackagesLockFile(lock *packagesLockFile, rootId string) map[string][]string {
+	treeMap := make(map[string][]string)
+	for _, target := range lock.Dependencies {
+		packagesIds := make(map[string]string)
+		var directDependencies []string
+		for name, dependency := range target {
+			if dependency.Type == "Project" {
+				continue
+			}
+			packagesIds[strings.ToLower(name)] = getPackageId(name, dependency.Resolved)
+			if dependency.Type == "Direct" {
+				directDependencies = append(directDependencies, name)
+			}
+		}
+		for name, dependency := range target {
+			if dependency.Type == "Project" {
+				continue
+			}
+			packageId := getPackageId(name, dependency.Resolved)
+			treeMap[packageId] = appendIds(treeMap[packageId], packagesIds, keys(dependency.Dependencies))
+		}
+		treeMap[rootId] = appendIds(treeMap[rootId], packagesIds, directDependencies)
+	}
+	return sortTreeMap(treeMap)
+}
+
+// Appends the IDs of the packages with the given names to ids. Names of projects and of packages that weren't resolved are skipped.
+func appendIds(ids []string, packagesIds map[string]string, names []string) []string {
+	for _, name := range names {
+		id, exists := packagesIds[strings.ToLower(name)]
+		if !exists {
+			continue
+		}
+		isDuplicate := false
+		for _, existing := range ids {
+			if existing == id {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func sortTreeMap(treeMap map[string][]string) map[string][]string {
+	for _, children := range treeMap {
+		sort.Strings(children)
+	}
+	return treeMap
+}
+
+func keys(dependencies map[string]string) (names []string) {
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	return
+}
+
+func getPackageId(name, version string) string {
+	return nugetPackageTypeIdentifier + name + ":" + version
+}
+
+func readXmlFile(filePath string, target interface{}) error {
+	content, err := os.ReadFile(filePath)
+	if errorutils.CheckError(err) != nil {
+		return err
+	}
+	if err = xml.Unmarshal(content, target); err != nil {
+		return errorutils.CheckErrorf("failed to read %s: %s", filePath, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package dotnet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyTree(t *testing.T) {
+	projectDir := filepath.Join("..", "..", "commands", "testdata", "dotnet-project")
+	trees, err := BuildDependencyTree(projectDir)
+	assert.NoError(t, err)
+	serilog := &services.GraphNode{Id: "nuget://Serilog:2.12.0", Nodes: []*services.GraphNode{}}
+	assert.Equal(t, []*services.GraphNode{
+		// The dependencies of all the target frameworks are included, and the referenced Lib project has a tree of its own
+		{Id: "nuget://App", Nodes: []*services.GraphNode{
+			{Id: "nuget://Newtonsoft.Json:13.0.1", Nodes: []*services.GraphNode{}},
+			{Id: "nuget://System.Text.Json:6.0.0", Nodes: []*services.GraphNode{
+				{Id: "nuget://System.Buffers:4.5.1", Nodes: []*services.GraphNode{}},
+			}},
+		}},
+		// The Legacy project wasn't restored, so its tree includes only the packages it declares
+		{Id: "nuget://Legacy", Nodes: []*services.GraphNode{
+			{Id: "nuget://Newtonsoft.Json:12.0.3", Nodes: []*services.GraphNode{}},
+			{Id: "nuget://log4net:2.0.10", Nodes: []*services.GraphNode{}},
+		}},
+		{Id: "nuget://Lib", Nodes: []*services.GraphNode{
+			{Id: "nuget://NETStandard.Library:2.0.3", Nodes: []*services.GraphNode{}},
+			{Id: "nuget://Serilog.Sinks.Console:4.1.0", Nodes: []*services.GraphNode{serilog}},
+			serilog,
+		}},
+	}, trees)
+
+	restored, err := HasRestoreFiles(projectDir)
+	assert.NoError(t, err)
+	assert.True(t, restored)
+
+	legacyProjectDir := filepath.Join(projectDir, "Legacy")
+	restored, err = HasRestoreFiles(legacyProjectDir)
+	assert.NoError(t, err)
+	assert.False(t, restored)
+	trees, err = BuildDependencyTree(legacyProjectDir)
+	assert.NoError(t, err)
+	if assert.Len(t, trees, 1) {
+		assert.Equal(t, "nuget://Legacy", trees[0].Id)
+	}
+
+	_, err = BuildDependencyTree(t.TempDir())
+	assert.ErrorContains(t, err, "couldn't find .NET project files")
+}
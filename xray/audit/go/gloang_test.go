@@ -12,7 +12,7 @@ import (
 
 func TestBuildGoDependencyList(t *testing.T) {
 	// Create and change directory to test workspace
-	_, cleanUp := audit.CreateTestWorkspace(t, "go-project")
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, "go-project")
 	defer cleanUp()
 
 	err := removeTxtSuffix("go.mod.txt")
@@ -23,7 +23,7 @@ func TestBuildGoDependencyList(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Run getModulesDependencyTrees
-	rootNode, err := BuildDependencyTree()
+	rootNode, err := BuildDependencyTree(tempDirPath)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, rootNode)
 
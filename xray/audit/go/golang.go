@@ -3,7 +3,6 @@ package _go
 import (
 	"strings"
 
-	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	goutils "github.com/jfrog/jfrog-cli-core/v2/utils/golang"
 	"github.com/jfrog/jfrog-client-go/xray/services"
 )
@@ -12,23 +11,19 @@ const (
 	goPackageTypeIdentifier = "go://"
 )
 
-func BuildDependencyTree() (dependencyTree []*services.GraphNode, err error) {
-	currentDir, err := coreutils.GetWorkingDirectory()
-	if err != nil {
-		return
-	}
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
 	// Calculate go dependencies graph
-	dependenciesGraph, err := goutils.GetDependenciesGraph(currentDir)
+	dependenciesGraph, err := goutils.GetDependenciesGraph(projectDir)
 	if err != nil {
 		return
 	}
 	// Calculate go dependencies list
-	dependenciesList, err := goutils.GetDependenciesList(currentDir)
+	dependenciesList, err := goutils.GetDependenciesList(projectDir)
 	if err != nil {
 		return
 	}
 	// Get root module name
-	rootModuleName, err := goutils.GetModuleName(currentDir)
+	rootModuleName, err := goutils.GetModuleName(projectDir)
 	if err != nil {
 		return
 	}
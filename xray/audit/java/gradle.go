@@ -9,11 +9,11 @@ import (
 	"github.com/jfrog/jfrog-client-go/xray/services"
 )
 
-func BuildGradleDependencyTree(excludeTestDeps, useWrapper, ignoreConfigFile bool) (dependencyTree []*services.GraphNode, err error) {
+func BuildGradleDependencyTree(projectDir string, excludeTestDeps, useWrapper, ignoreConfigFile bool) (dependencyTree []*services.GraphNode, err error) {
 	buildConfiguration, cleanBuild := createBuildConfiguration("audit-gradle")
 	defer cleanBuild(err)
 
-	err = runGradle(buildConfiguration, excludeTestDeps, useWrapper, ignoreConfigFile)
+	err = runGradle(projectDir, buildConfiguration, excludeTestDeps, useWrapper, ignoreConfigFile)
 	if err != nil {
 		return
 	}
@@ -22,7 +22,7 @@ func BuildGradleDependencyTree(excludeTestDeps, useWrapper, ignoreConfigFile boo
 	return
 }
 
-func runGradle(buildConfiguration *utils.BuildConfiguration, excludeTestDeps, useWrapper, ignoreConfigFile bool) (err error) {
+func runGradle(projectDir string, buildConfiguration *utils.BuildConfiguration, excludeTestDeps, useWrapper, ignoreConfigFile bool) (err error) {
 	tasks := "clean compileJava "
 	if !excludeTestDeps {
 		tasks += "compileTestJava "
@@ -32,7 +32,7 @@ func runGradle(buildConfiguration *utils.BuildConfiguration, excludeTestDeps, us
 	configFilePath := ""
 	if !ignoreConfigFile {
 		var exists bool
-		configFilePath, exists, err = utils.GetProjectConfFilePath(utils.Gradle)
+		configFilePath, exists, err = utils.GetProjectConfFilePathFromDir(utils.Gradle, projectDir)
 		if err != nil {
 			return
 		}
@@ -45,5 +45,5 @@ func runGradle(buildConfiguration *utils.BuildConfiguration, excludeTestDeps, us
 	if err != nil {
 		return err
 	}
-	return gradleutils.RunGradle(vConfig, tasks, "", buildConfiguration, 0, useWrapper, true)
+	return gradleutils.RunGradleInDir(vConfig, projectDir, tasks, "", buildConfiguration, 0, useWrapper, true)
 }
@@ -2,6 +2,7 @@ package java
 
 import (
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	buildinfo "github.com/jfrog/build-info-go/entities"
@@ -15,8 +16,12 @@ const (
 	GavPackageTypeIdentifier = "gav://"
 )
 
+// Used to generate a unique build number for each audited project, since several projects may be audited at the same time.
+var buildsCounter uint32
+
 func createBuildConfiguration(buildName string) (*artifactoryUtils.BuildConfiguration, func(err error)) {
-	buildConfiguration := artifactoryUtils.NewBuildConfiguration(buildName, strconv.FormatInt(time.Now().Unix(), 10), "", "")
+	buildNumber := strconv.FormatInt(time.Now().Unix(), 10) + "-" + strconv.FormatUint(uint64(atomic.AddUint32(&buildsCounter, 1)), 10)
+	buildConfiguration := artifactoryUtils.NewBuildConfiguration(buildName, buildNumber, "", "")
 	return buildConfiguration, func(err error) {
 		buildName, err := buildConfiguration.GetBuildName()
 		if err != nil {
@@ -9,11 +9,11 @@ import (
 	"github.com/jfrog/jfrog-client-go/xray/services"
 )
 
-func BuildMvnDependencyTree(insecureTls, ignoreConfigFile bool) (modules []*services.GraphNode, err error) {
+func BuildMvnDependencyTree(projectDir string, insecureTls, ignoreConfigFile bool) (modules []*services.GraphNode, err error) {
 	buildConfiguration, cleanBuild := createBuildConfiguration("audit-mvn")
 	defer cleanBuild(err)
 
-	err = runMvn(buildConfiguration, insecureTls, ignoreConfigFile)
+	err = runMvn(projectDir, buildConfiguration, insecureTls, ignoreConfigFile)
 	if err != nil {
 		return
 	}
@@ -21,13 +21,13 @@ func BuildMvnDependencyTree(insecureTls, ignoreConfigFile bool) (modules []*serv
 	return createGavDependencyTree(buildConfiguration)
 }
 
-func runMvn(buildConfiguration *utils.BuildConfiguration, insecureTls, ignoreConfigFile bool) (err error) {
+func runMvn(projectDir string, buildConfiguration *utils.BuildConfiguration, insecureTls, ignoreConfigFile bool) (err error) {
 	goals := []string{"-B", "compile", "test-compile"}
 	log.Debug(fmt.Sprintf("mvn command goals: %v", goals))
 	configFilePath := ""
 	if !ignoreConfigFile {
 		var exists bool
-		configFilePath, exists, err = utils.GetProjectConfFilePath(utils.Maven)
+		configFilePath, exists, err = utils.GetProjectConfFilePathFromDir(utils.Maven, projectDir)
 		if err != nil {
 			return
 		}
@@ -40,5 +40,5 @@ func runMvn(buildConfiguration *utils.BuildConfiguration, insecureTls, ignoreCon
 	if err != nil {
 		return err
 	}
-	return mvnutils.RunMvn(vConfig, "", buildConfiguration, goals, 0, insecureTls, true)
+	return mvnutils.RunMvnInDir(vConfig, projectDir, "", buildConfiguration, goals, 0, insecureTls, true)
 }
@@ -10,11 +10,11 @@ import (
 
 func TestMavenTreesMultiModule(t *testing.T) {
 	// Create and change directory to test workspace
-	_, cleanUp := audit.CreateTestWorkspace(t, "maven-example")
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, "maven-example")
 	defer cleanUp()
 
 	// Run getModulesDependencyTrees
-	modulesDependencyTrees, err := BuildMvnDependencyTree(false, true)
+	modulesDependencyTrees, err := BuildMvnDependencyTree(tempDirPath, false, true)
 	if assert.NoError(t, err) && assert.NotEmpty(t, modulesDependencyTrees) {
 		// Check root module
 		multi := audit.GetAndAssertNode(t, modulesDependencyTrees, "org.jfrog.test:multi:3.7-SNAPSHOT")
@@ -3,7 +3,6 @@ package npm
 import (
 	biutils "github.com/jfrog/build-info-go/build/utils"
 	buildinfo "github.com/jfrog/build-info-go/entities"
-	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray/services"
@@ -13,21 +12,24 @@ const (
 	npmPackageTypeIdentifier = "npm://"
 )
 
-func BuildDependencyTree(npmArgs []string) (dependencyTree []*services.GraphNode, err error) {
-	currentDir, err := coreutils.GetWorkingDirectory()
-	if err != nil {
-		return
-	}
+// BuildDependencyTree builds the dependency tree of the project in projectDir using npm.
+// If npm isn't installed, the tree is built from the project's lockfile instead (see BuildDependencyTreeFromLockfile).
+func BuildDependencyTree(projectDir string, npmArgs []string) (dependencyTree []*services.GraphNode, err error) {
 	npmVersion, npmExecutablePath, err := biutils.GetNpmVersionAndExecPath(log.Logger)
 	if err != nil {
-		return
+		lockfilePath, lockfileErr := getNpmLockfilePath(projectDir)
+		if lockfileErr != nil || lockfilePath == "" {
+			return
+		}
+		log.Info("npm isn't available (" + err.Error() + "). Building the dependency tree from " + lockfilePath + "...")
+		return BuildDependencyTreeFromLockfile(projectDir, npmArgs)
 	}
-	packageInfo, err := biutils.ReadPackageInfoFromPackageJson(currentDir, npmVersion)
+	packageInfo, err := biutils.ReadPackageInfoFromPackageJson(projectDir, npmVersion)
 	if err != nil {
 		return
 	}
 	// Calculate npm dependencies
-	dependenciesList, err := biutils.CalculateNpmDependenciesList(npmExecutablePath, currentDir, packageInfo.BuildInfoModuleId(), npmArgs, false, log.Logger)
+	dependenciesList, err := biutils.CalculateNpmDependenciesList(npmExecutablePath, projectDir, packageInfo.BuildInfoModuleId(), npmArgs, false, log.Logger)
 	if err != nil {
 		return
 	}
@@ -3,6 +3,7 @@ package npm
 import (
 	"encoding/json"
 	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	biutils "github.com/jfrog/build-info-go/build/utils"
@@ -76,3 +77,63 @@ func TestParseNpmDependenciesList(t *testing.T) {
 	}
 
 }
+
+func TestBuildDependencyTreeFromLockfile(t *testing.T) {
+	for _, lockfileVersion := range []string{"v1", "v3"} {
+		t.Run(lockfileVersion, func(t *testing.T) {
+			projectDir := filepath.Join("..", "..", "commands", "testdata", "npm-lockfile", lockfileVersion)
+			dNode := func(version string) *services.GraphNode {
+				return &services.GraphNode{Id: "npm://d:" + version, Nodes: []*services.GraphNode{}}
+			}
+			prodNodes := []*services.GraphNode{
+				{Id: "npm://@scope/b:2.0.0", Nodes: []*services.GraphNode{dNode("2.0.0")}},
+				{Id: "npm://a:1.0.0", Nodes: []*services.GraphNode{dNode("1.0.0")}},
+				{Id: "npm://real-f:3.0.0", Nodes: []*services.GraphNode{}},
+			}
+			devNode := &services.GraphNode{Id: "npm://c:1.0.0", Nodes: []*services.GraphNode{dNode("1.0.0")}}
+
+			trees, err := BuildDependencyTreeFromLockfile(projectDir, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, []*services.GraphNode{{Id: "npm://lockfile-project:1.0.0", Nodes: []*services.GraphNode{prodNodes[0], prodNodes[1], devNode, prodNodes[2]}}}, trees)
+
+			trees, err = BuildDependencyTreeFromLockfile(projectDir, []string{"--prod"})
+			assert.NoError(t, err)
+			assert.Equal(t, []*services.GraphNode{{Id: "npm://lockfile-project:1.0.0", Nodes: prodNodes}}, trees)
+
+			trees, err = BuildDependencyTreeFromLockfile(projectDir, []string{"--dev"})
+			assert.NoError(t, err)
+			assert.Equal(t, []*services.GraphNode{{Id: "npm://lockfile-project:1.0.0", Nodes: []*services.GraphNode{devNode}}}, trees)
+		})
+	}
+
+	_, err := BuildDependencyTreeFromLockfile(t.TempDir(), nil)
+	assert.ErrorContains(t, err, "couldn't find a package-lock.json")
+}
+
+func TestResolveNpmPackage(t *testing.T) {
+	packages := map[string]*npmLockfilePackage{
+		"node_modules/a":                       {Version: "1.0.0"},
+		"node_modules/@scope/b":                {Version: "1.0.0"},
+		"node_modules/@scope/b/node_modules/a": {Version: "2.0.0"},
+		"node_modules/@scope/b/node_modules/c": {Version: "1.0.0"},
+		"node_modules/workspace":               {Link: true, Resolved: "packages/workspace"},
+		"packages/workspace":                   {Name: "workspace", Version: "0.1.0"},
+		"packages/workspace/node_modules/a":    {Version: "3.0.0"},
+	}
+	location, resolved := resolveNpmPackage(packages, "node_modules/@scope/b/node_modules/c", "a")
+	assert.Equal(t, "node_modules/@scope/b/node_modules/a", location)
+	assert.Equal(t, "2.0.0", resolved.Version)
+	location, _ = resolveNpmPackage(packages, "node_modules/@scope/b", "a")
+	assert.Equal(t, "node_modules/@scope/b/node_modules/a", location)
+	location, _ = resolveNpmPackage(packages, "node_modules/a", "a")
+	assert.Equal(t, "node_modules/a", location)
+	location, resolved = resolveNpmPackage(packages, "", "workspace")
+	assert.Equal(t, "packages/workspace", location)
+	assert.Equal(t, "workspace", getNpmPackageName(location, resolved))
+	location, _ = resolveNpmPackage(packages, "packages/workspace", "a")
+	assert.Equal(t, "packages/workspace/node_modules/a", location)
+	location, _ = resolveNpmPackage(packages, "packages/workspace", "@scope/b")
+	assert.Equal(t, "node_modules/@scope/b", location)
+	_, resolved = resolveNpmPackage(packages, "node_modules/a", "missing")
+	assert.Nil(t, resolved)
+}
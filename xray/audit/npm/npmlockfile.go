@@ -0,0 +1,276 @@
+package npm
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	biutils "github.com/jfrog/build-info-go/build/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+// The lockfiles npm creates, by priority. If both exist, npm uses npm-shrinkwrap.json.
+var npmLockfiles = []string{"npm-shrinkwrap.json", "package-lock.json"}
+
+type npmLockfile struct {
+	LockfileVersion int `json:"lockfileVersion"`
+	// The packages installed in the project by their location in the node_modules tree (lockfile version 2 and above).
+	// The root project's location is an empty string.
+	Packages map[string]*npmLockfilePackage `json:"packages"`
+	// The nested dependencies tree (lockfile version 1).
+	Dependencies map[string]*npmLockfileDependency `json:"dependencies"`
+}
+
+type npmLockfilePackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	// Link packages are symbolic links to other locations, like workspaces. Resolved is the location of the linked package.
+	Link     bool   `json:"link"`
+	Resolved string `json:"resolved"`
+}
+
+type npmLockfileDependency struct {
+	Version      string                            `json:"version"`
+	Requires     map[string]string                 `json:"requires"`
+	Dependencies map[string]*npmLockfileDependency `json:"dependencies"`
+}
+
+// The direct dependencies of the root project, from its package.json.
+type npmPackageJsonDependencies struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// BuildDependencyTreeFromLockfile builds the dependency tree of the project in projectDir from its npm-shrinkwrap.json or package-lock.json,
+// without running npm. All lockfile versions are supported.
+// Like with 'npm ls', the '--dev' and '--prod' npmArgs limit the tree to the development or production dependencies.
+func BuildDependencyTreeFromLockfile(projectDir string, npmArgs []string) (dependencyTree []*services.GraphNode, err error) {
+	lockfilePath, err := getNpmLockfilePath(projectDir)
+	if err != nil {
+		return
+	}
+	if lockfilePath == "" {
+		return nil, errorutils.CheckErrorf("couldn't find a package-lock.json or an npm-shrinkwrap.json file in %s. Run 'npm install' to create one.", projectDir)
+	}
+	packageJson, err := os.ReadFile(filepath.Join(projectDir, "package.json"))
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	packageInfo, err := biutils.ReadPackageInfo(packageJson, nil)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	lockfileContent, err := os.ReadFile(lockfilePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	var lockfile npmLockfile
+	if err = errorutils.CheckError(json.Unmarshal(lockfileContent, &lockfile)); err != nil {
+		return
+	}
+	includeProd, includeDev := GetDependenciesScopes(npmArgs)
+	rootId := npmPackageTypeIdentifier + packageInfo.BuildInfoModuleId()
+	var treeMap map[string][]string
+	if lockfile.Packages != nil {
+		treeMap = parseNpmLockfilePackages(lockfile.Packages, rootId, includeProd, includeDev)
+	} else {
+		var rootDependencies npmPackageJsonDependencies
+		if err = errorutils.CheckError(json.Unmarshal(packageJson, &rootDependencies)); err != nil {
+			return
+		}
+		treeMap = parseNpmLockfileDependencies(lockfile.Dependencies, rootDependencies, rootId, includeProd, includeDev)
+	}
+	dependencyTree = []*services.GraphNode{audit.BuildXrayDependencyTree(treeMap, rootId)}
+	return
+}
+
+// Returns the path of the project's lockfile, or an empty string if the project has no lockfile.
+func getNpmLockfilePath(projectDir string) (string, error) {
+	for _, lockfileName := range npmLockfiles {
+		lockfilePath := filepath.Join(projectDir, lockfileName)
+		exists, err := fileutils.IsFileExists(lockfilePath, false)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return lockfilePath, nil
+		}
+	}
+	return "", nil
+}
+
+// GetDependenciesScopes returns the scopes of the root project's dependencies to include, according to the '--dev' and '--prod' args (see GenericAuditCommand.SetNpmScope).
+// The args of the pnpm technology have the same meaning.
+func GetDependenciesScopes(npmArgs []string) (includeProd, includeDev bool) {
+	includeProd, includeDev = true, true
+	for _, arg := range npmArgs {
+		switch arg {
+		case "--dev":
+			includeProd = false
+		case "--prod":
+			includeDev = false
+		}
+	}
+	return
+}
+
+// Returns the names of the dependencies in the given scopes, sorted to keep the tree stable.
+func getDependenciesNames(dependencies, devDependencies, optionalDependencies map[string]string, includeProd, includeDev bool) (names []string) {
+	var scopes []map[string]string
+	if includeProd {
+		scopes = append(scopes, dependencies, optionalDependencies)
+	}
+	if includeDev {
+		scopes = append(scopes, devDependencies)
+	}
+	exists := make(map[string]bool)
+	for _, scope := range scopes {
+		for name := range scope {
+			if !exists[name] {
+				exists[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// Builds the dependencies map (see audit.BuildXrayDependencyTree) from the packages of lockfile version 2 and above.
+// Each package's dependencies are resolved the way Node.js resolves modules: in the node_modules directory of the package,
+// then in the node_modules directories of its parents.
+func parseNpmLockfilePackages(packages map[string]*npmLockfilePackage, rootId string, includeProd, includeDev bool) map[string][]string {
+	treeMap := make(map[string][]string)
+	root := packages[""]
+	if root == nil {
+		root = &npmLockfilePackage{}
+	}
+	visited := make(map[string]bool)
+	var visit func(parentId, location string, dependenciesNames []string)
+	visit = func(parentId, location string, dependenciesNames []string) {
+		for _, dependencyName := range dependenciesNames {
+			dependencyLocation, dependency := resolveNpmPackage(packages, location, dependencyName)
+			if dependency == nil {
+				// Missing optional and peer dependencies aren't installed
+				continue
+			}
+			dependencyId := npmPackageTypeIdentifier + getNpmPackageName(dependencyLocation, dependency) + ":" + dependency.Version
+			treeMap[parentId] = coreutils.AppendUnique(treeMap[parentId], dependencyId)
+			if visited[dependencyLocation] {
+				continue
+			}
+			visited[dependencyLocation] = true
+			visit(dependencyId, dependencyLocation, getDependenciesNames(dependency.Dependencies, nil, mergeMaps(dependency.OptionalDependencies, dependency.PeerDependencies), true, false))
+		}
+	}
+	visit(rootId, "", getDependenciesNames(root.Dependencies, root.DevDependencies, root.OptionalDependencies, includeProd, includeDev))
+	return treeMap
+}
+
+// Returns the location and the package that a package in the given location gets when it requires dependencyName.
+// Links are followed to the linked packages.
+func resolveNpmPackage(packages map[string]*npmLockfilePackage, location, dependencyName string) (string, *npmLockfilePackage) {
+	for {
+		candidate := path.Join(location, "node_modules", dependencyName)
+		if dependency, exists := packages[candidate]; exists {
+			if dependency.Link {
+				linked, exists := packages[dependency.Resolved]
+				if !exists {
+					return "", nil
+				}
+				return dependency.Resolved, linked
+			}
+			return candidate, dependency
+		}
+		if location == "" {
+			return "", nil
+		}
+		// Move to the parent package, skipping the 'node_modules' directory (and the scope directory of scoped packages)
+		parentIndex := strings.LastIndex(location, "node_modules/")
+		if parentIndex < 0 {
+			// The location of a workspace, which resolves its dependencies in the root node_modules directory
+			location = ""
+			continue
+		}
+		location = strings.TrimSuffix(location[:parentIndex], "/")
+	}
+}
+
+// The name of an installed package is the name of its directory, unless it's an alias or a workspace, which have their real names in the lockfile.
+func getNpmPackageName(location string, dependency *npmLockfilePackage) string {
+	if dependency.Name != "" {
+		return dependency.Name
+	}
+	if index := strings.LastIndex(location, "node_modules/"); index >= 0 {
+		return location[index+len("node_modules/"):]
+	}
+	return path.Base(location)
+}
+
+// Builds the dependencies map (see audit.BuildXrayDependencyTree) from the nested dependencies of lockfile version 1,
+// in which the requirements of each dependency are resolved in its own nested dependencies, and then in the nested dependencies of its parents.
+// Since version 1 lockfiles don't include the root project, its direct dependencies are taken from package.json.
+func parseNpmLockfileDependencies(dependencies map[string]*npmLockfileDependency, rootDependencies npmPackageJsonDependencies, rootId string, includeProd, includeDev bool) map[string][]string {
+	treeMap := make(map[string][]string)
+	visited := make(map[*npmLockfileDependency]bool)
+	var visit func(parentId string, scopes []map[string]*npmLockfileDependency, dependenciesNames []string)
+	visit = func(parentId string, scopes []map[string]*npmLockfileDependency, dependenciesNames []string) {
+		for _, dependencyName := range dependenciesNames {
+			var dependency *npmLockfileDependency
+			var dependencyScopes []map[string]*npmLockfileDependency
+			for i := len(scopes) - 1; i >= 0 && dependency == nil; i-- {
+				dependency, dependencyScopes = scopes[i][dependencyName], scopes[:i+1]
+			}
+			if dependency == nil {
+				continue
+			}
+			dependencyId := npmPackageTypeIdentifier + getNpmLockfileV1DependencyId(dependencyName, dependency.Version)
+			treeMap[parentId] = coreutils.AppendUnique(treeMap[parentId], dependencyId)
+			if visited[dependency] {
+				continue
+			}
+			visited[dependency] = true
+			var requires []string
+			for name := range dependency.Requires {
+				requires = append(requires, name)
+			}
+			sort.Strings(requires)
+			visit(dependencyId, append(dependencyScopes[:len(dependencyScopes):len(dependencyScopes)], dependency.Dependencies), requires)
+		}
+	}
+	visit(rootId, []map[string]*npmLockfileDependency{dependencies}, getDependenciesNames(rootDependencies.Dependencies, rootDependencies.DevDependencies, rootDependencies.OptionalDependencies, includeProd, includeDev))
+	return treeMap
+}
+
+// Aliased dependencies have versions like 'npm:real-name@1.0.0'.
+func getNpmLockfileV1DependencyId(name, version string) string {
+	if aliased := strings.TrimPrefix(version, "npm:"); aliased != version {
+		if separatorIndex := strings.LastIndex(aliased, "@"); separatorIndex > 0 {
+			return aliased[:separatorIndex] + ":" + aliased[separatorIndex+1:]
+		}
+	}
+	return name + ":" + version
+}
+
+func mergeMaps(first, second map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for key, value := range first {
+		merged[key] = value
+	}
+	for key, value := range second {
+		merged[key] = value
+	}
+	return merged
+}
@@ -2,7 +2,6 @@ package nuget
 
 import (
 	"github.com/jfrog/jfrog-client-go/utils/log"
-	"os"
 
 	"github.com/jfrog/build-info-go/build/utils/dotnet/solution"
 	"github.com/jfrog/build-info-go/entities"
@@ -14,12 +13,8 @@ const (
 	nugetPackageTypeIdentifier = "nuget://"
 )
 
-func BuildDependencyTree() (dependencyTree []*services.GraphNode, err error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return
-	}
-	sol, err := solution.Load(wd, "", log.Logger)
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
+	sol, err := solution.Load(projectDir, "", log.Logger)
 	if err != nil {
 		return
 	}
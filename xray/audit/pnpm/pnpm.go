@@ -0,0 +1,68 @@
+package pnpm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	biutils "github.com/jfrog/build-info-go/build/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils/pnpm"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/npm"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	npmPackageTypeIdentifier = "npm://"
+)
+
+// BuildDependencyTree builds a dependency tree for each of the projects in the pnpm-lock.yaml of projectDir (the project itself and the projects
+// of its workspace), without running pnpm.
+// Like with the npm technology, the '--dev' and '--prod' pnpmArgs limit the trees to the development or production dependencies.
+func BuildDependencyTree(projectDir string, pnpmArgs []string) (dependencyTree []*services.GraphNode, err error) {
+	lockfile, err := pnpm.ReadLockfile(projectDir)
+	if err != nil {
+		return
+	}
+	includeProd, includeDev := npm.GetDependenciesScopes(pnpmArgs)
+	importers := lockfile.GetImporters()
+	var importersPaths []string
+	for importerPath := range importers {
+		importersPaths = append(importersPaths, importerPath)
+	}
+	sort.Strings(importersPaths)
+	for _, importerPath := range importersPaths {
+		rootId, err := getImporterId(projectDir, importerPath)
+		if err != nil {
+			return nil, err
+		}
+		directDependencies, dependenciesGraph := lockfile.GetDependenciesGraph(importers[importerPath], includeProd, includeDev)
+		treeMap := map[string][]string{rootId: addPackageTypeIdentifier(directDependencies)}
+		for id, dependencies := range dependenciesGraph {
+			treeMap[npmPackageTypeIdentifier+id] = addPackageTypeIdentifier(dependencies)
+		}
+		dependencyTree = append(dependencyTree, audit.BuildXrayDependencyTree(treeMap, rootId))
+	}
+	return
+}
+
+// Returns the ID of a project in the lockfile, by the name and version in its package.json.
+func getImporterId(projectDir, importerPath string) (string, error) {
+	packageJson, err := os.ReadFile(filepath.Join(projectDir, importerPath, "package.json"))
+	if errorutils.CheckError(err) != nil {
+		return "", err
+	}
+	packageInfo, err := biutils.ReadPackageInfo(packageJson, nil)
+	if errorutils.CheckError(err) != nil {
+		return "", err
+	}
+	return npmPackageTypeIdentifier + packageInfo.BuildInfoModuleId(), nil
+}
+
+func addPackageTypeIdentifier(ids []string) (idsWithIdentifier []string) {
+	for _, id := range ids {
+		idsWithIdentifier = append(idsWithIdentifier, npmPackageTypeIdentifier+id)
+	}
+	return
+}
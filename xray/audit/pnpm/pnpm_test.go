@@ -0,0 +1,47 @@
+package pnpm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyTree(t *testing.T) {
+	projectDir := filepath.Join("..", "..", "commands", "testdata", "pnpm-project")
+	looseEnvify := &services.GraphNode{Id: "npm://loose-envify:1.4.0", Nodes: []*services.GraphNode{
+		{Id: "npm://js-tokens:4.0.0", Nodes: []*services.GraphNode{}},
+	}}
+	// The peer dependencies are removed from the version, and the aliased lodash is identified by its real name
+	reactDom := &services.GraphNode{Id: "npm://react-dom:18.2.0", Nodes: []*services.GraphNode{
+		looseEnvify,
+		{Id: "npm://react:18.2.0", Nodes: []*services.GraphNode{looseEnvify}},
+		{Id: "npm://scheduler:0.23.0", Nodes: []*services.GraphNode{looseEnvify}},
+	}}
+	lodash := &services.GraphNode{Id: "npm://lodash:4.17.21", Nodes: []*services.GraphNode{}}
+	utils := &services.GraphNode{Id: "npm://pnpm-project:utils:0.1.0", Nodes: []*services.GraphNode{
+		{Id: "npm://ms:2.1.3", Nodes: []*services.GraphNode{}},
+	}}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		expected []*services.GraphNode
+	}{
+		{"all", nil, []*services.GraphNode{{Id: "npm://pnpm-project:1.0.0", Nodes: []*services.GraphNode{lodash, reactDom}}, utils}},
+		{"prodOnly", []string{"--prod"}, []*services.GraphNode{{Id: "npm://pnpm-project:1.0.0", Nodes: []*services.GraphNode{reactDom}}, utils}},
+		{"devOnly", []string{"--dev"}, []*services.GraphNode{{Id: "npm://pnpm-project:1.0.0", Nodes: []*services.GraphNode{lodash}},
+			{Id: "npm://pnpm-project:utils:0.1.0", Nodes: []*services.GraphNode{}}}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			trees, err := BuildDependencyTree(projectDir, testCase.args)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, trees)
+		})
+	}
+
+	_, err := BuildDependencyTree(t.TempDir(), nil)
+	assert.ErrorContains(t, err, "couldn't find pnpm-lock.yaml")
+}
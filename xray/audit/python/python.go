@@ -2,6 +2,8 @@ package python
 
 import (
 	"bytes"
+	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -20,13 +22,41 @@ import (
 
 const (
 	pythonPackageTypeIdentifier = "pypi://"
+	// The version of the pipdeptree script of build-info-go, which is saved under the same path as build-info-go saves it.
+	pipDepTreeVersion  = "5"
+	pipDepTreeFileName = "pipdeptree.py"
 )
 
-func BuildDependencyTree(pythonTool pythonutils.PythonTool, requirementsFile string) (dependencyTree []*services.GraphNode, err error) {
-	dependenciesGraph, rootNode, directDependenciesList, err := getDependencies(pythonTool, requirementsFile)
+// The pipdeptree script of build-info-go (pythonutils), which lists the packages installed in the Python environment that runs it.
+// build-info-go runs it with the Python executable found in the PATH of the process, so it's run here with the executable of the virtual environment instead.
+//
+//go:embed pipdeptree.py
+var pipDepTreeContent []byte
+
+// The packages in the output of 'pipenv graph --json' and of pipdeptree.
+type pythonDependencyPackage struct {
+	Package      pythonPackage   `json:"package"`
+	Dependencies []pythonPackage `json:"dependencies"`
+}
+
+type pythonPackage struct {
+	Key              string `json:"key"`
+	InstalledVersion string `json:"installed_version"`
+}
+
+// BuildDependencyTree builds the dependency tree of the Python project in projectDir.
+// Poetry and Pipenv projects with a lockfile are resolved from the lockfile. Otherwise, the project's dependencies are installed in a virtual environment first.
+func BuildDependencyTree(projectDir string, pythonTool pythonutils.PythonTool, requirementsFile string) (dependencyTree []*services.GraphNode, err error) {
+	dependenciesGraph, rootNode, directDependenciesList, lockfileFound, err := getLockfileDependencies(projectDir, pythonTool)
 	if err != nil {
 		return
 	}
+	if !lockfileFound {
+		dependenciesGraph, rootNode, directDependenciesList, err = getDependencies(projectDir, pythonTool, requirementsFile)
+		if err != nil {
+			return
+		}
+	}
 	directDependencies := []*services.GraphNode{}
 	for _, rootDep := range directDependenciesList {
 		directDependency := &services.GraphNode{
@@ -43,116 +73,91 @@ func BuildDependencyTree(pythonTool pythonutils.PythonTool, requirementsFile str
 	return []*services.GraphNode{root}, nil
 }
 
-func getDependencies(pythonTool pythonutils.PythonTool, requirementsFile string) (dependenciesGraph map[string][]string, rootNodeName string, directDependencies []string, err error) {
-	wd, err := os.Getwd()
-	if errorutils.CheckError(err) != nil {
-		return
-	}
-
-	// Create temp dir to run all work outside users working directory
+func getDependencies(projectDir string, pythonTool pythonutils.PythonTool, requirementsFile string) (dependenciesGraph map[string][]string, rootNodeName string, directDependencies []string, err error) {
+	// Create temp dir to run all work outside the project's directory
 	tempDirPath, err := fileutils.CreateTempDir()
 	if err != nil {
 		return
 	}
 
-	err = os.Chdir(tempDirPath)
-	if errorutils.CheckError(err) != nil {
-		return
-	}
-
 	defer func() {
-		e := os.Chdir(wd)
-		if err == nil {
-			err = errorutils.CheckError(e)
-		}
-
-		e = fileutils.RemoveTempDir(tempDirPath)
+		e := fileutils.RemoveTempDir(tempDirPath)
 		if err == nil {
 			err = e
 		}
 	}()
 
-	err = fileutils.CopyDir(wd, tempDirPath, true, nil)
-	if err != nil {
-		return
-	}
-
-	restoreEnv, err := runPythonInstall(pythonTool, requirementsFile)
+	err = fileutils.CopyDir(projectDir, tempDirPath, true, nil)
 	if err != nil {
 		return
 	}
-	defer func() {
-		e := restoreEnv()
-		if err == nil {
-			err = e
-		}
-	}()
 
-	localDependenciesPath, err := config.GetJfrogDependenciesPath()
+	env, err := runPythonInstall(tempDirPath, pythonTool, requirementsFile)
 	if err != nil {
 		return
 	}
-	dependenciesGraph, directDependencies, err = pythonutils.GetPythonDependencies(pythonTool, tempDirPath, localDependenciesPath)
+	dependenciesGraph, directDependencies, err = getInstalledDependencies(tempDirPath, pythonTool, env)
 	if err != nil {
 		return
 	}
 	rootNodeName, pkgNameErr := pythonutils.GetPackageName(pythonTool, tempDirPath)
 	if pkgNameErr != nil {
 		clientLog.Debug("Couldn't retrieve Python package name. Reason:", pkgNameErr.Error())
-		// If package name couldn't be determined by the Python utils, use the project dir name.
-		rootNodeName = filepath.Base(filepath.Dir(wd))
+	}
+	if rootNodeName == "" {
+		// Projects without a setup.py have no package name
+		rootNodeName = getDefaultRootNodeName(projectDir)
 	}
 	return
 }
 
-func runPythonInstall(pythonTool pythonutils.PythonTool, requirementsFile string) (restoreEnv func() error, err error) {
+// Returns the name of the root node of a project whose package name can't be determined, which is the name of the project's directory.
+func getDefaultRootNodeName(projectDir string) string {
+	return filepath.Base(projectDir)
+}
+
+// Runs the install command of the Python tool in srcPath.
+// Returns the environment variables the tool should run with to find the installed dependencies. They are set on the commands only,
+// so that projects can be installed concurrently.
+func runPythonInstall(srcPath string, pythonTool pythonutils.PythonTool, requirementsFile string) (env []string, err error) {
 	var output []byte
 	switch pythonTool {
 	case pythonutils.Pip:
-		restoreEnv, err = SetPipVirtualEnvPath()
-		if err != nil {
+		if err = createVirtualEnv(srcPath); err != nil {
 			return
 		}
+		env = getVirtualEnvVars(srcPath)
 		// Run pip install
-		pipExec, _ := exec.LookPath("pip3")
-		if pipExec == "" {
-			pipExec = "pip"
+		var pipExec string
+		if pipExec, err = getVirtualEnvExecutable(srcPath, "pip"); err != nil {
+			return
 		}
 		if requirementsFile != "" {
 			clientLog.Debug("Running pip install -r", requirementsFile)
-			output, err = exec.Command(pipExec, "install", "-r", requirementsFile).CombinedOutput()
+			output, err = runInDir(srcPath, env, pipExec, "install", "-r", requirementsFile)
 		} else {
 			clientLog.Debug("Running 'pip install .'")
-			output, err = exec.Command(pipExec, "install", ".").CombinedOutput()
+			output, err = runInDir(srcPath, env, pipExec, "install", ".")
 			if err != nil {
 				err = errorutils.CheckErrorf("pip install command failed: %s - %s", err.Error(), output)
 				clientLog.Debug(fmt.Sprintf("Failed running 'pip install .' : \n%s\n trying 'pip install -r requirements.txt' ", err.Error()))
 				// Run pip install -r requirements
-				output, err = exec.Command(pipExec, "install", "-r", "requirements.txt").CombinedOutput()
+				output, err = runInDir(srcPath, env, pipExec, "install", "-r", "requirements.txt")
 			}
 		}
 
 	case pythonutils.Pipenv:
 		// Set virtualenv path to venv dir
-		err = os.Setenv("WORKON_HOME", ".jfrog")
-		if err != nil {
-			return
-		}
-		restoreEnv = func() error {
-			return os.Unsetenv("WORKON_HOME")
-		}
+		env = append(os.Environ(), "WORKON_HOME=.jfrog")
 		// Run pipenv install
-		output, err = exec.Command("pipenv", "install", "-d").CombinedOutput()
+		output, err = runInDir(srcPath, env, "pipenv", "install", "-d")
 		if err != nil {
 			err = errorutils.CheckErrorf("pipenv install command failed: %s - %s", err.Error(), output)
 		}
 	case pythonutils.Poetry:
 		// No changes to env here.
-		restoreEnv = func() error {
-			return nil
-		}
 		// Run poetry install
-		output, err = exec.Command("poetry", "install").CombinedOutput()
+		output, err = runInDir(srcPath, nil, "poetry", "install")
 	}
 
 	if err != nil {
@@ -161,8 +166,87 @@ func runPythonInstall(pythonTool pythonutils.PythonTool, requirementsFile string
 	return
 }
 
-// Execute virtualenv command: "virtualenv venvdir" / "python3 -m venv venvdir" and set path
-func SetPipVirtualEnvPath() (func() error, error) {
+// Returns the dependencies graph and the direct dependencies of the packages installed in srcPath by runPythonInstall.
+// The pip and pipenv dependencies are listed in the environment of the installation.
+func getInstalledDependencies(srcPath string, pythonTool pythonutils.PythonTool, env []string) (dependenciesGraph map[string][]string, directDependencies []string, err error) {
+	var cmd *exec.Cmd
+	switch pythonTool {
+	case pythonutils.Pip:
+		var pythonExec, pipDepTreePath string
+		if pythonExec, err = getVirtualEnvExecutable(srcPath, "python"); err != nil {
+			return
+		}
+		if pipDepTreePath, err = getPipDepTreeScriptPath(); err != nil {
+			return
+		}
+		cmd = exec.Command(pythonExec, pipDepTreePath, "--json")
+		// pipdeptree reads the installed packages with the pkg_resources backend of pip, which isn't the default backend of pip on Python 3.11 and above
+		env = append(env, "_PIP_USE_IMPORTLIB_METADATA=0")
+	case pythonutils.Pipenv:
+		cmd = exec.Command("pipenv", "graph", "--json")
+	default:
+		var localDependenciesPath string
+		if localDependenciesPath, err = config.GetJfrogDependenciesPath(); err != nil {
+			return
+		}
+		return pythonutils.GetPythonDependencies(pythonTool, srcPath, localDependenciesPath)
+	}
+	var stderr bytes.Buffer
+	cmd.Dir, cmd.Env, cmd.Stderr = srcPath, env, &stderr
+	// Warnings are printed to stderr, so only stdout is parsed
+	output, err := cmd.Output()
+	if err != nil {
+		err = errorutils.CheckErrorf("failed to list the installed %s dependencies: %s - %s", string(pythonTool), err.Error(), stderr.String())
+		return
+	}
+	return parseDependenciesGraph(output)
+}
+
+// Runs a command in dir and returns its combined output.
+// The command runs with the given environment variables, or with the environment of the process if env is nil.
+func runInDir(dir string, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	return cmd.CombinedOutput()
+}
+
+// Returns the path of the pipdeptree script under the JFrog CLI dependencies directory, and saves the script there if it's missing.
+func getPipDepTreeScriptPath() (string, error) {
+	localDependenciesPath, err := config.GetJfrogDependenciesPath()
+	if err != nil {
+		return "", err
+	}
+	scriptDir := filepath.Join(localDependenciesPath, "pip", pipDepTreeVersion)
+	scriptPath := filepath.Join(scriptDir, pipDepTreeFileName)
+	exists, err := fileutils.IsFileExists(scriptPath, false)
+	if err != nil || exists {
+		return scriptPath, err
+	}
+	if err = os.MkdirAll(scriptDir, os.ModePerm); err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	// The script is renamed into place, so that concurrent audits never run a partially written script
+	tempFile, err := os.CreateTemp(scriptDir, pipDepTreeFileName+".*")
+	if err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	_, err = tempFile.Write(pipDepTreeContent)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), scriptPath)
+	}
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+		return "", errorutils.CheckError(err)
+	}
+	return scriptPath, nil
+}
+
+// Execute virtualenv command: "virtualenv venvdir" / "python3 -m venv venvdir" in srcPath
+func createVirtualEnv(srcPath string) error {
 	var cmdArgs []string
 	execPath, err := exec.LookPath("virtualenv")
 	if err != nil || execPath == "" {
@@ -177,45 +261,72 @@ func SetPipVirtualEnvPath() (func() error, error) {
 			cmdArgs = append(cmdArgs, "-m", "venv")
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if execPath == "" {
-			return nil, errors.New("could not find python3 or virtualenv executable in PATH")
+			return errors.New("could not find python3 or virtualenv executable in PATH")
 		}
 	}
 	cmdArgs = append(cmdArgs, "venvdir")
 	var stderr bytes.Buffer
 	pipVenv := exec.Command(execPath, cmdArgs...)
+	pipVenv.Dir = srcPath
 	pipVenv.Stderr = &stderr
 	err = pipVenv.Run()
 	if err != nil {
-		return nil, fmt.Errorf("pipenv install command failed: %s - %s", err.Error(), stderr.String())
+		return fmt.Errorf("pipenv install command failed: %s - %s", err.Error(), stderr.String())
 	}
+	return nil
+}
 
-	// Keep original value of 'PATH'.
-	pathValue, exists := os.LookupEnv("PATH")
-	if !exists {
-		return nil, errors.New("couldn't find PATH variable")
-	}
-	var newPathValue string
-	var virtualEnvPath string
+// Returns the directory of the executables of the virtual environment created by createVirtualEnv in srcPath.
+func getVirtualEnvBinDir(srcPath string) string {
 	if runtime.GOOS == "windows" {
-		virtualEnvPath, err = filepath.Abs(filepath.Join("venvdir", "Scripts"))
-		newPathValue = fmt.Sprintf("%s;", virtualEnvPath)
-	} else {
-		virtualEnvPath, err = filepath.Abs(filepath.Join("venvdir", "bin"))
-		newPathValue = fmt.Sprintf("%s:", virtualEnvPath)
+		return filepath.Join(srcPath, "venvdir", "Scripts")
 	}
-	if err != nil {
-		return nil, err
+	return filepath.Join(srcPath, "venvdir", "bin")
+}
+
+// Returns the path of an executable of the virtual environment in srcPath.
+// Commands look up their executable in the PATH of the process, so the executables of the environment are run by their full path.
+func getVirtualEnvExecutable(srcPath, name string) (string, error) {
+	execPath, err := exec.LookPath(filepath.Join(getVirtualEnvBinDir(srcPath), name))
+	return execPath, errorutils.CheckError(err)
+}
+
+// Returns the environment variables of the process, with the executables of the virtual environment in srcPath first in PATH.
+func getVirtualEnvVars(srcPath string) []string {
+	virtualEnvPath, _ := filepath.Abs(filepath.Join(srcPath, "venvdir"))
+	// When a variable is set more than once, commands get its last value
+	return append(os.Environ(),
+		"VIRTUAL_ENV="+virtualEnvPath,
+		"PATH="+filepath.Join(virtualEnvPath, filepath.Base(getVirtualEnvBinDir("")))+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// Parses the output of 'pipenv graph --json' or of 'pipdeptree --json'.
+// Returns the dependencies graph of the installed packages, and the packages no other package depends on as the direct dependencies.
+func parseDependenciesGraph(output []byte) (dependenciesGraph map[string][]string, directDependencies []string, err error) {
+	var packages []pythonDependencyPackage
+	if err = json.Unmarshal(output, &packages); err != nil {
+		return nil, nil, errorutils.CheckErrorf("failed to parse the installed Python dependencies: %s", err.Error())
 	}
-	err = os.Setenv("PATH", newPathValue)
-	if err != nil {
-		return nil, err
+	dependenciesGraph = make(map[string][]string)
+	subPackages := make(map[string]bool)
+	for _, pkg := range packages {
+		var dependencies []string
+		for _, dependency := range pkg.Dependencies {
+			dependencyId := dependency.Key + ":" + dependency.InstalledVersion
+			dependencies = append(dependencies, dependencyId)
+			subPackages[dependencyId] = true
+		}
+		dependenciesGraph[pkg.Package.Key+":"+pkg.Package.InstalledVersion] = dependencies
 	}
-	return func() error {
-		return os.Setenv("PATH", pathValue)
-	}, nil
+	for _, pkg := range packages {
+		if id := pkg.Package.Key + ":" + pkg.Package.InstalledVersion; !subPackages[id] {
+			directDependencies = append(directDependencies, id)
+		}
+	}
+	return
 }
 
 func populatePythonDependencyTree(currNode *services.GraphNode, dependenciesGraph map[string][]string) {
@@ -1,20 +1,22 @@
 package python
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/jfrog/build-info-go/utils/pythonutils"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestBuildPipDependencyListSetuppy(t *testing.T) {
 	// Create and change directory to test workspace
-	_, cleanUp := audit.CreateTestWorkspace(t, filepath.Join("pip-project", "setuppyproject"))
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, filepath.Join("pip-project", "setuppyproject"))
 	defer cleanUp()
 	// Run getModulesDependencyTrees
-	rootNode, err := BuildDependencyTree(pythonutils.Pip, "")
+	rootNode, err := BuildDependencyTree(tempDirPath, pythonutils.Pip, "")
 	assert.NoError(t, err)
 	assert.Len(t, rootNode, 1)
 	if len(rootNode) > 0 {
@@ -32,10 +34,10 @@ func TestBuildPipDependencyListSetuppy(t *testing.T) {
 
 func TestBuildPipDependencyListRequirements(t *testing.T) {
 	// Create and change directory to test workspace
-	_, cleanUp := audit.CreateTestWorkspace(t, filepath.Join("pip-project", "requirementsproject"))
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, filepath.Join("pip-project", "requirementsproject"))
 	defer cleanUp()
 	// Run getModulesDependencyTrees
-	rootNode, err := BuildDependencyTree(pythonutils.Pip, "requirements.txt")
+	rootNode, err := BuildDependencyTree(tempDirPath, pythonutils.Pip, "requirements.txt")
 	assert.NoError(t, err)
 	assert.Len(t, rootNode, 1)
 	if len(rootNode) > 0 {
@@ -51,10 +53,10 @@ func TestBuildPipDependencyListRequirements(t *testing.T) {
 
 func TestBuildPipenvDependencyList(t *testing.T) {
 	// Create and change directory to test workspace
-	_, cleanUp := audit.CreateTestWorkspace(t, "pipenv-project")
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, "pipenv-project")
 	defer cleanUp()
 	// Run getModulesDependencyTrees
-	rootNode, err := BuildDependencyTree(pythonutils.Pipenv, "")
+	rootNode, err := BuildDependencyTree(tempDirPath, pythonutils.Pipenv, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -72,10 +74,10 @@ func TestBuildPipenvDependencyList(t *testing.T) {
 
 func TestBuildPoetryDependencyList(t *testing.T) {
 	// Create and change directory to test workspace
-	_, cleanUp := audit.CreateTestWorkspace(t, "poetry-project")
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, "poetry-project")
 	defer cleanUp()
 	// Run getModulesDependencyTrees
-	rootNode, err := BuildDependencyTree(pythonutils.Poetry, "")
+	rootNode, err := BuildDependencyTree(tempDirPath, pythonutils.Poetry, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -93,3 +95,178 @@ func TestBuildPoetryDependencyList(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildPipenvDependencyListFromLockfile(t *testing.T) {
+	// Create and change directory to test workspace
+	tempDirPath, cleanUp := audit.CreateTestWorkspace(t, "pipenv-lockfile-project")
+	defer cleanUp()
+	// Pipfile.lock doesn't record the dependencies between the packages, so all the packages are dependencies of the project
+	rootNode, err := BuildDependencyTree(tempDirPath, pythonutils.Pipenv, "")
+	assert.NoError(t, err)
+	if assert.Len(t, rootNode, 1) {
+		assert.Equal(t, pythonPackageTypeIdentifier+filepath.Base(tempDirPath), rootNode[0].Id)
+		assert.Len(t, rootNode[0].Nodes, 3)
+		for _, childId := range []string{"pexpect:4.8.0", "ptyprocess:0.7.0", "toml:0.10.2"} {
+			assert.Empty(t, audit.GetAndAssertNode(t, rootNode[0].Nodes, childId).Nodes)
+		}
+	}
+}
+
+func TestGetPipenvLockDependencies(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "Pipfile.lock"), []byte(`{
+  "default": {
+    "requests": {"version": "==2.28.1"},
+    "urllib3": {"version": "==1.26.12"},
+    "local-package": {"path": "."}
+  },
+  "develop": {
+    "pytest": {"version": "==7.2.0"},
+    "urllib3": {"version": "==1.26.12"}
+  }
+}`), 0644))
+
+	dependenciesGraph, rootNodeName, directDependencies, found, err := getLockfileDependencies(projectDir, pythonutils.Pipenv)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, filepath.Base(projectDir), rootNodeName)
+	// No dependencies between the packages are made up
+	assert.Equal(t, []string{"requests:2.28.1", "urllib3:1.26.12", "pytest:7.2.0"}, directDependencies)
+	assert.Empty(t, dependenciesGraph)
+}
+
+func TestGetPoetryLockDependencies(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "pyproject.toml"), []byte(`[tool.poetry]
+name = "my-project"
+version = "1.0.0"
+
+[tool.poetry.dependencies]
+python = "^3.8"
+Requests = "^2.28"
+
+[tool.poetry.group.test.dependencies]
+pytest = "^7.0"
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "poetry.lock"), []byte(`[[package]]
+name = "requests"
+version = "2.28.1"
+
+[package.dependencies]
+charset-normalizer = ">=2,<3"
+urllib3 = {version = ">=1.21.1,<1.27", markers = "python_version >= \"3\""}
+
+[[package]]
+name = "charset-normalizer"
+version = "2.1.1"
+
+[[package]]
+name = "urllib3"
+version = "1.26.12"
+
+[[package]]
+name = "pytest"
+version = "7.2.0"
+
+[package.dependencies]
+colorama = {version = "*", markers = "sys_platform == \"win32\""}
+`), 0644))
+
+	dependenciesGraph, rootNodeName, directDependencies, found, err := getLockfileDependencies(projectDir, pythonutils.Poetry)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "my-project:1.0.0", rootNodeName)
+	assert.Equal(t, []string{"requests:2.28.1", "pytest:7.2.0"}, directDependencies)
+	assert.Equal(t, []string{"charset-normalizer:2.1.1", "urllib3:1.26.12"}, dependenciesGraph["requests:2.28.1"])
+	// Dependencies that are missing from the lockfile are skipped
+	assert.Empty(t, dependenciesGraph["pytest:7.2.0"])
+
+	// Projects without a lockfile are installed
+	_, _, _, found, err = getLockfileDependencies(t.TempDir(), pythonutils.Poetry)
+	assert.NoError(t, err)
+	assert.False(t, found)
+	_, _, _, found, err = getLockfileDependencies(projectDir, pythonutils.Pip)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestGetPoetryLockDependenciesMultipleVersions(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "pyproject.toml"), []byte(`[tool.poetry]
+name = "my-project"
+version = "1.0.0"
+
+[tool.poetry.dependencies]
+numpy = [{version = "1.21.6", python = "<3.8"}, {version = "1.24.2", python = ">=3.8"}]
+pandas = "^1.3"
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "poetry.lock"), []byte(`[[package]]
+name = "numpy"
+version = "1.21.6"
+
+[[package]]
+name = "numpy"
+version = "1.24.2"
+
+[[package]]
+name = "pandas"
+version = "1.3.5"
+
+[package.dependencies]
+numpy = ">=1.17.3"
+`), 0644))
+
+	dependenciesGraph, _, directDependencies, _, err := getLockfileDependencies(projectDir, pythonutils.Poetry)
+	assert.NoError(t, err)
+	// All the locked versions of a package are kept
+	assert.Equal(t, []string{"numpy:1.21.6", "numpy:1.24.2", "pandas:1.3.5"}, directDependencies)
+	// A constraint that doesn't pin a locked version may resolve to any of the locked versions
+	assert.Equal(t, []string{"numpy:1.21.6", "numpy:1.24.2"}, dependenciesGraph["pandas:1.3.5"])
+}
+
+func TestResolvePoetryDependency(t *testing.T) {
+	lockPackages := []poetryLockPackage{{Name: "numpy", Version: "1.21.6"}, {Name: "numpy", Version: "1.24.2"}}
+	assert.Equal(t, lockPackages[1:], resolvePoetryDependency(lockPackages, "==1.24.2"))
+	assert.Equal(t, lockPackages[:1], resolvePoetryDependency(lockPackages, map[string]interface{}{"version": "1.21.6", "python": "<3.8"}))
+	assert.Equal(t, lockPackages, resolvePoetryDependency(lockPackages, ">=1.21"))
+	assert.Equal(t, lockPackages[:1], resolvePoetryDependency(lockPackages[:1], "==1.24.2"))
+	assert.Empty(t, resolvePoetryDependency(nil, "*"))
+}
+
+func TestParseDependenciesGraph(t *testing.T) {
+	output := []byte(`[
+		{"package": {"key": "pip-example", "installed_version": "1.2.3"}, "dependencies": [{"key": "pexpect", "installed_version": "4.8.0"}]},
+		{"package": {"key": "pexpect", "installed_version": "4.8.0"}, "dependencies": [{"key": "ptyprocess", "installed_version": "0.7.0"}]},
+		{"package": {"key": "ptyprocess", "installed_version": "0.7.0"}, "dependencies": []}
+	]`)
+	dependenciesGraph, directDependencies, err := parseDependenciesGraph(output)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"pip-example:1.2.3": {"pexpect:4.8.0"},
+		"pexpect:4.8.0":     {"ptyprocess:0.7.0"},
+		"ptyprocess:0.7.0":  nil,
+	}, dependenciesGraph)
+	assert.Equal(t, []string{"pip-example:1.2.3"}, directDependencies)
+
+	_, _, err = parseDependenciesGraph([]byte("DeprecationWarning"))
+	assert.ErrorContains(t, err, "failed to parse the installed Python dependencies")
+}
+
+func TestGetPipDepTreeScriptPath(t *testing.T) {
+	dependenciesDir := t.TempDir()
+	t.Setenv(coreutils.DependenciesDir, dependenciesDir)
+	scriptPath, err := getPipDepTreeScriptPath()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dependenciesDir, "pip", pipDepTreeVersion, pipDepTreeFileName), scriptPath)
+	content, err := os.ReadFile(scriptPath)
+	assert.NoError(t, err)
+	assert.Equal(t, pipDepTreeContent, content)
+
+	// An existing script is kept
+	assert.NoError(t, os.WriteFile(scriptPath, []byte("existing"), 0644))
+	_, err = getPipDepTreeScriptPath()
+	assert.NoError(t, err)
+	content, err = os.ReadFile(scriptPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "existing", string(content))
+}
@@ -0,0 +1,200 @@
+package python
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jfrog/build-info-go/utils/pythonutils"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+)
+
+const (
+	poetryLockFileName = "poetry.lock"
+	pipenvLockFileName = "Pipfile.lock"
+)
+
+type pyprojectToml struct {
+	Tool struct {
+		Poetry poetryProject `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+type poetryProject struct {
+	Name            string                 `toml:"name"`
+	Version         string                 `toml:"version"`
+	Dependencies    map[string]interface{} `toml:"dependencies"`
+	DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+	// Dependency groups (Poetry 1.2 and above), like [tool.poetry.group.test.dependencies].
+	Group map[string]struct {
+		Dependencies map[string]interface{} `toml:"dependencies"`
+	} `toml:"group"`
+}
+
+type poetryLock struct {
+	Package []poetryLockPackage `toml:"package"`
+}
+
+type poetryLockPackage struct {
+	Name         string                 `toml:"name"`
+	Version      string                 `toml:"version"`
+	Dependencies map[string]interface{} `toml:"dependencies"`
+}
+
+type pipenvLock struct {
+	Default map[string]pipenvLockPackage `json:"default"`
+	Develop map[string]pipenvLockPackage `json:"develop"`
+}
+
+type pipenvLockPackage struct {
+	// Pinned versions, like '==1.2.3'. Empty for packages installed from VCS or local paths.
+	Version string `json:"version"`
+}
+
+// Returns the dependencies of the project in projectDir from its poetry.lock or Pipfile.lock, without installing them.
+// The dependencies graph and the direct dependencies are in the format returned by pythonutils.GetPythonDependencies.
+// found is false if the Python tool has no lockfile support, or if the project has no lockfile.
+func getLockfileDependencies(projectDir string, pythonTool pythonutils.PythonTool) (dependenciesGraph map[string][]string, rootNodeName string, directDependencies []string, found bool, err error) {
+	var lockFileName string
+	switch pythonTool {
+	case pythonutils.Poetry:
+		lockFileName = poetryLockFileName
+	case pythonutils.Pipenv:
+		lockFileName = pipenvLockFileName
+	default:
+		return
+	}
+	lockFilePath := filepath.Join(projectDir, lockFileName)
+	if found, err = fileutils.IsFileExists(lockFilePath, false); err != nil || !found {
+		return
+	}
+	if pythonTool == pythonutils.Poetry {
+		dependenciesGraph, rootNodeName, directDependencies, err = getPoetryLockDependencies(projectDir, lockFilePath)
+	} else {
+		dependenciesGraph, rootNodeName, directDependencies, err = getPipenvLockDependencies(projectDir, lockFilePath)
+	}
+	return
+}
+
+// The direct dependencies are read from pyproject.toml, and the dependencies of each package are read from poetry.lock.
+func getPoetryLockDependencies(projectDir, lockFilePath string) (dependenciesGraph map[string][]string, rootNodeName string, directDependencies []string, err error) {
+	var pyproject pyprojectToml
+	if _, err = toml.DecodeFile(filepath.Join(projectDir, "pyproject.toml"), &pyproject); err != nil {
+		err = errorutils.CheckErrorf("failed to read pyproject.toml in %s: %s", projectDir, err.Error())
+		return
+	}
+	var lock poetryLock
+	if _, err = toml.DecodeFile(lockFilePath, &lock); err != nil {
+		err = errorutils.CheckErrorf("failed to read %s: %s", lockFilePath, err.Error())
+		return
+	}
+	// Dependencies are declared with the names the users chose, so the packages are looked up by their normalized names.
+	// The lockfile may hold several versions of a package, like when different versions are required for different Python versions.
+	packagesVersions := make(map[string][]poetryLockPackage)
+	for _, lockPackage := range lock.Package {
+		name := normalizePythonPackageName(lockPackage.Name)
+		packagesVersions[name] = append(packagesVersions[name], lockPackage)
+	}
+	getIds := func(dependencies map[string]interface{}) (ids []string) {
+		for name, constraint := range dependencies {
+			for _, lockPackage := range resolvePoetryDependency(packagesVersions[normalizePythonPackageName(name)], constraint) {
+				ids = coreutils.AppendUnique(ids, lockPackage.Name+":"+lockPackage.Version)
+			}
+		}
+		sort.Strings(ids)
+		return
+	}
+	dependenciesGraph = make(map[string][]string)
+	for _, lockPackage := range lock.Package {
+		dependenciesGraph[lockPackage.Name+":"+lockPackage.Version] = getIds(lockPackage.Dependencies)
+	}
+	// The 'python' dependency is the supported Python version, which isn't a package
+	project := pyproject.Tool.Poetry
+	directDependencies = coreutils.AppendUnique(directDependencies, getIds(project.Dependencies)...)
+	directDependencies = coreutils.AppendUnique(directDependencies, getIds(project.DevDependencies)...)
+	var groups []string
+	for group := range project.Group {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		directDependencies = coreutils.AppendUnique(directDependencies, getIds(project.Group[group].Dependencies)...)
+	}
+	rootNodeName = project.Name + ":" + project.Version
+	if project.Name == "" {
+		rootNodeName = getDefaultRootNodeName(projectDir)
+	}
+	return
+}
+
+// Returns the locked versions of a package that a dependency with the given constraint may resolve to.
+// The constraint is a version string, a table with a version, or a list of tables for different environments, like:
+// numpy = [{version = "1.21.6", python = "<3.8"}, {version = "^1.24", python = ">=3.8"}]
+// If the constraint pins some of the locked versions, only they are returned. Otherwise, all the locked versions are returned,
+// since the environment markers of the lockfile aren't evaluated.
+func resolvePoetryDependency(lockPackages []poetryLockPackage, constraint interface{}) []poetryLockPackage {
+	if len(lockPackages) < 2 {
+		return lockPackages
+	}
+	var constraints []interface{}
+	if constraintsList, isList := constraint.([]interface{}); isList {
+		constraints = constraintsList
+	} else {
+		constraints = []interface{}{constraint}
+	}
+	var pinned []poetryLockPackage
+	for _, currConstraint := range constraints {
+		if table, isTable := currConstraint.(map[string]interface{}); isTable {
+			currConstraint = table["version"]
+		}
+		version, isString := currConstraint.(string)
+		if !isString {
+			continue
+		}
+		version = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(version), "=="))
+		for _, lockPackage := range lockPackages {
+			if lockPackage.Version == version {
+				pinned = append(pinned, lockPackage)
+			}
+		}
+	}
+	if len(pinned) == 0 {
+		return lockPackages
+	}
+	return pinned
+}
+
+// Pipfile.lock doesn't record the dependencies between the packages, so all the locked packages (including the development packages)
+// are added as dependencies of the project, rather than guessing which package requires each of them.
+func getPipenvLockDependencies(projectDir, lockFilePath string) (dependenciesGraph map[string][]string, rootNodeName string, directDependencies []string, err error) {
+	var lock pipenvLock
+	if err = coreutils.ReadJsonFile(lockFilePath, &lock); err != nil {
+		return
+	}
+	dependenciesGraph = make(map[string][]string)
+	for _, packages := range []map[string]pipenvLockPackage{lock.Default, lock.Develop} {
+		var ids []string
+		for name, lockPackage := range packages {
+			version := strings.TrimPrefix(lockPackage.Version, "==")
+			if version == "" {
+				// Packages that aren't installed from an index can't be scanned
+				continue
+			}
+			ids = append(ids, name+":"+version)
+		}
+		sort.Strings(ids)
+		directDependencies = coreutils.AppendUnique(directDependencies, ids...)
+	}
+	return dependenciesGraph, getDefaultRootNodeName(projectDir), directDependencies, nil
+}
+
+// Python package names are case-insensitive, and runs of '-', '_' and '.' are equivalent (PEP 503).
+var pythonPackageNameSeparatorsRegexp = regexp.MustCompile(`[-_.]+`)
+
+func normalizePythonPackageName(name string) string {
+	return strings.ToLower(pythonPackageNameSeparatorsRegexp.ReplaceAllString(name, "-"))
+}
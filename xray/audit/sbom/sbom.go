@@ -0,0 +1,313 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	spdxDescribes    = "DESCRIBES"
+	spdxDescribedBy  = "DESCRIBED_BY"
+	spdxDependsOn    = "DEPENDS_ON"
+	spdxDependencyOf = "DEPENDENCY_OF"
+	spdxContains     = "CONTAINS"
+	spdxDocumentId   = "SPDXRef-DOCUMENT"
+	spdxPurlRefType  = "purl"
+	genericRootType  = "generic://"
+)
+
+// Maps Xray package types to the technologies they belong to.
+var packageTypeToTechnology = map[string]coreutils.Technology{
+	"gav":      coreutils.Maven,
+	"npm":      coreutils.Npm,
+	"go":       coreutils.Go,
+	"pypi":     coreutils.Pip,
+	"nuget":    coreutils.Nuget,
+	"cargo":    coreutils.Cargo,
+	"composer": coreutils.Composer,
+	"gem":      coreutils.Bundler,
+}
+
+// BuildDependencyTree reads a CycloneDX (JSON or XML) or an SPDX (JSON or tag-value) file and converts the components listed in it to a Xray dependency tree.
+// The returned technology is the one most of the components belong to.
+// Components without a package URL, or with a package URL type that isn't supported by Xray, are ignored.
+func BuildDependencyTree(sbomFilePath string) (dependencyTree []*services.GraphNode, technology coreutils.Technology, err error) {
+	content, err := os.ReadFile(sbomFilePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	var graph *sbomGraph
+	switch {
+	case isSpdxTagValue(content):
+		graph, err = parseSpdxTagValue(content)
+	case isSpdxJson(content):
+		graph, err = parseSpdxJson(content)
+	default:
+		graph, err = parseCycloneDx(content, strings.EqualFold(filepath.Ext(sbomFilePath), ".xml"))
+	}
+	if err != nil {
+		return
+	}
+	if len(graph.componentIds) == 0 {
+		err = errorutils.CheckErrorf("no components with a package URL supported by Xray were found in %s", sbomFilePath)
+		return
+	}
+	if graph.rootId == "" {
+		graph.rootId = genericRootType + filepath.Base(sbomFilePath)
+	}
+	dependencyTree = []*services.GraphNode{graph.toXrayDependencyTree()}
+	technology = graph.getTechnology()
+	return
+}
+
+// sbomGraph holds the components of an SBOM document and the dependency relationships between them, using Xray component IDs.
+type sbomGraph struct {
+	rootId string
+	// Maps the SBOM reference of each component (bom-ref or SPDXID) to its Xray component ID.
+	componentIds map[string]string
+	// Maps the SBOM reference of each component to the references of its dependencies.
+	dependencies map[string][]string
+}
+
+func newSbomGraph() *sbomGraph {
+	return &sbomGraph{componentIds: make(map[string]string), dependencies: make(map[string][]string)}
+}
+
+func (sg *sbomGraph) addComponent(ref, purl string) {
+	componentId := xrutils.PurlToComponentId(purl)
+	if componentId == "" {
+		log.Debug("Skipping SBOM component", ref, "with an unsupported package URL:", purl)
+		return
+	}
+	sg.componentIds[ref] = componentId
+}
+
+func (sg *sbomGraph) addDependency(parentRef, childRef string) {
+	sg.dependencies[parentRef] = append(sg.dependencies[parentRef], childRef)
+}
+
+// Converts the SBOM graph to a Xray dependency tree.
+// Components that aren't reachable from the root are added as direct dependencies of the root, so that all the SBOM components are scanned.
+func (sg *sbomGraph) toXrayDependencyTree() *services.GraphNode {
+	treeMap := make(map[string][]string)
+	for parentRef, childrenRefs := range sg.dependencies {
+		parentId := sg.getXrayId(parentRef)
+		for _, childRef := range childrenRefs {
+			if childId, exist := sg.componentIds[childRef]; exist && childId != parentId && !slices.Contains(treeMap[parentId], childId) {
+				treeMap[parentId] = append(treeMap[parentId], childId)
+			}
+		}
+	}
+	visited := make(map[string]bool)
+	markReachable(sg.rootId, treeMap, visited)
+	for _, componentId := range sg.sortedComponentIds() {
+		if !visited[componentId] {
+			treeMap[sg.rootId] = append(treeMap[sg.rootId], componentId)
+			markReachable(componentId, treeMap, visited)
+		}
+	}
+	return audit.BuildXrayDependencyTree(treeMap, sg.rootId)
+}
+
+func markReachable(componentId string, treeMap map[string][]string, visited map[string]bool) {
+	queue := []string{componentId}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		queue = append(queue, treeMap[current]...)
+	}
+}
+
+// The root reference may not have a supported package URL, and is therefore mapped to the root ID.
+func (sg *sbomGraph) getXrayId(ref string) string {
+	if componentId, exist := sg.componentIds[ref]; exist {
+		return componentId
+	}
+	return sg.rootId
+}
+
+func (sg *sbomGraph) sortedComponentIds() (componentIds []string) {
+	added := make(map[string]bool)
+	for _, componentId := range sg.componentIds {
+		if !added[componentId] {
+			componentIds = append(componentIds, componentId)
+			added[componentId] = true
+		}
+	}
+	sort.Strings(componentIds)
+	return
+}
+
+func (sg *sbomGraph) getTechnology() (technology coreutils.Technology) {
+	techCount := make(map[coreutils.Technology]int)
+	for _, componentId := range sg.componentIds {
+		if tech, exist := packageTypeToTechnology[strings.Split(componentId, "://")[0]]; exist {
+			techCount[tech]++
+		}
+	}
+	for tech, count := range techCount {
+		if count > techCount[technology] || (count == techCount[technology] && tech < technology) {
+			technology = tech
+		}
+	}
+	return
+}
+
+func parseCycloneDx(content []byte, isXml bool) (*sbomGraph, error) {
+	format := cdx.BOMFileFormatJSON
+	if isXml {
+		format = cdx.BOMFileFormatXML
+	}
+	bom := cdx.BOM{}
+	if err := cdx.NewBOMDecoder(bytes.NewReader(content), format).Decode(&bom); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the CycloneDX file: %s", err.Error())
+	}
+	graph := newSbomGraph()
+	rootRef := ""
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		root := bom.Metadata.Component
+		rootRef = root.BOMRef
+		graph.rootId = xrutils.PurlToComponentId(root.PackageURL)
+		if graph.rootId == "" {
+			graph.rootId = genericRootType + root.Name
+			if root.Version != "" {
+				graph.rootId += ":" + root.Version
+			}
+		}
+	}
+	if bom.Components != nil {
+		addCycloneDxComponents(graph, *bom.Components)
+	}
+	if bom.Dependencies != nil {
+		for _, dependency := range *bom.Dependencies {
+			if dependency.Dependencies == nil {
+				continue
+			}
+			for _, childRef := range *dependency.Dependencies {
+				graph.addDependency(dependency.Ref, childRef)
+			}
+		}
+	}
+	// The root component should not be scanned as one of its own dependencies.
+	delete(graph.componentIds, rootRef)
+	return graph, nil
+}
+
+// CycloneDX components may be nested in other components.
+func addCycloneDxComponents(graph *sbomGraph, components []cdx.Component) {
+	for _, component := range components {
+		ref := component.BOMRef
+		if ref == "" {
+			ref = component.PackageURL
+		}
+		graph.addComponent(ref, component.PackageURL)
+		if component.Components != nil {
+			addCycloneDxComponents(graph, *component.Components)
+		}
+	}
+}
+
+func isSpdxJson(content []byte) bool {
+	return bytes.Contains(content, []byte(`"spdxVersion"`))
+}
+
+func isSpdxTagValue(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("SPDXVersion:"))
+}
+
+func parseSpdxJson(content []byte) (*sbomGraph, error) {
+	document := xrutils.SpdxDocument{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the SPDX file: %s", err.Error())
+	}
+	return convertSpdxDocument(&document), nil
+}
+
+// Parses the packages and relationships of an SPDX tag-value document. Other tags are ignored.
+func parseSpdxTagValue(content []byte) (*sbomGraph, error) {
+	document := xrutils.SpdxDocument{}
+	var currentPackage *xrutils.SpdxPackage
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		tag, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(tag) {
+		case "PackageName":
+			document.Packages = append(document.Packages, xrutils.SpdxPackage{Name: value})
+			currentPackage = &document.Packages[len(document.Packages)-1]
+		case "SPDXID":
+			if currentPackage != nil {
+				currentPackage.SpdxId = value
+			}
+		case "ExternalRef":
+			if fields := strings.Fields(value); currentPackage != nil && len(fields) == 3 {
+				currentPackage.ExternalRefs = append(currentPackage.ExternalRefs, xrutils.SpdxExternalRef{ReferenceCategory: fields[0], ReferenceType: fields[1], ReferenceLocator: fields[2]})
+			}
+		case "Relationship":
+			if fields := strings.Fields(value); len(fields) == 3 {
+				document.Relationships = append(document.Relationships, xrutils.SpdxRelationship{SpdxElementId: fields[0], RelationshipType: fields[1], RelatedSpdxElement: fields[2]})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the SPDX file: %s", err.Error())
+	}
+	return convertSpdxDocument(&document), nil
+}
+
+func convertSpdxDocument(document *xrutils.SpdxDocument) *sbomGraph {
+	graph := newSbomGraph()
+	for _, spdxPackage := range document.Packages {
+		for _, externalRef := range spdxPackage.ExternalRefs {
+			if externalRef.ReferenceType == spdxPurlRefType {
+				graph.addComponent(spdxPackage.SpdxId, externalRef.ReferenceLocator)
+				break
+			}
+		}
+	}
+	var rootRef string
+	for _, relationship := range document.Relationships {
+		switch relationship.RelationshipType {
+		case spdxDescribes:
+			if relationship.SpdxElementId == spdxDocumentId && rootRef == "" {
+				rootRef = relationship.RelatedSpdxElement
+			}
+		case spdxDescribedBy:
+			if relationship.RelatedSpdxElement == spdxDocumentId && rootRef == "" {
+				rootRef = relationship.SpdxElementId
+			}
+		case spdxDependsOn, spdxContains:
+			graph.addDependency(relationship.SpdxElementId, relationship.RelatedSpdxElement)
+		case spdxDependencyOf:
+			graph.addDependency(relationship.RelatedSpdxElement, relationship.SpdxElementId)
+		}
+	}
+	if rootId, exist := graph.componentIds[rootRef]; exist {
+		graph.rootId = rootId
+		delete(graph.componentIds, rootRef)
+	} else if rootRef != "" {
+		graph.rootId = genericRootType + strings.TrimPrefix(rootRef, "SPDXRef-")
+	}
+	return graph
+}
@@ -0,0 +1,67 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/tests"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDependencyTree(t *testing.T) {
+	// Create and change directory to test workspace
+	_, cleanUp := audit.CreateTestWorkspace(t, "sbom")
+	defer cleanUp()
+
+	testCases := []struct {
+		sbomFile     string
+		expectedTech coreutils.Technology
+		expectedTree *services.GraphNode
+	}{
+		{
+			sbomFile:     "cyclonedx.json",
+			expectedTech: coreutils.Npm,
+			expectedTree: &services.GraphNode{
+				Id: "npm://vendor-app:2.0.0",
+				Nodes: []*services.GraphNode{
+					{Id: "npm://express:4.17.1", Nodes: []*services.GraphNode{{Id: "npm://debug:2.6.9"}}},
+					{Id: "npm://@jfrog/npm_scoped:1.0.0"},
+				},
+			},
+		},
+		{
+			sbomFile:     "spdx.json",
+			expectedTech: coreutils.Maven,
+			expectedTree: &services.GraphNode{
+				Id: "gav://com.vendor:vendor-service:1.0.0",
+				Nodes: []*services.GraphNode{
+					{Id: "gav://junit:junit:4.13.2", Nodes: []*services.GraphNode{{Id: "gav://org.hamcrest:hamcrest-core:1.3"}}},
+				},
+			},
+		},
+		{
+			sbomFile:     "spdx.spdx",
+			expectedTech: coreutils.Go,
+			expectedTree: &services.GraphNode{
+				Id: "go://github.com/vendor/service:v1.0.0",
+				Nodes: []*services.GraphNode{
+					{Id: "go://github.com/pkg/errors:v0.9.1"},
+					{Id: "go://golang.org/x/text:v0.3.8"},
+				},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.sbomFile, func(t *testing.T) {
+			dependencyTrees, tech, err := BuildDependencyTree(testCase.sbomFile)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedTech, tech)
+			if assert.Len(t, dependencyTrees, 1) {
+				assert.Len(t, dependencyTrees[0].Nodes, len(testCase.expectedTree.Nodes))
+				assert.True(t, tests.CompareTree(testCase.expectedTree, dependencyTrees[0]), "expected:", testCase.expectedTree.Nodes, "got:", dependencyTrees[0].Nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+// The directory of the graph scan results in the JFrog CLI cache directory.
+const graphScanCacheDirName = "xray-graph-scan"
+
+// ScanCache is a local cache of Xray graph scan results.
+// The results are stored under the JFrog CLI cache directory, in a file named by the hash of the scanned dependency tree and of the scan parameters.
+// Since the content of Xray's database and policies may change, the cached results expire after a configurable TTL.
+type ScanCache struct {
+	dir string
+	ttl time.Duration
+	// If true, the cached results are ignored, but the results of new scans are still cached.
+	noCache bool
+}
+
+type scanCacheEntry struct {
+	CreatedAt time.Time             `json:"createdAt"`
+	Result    services.ScanResponse `json:"result"`
+}
+
+// NewScanCache creates a cache of graph scan results whose entries expire after ttl.
+// Returns nil if ttl isn't positive, which means the scan results shouldn't be cached.
+// If noCache is true, the cached results are bypassed and overwritten by the results of new scans.
+func NewScanCache(ttl time.Duration, noCache bool) (*ScanCache, error) {
+	if ttl <= 0 {
+		return nil, nil
+	}
+	cacheDir, err := coreutils.GetJfrogCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return newScanCacheInDir(filepath.Join(cacheDir, graphScanCacheDirName), ttl, noCache)
+}
+
+func newScanCacheInDir(dir string, ttl time.Duration, noCache bool) (*ScanCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	return &ScanCache{dir: dir, ttl: ttl, noCache: noCache}, nil
+}
+
+// Get returns the cached results of a scan with the given parameters (including the scanned graph), or nil if there are no valid cached results.
+// Errors are not returned, since a corrupted or unreadable entry only means that the graph should be scanned again.
+func (sc *ScanCache) Get(params services.XrayGraphScanParams, serverDetails *config.ServerDetails) *services.ScanResponse {
+	if sc == nil || sc.noCache {
+		return nil
+	}
+	entryPath := sc.getEntryPath(params, serverDetails)
+	content, err := os.ReadFile(entryPath)
+	if err != nil {
+		return nil
+	}
+	var entry scanCacheEntry
+	if err = json.Unmarshal(content, &entry); err != nil {
+		log.Debug("Ignoring the corrupted cached scan results in " + entryPath + ": " + err.Error())
+		return nil
+	}
+	if time.Since(entry.CreatedAt) > sc.ttl {
+		// Expired entries are removed, so that the cache doesn't grow with results that will never be used
+		_ = os.Remove(entryPath)
+		return nil
+	}
+	return &entry.Result
+}
+
+// Put caches the results of a scan with the given parameters (including the scanned graph).
+func (sc *ScanCache) Put(params services.XrayGraphScanParams, serverDetails *config.ServerDetails, result *services.ScanResponse) error {
+	if sc == nil {
+		return nil
+	}
+	content, err := json.Marshal(scanCacheEntry{CreatedAt: time.Now(), Result: *result})
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	// The entry is written to a temporary file first, so that concurrent audits never read a partially written entry
+	tempFile, err := os.CreateTemp(sc.dir, "entry-*.tmp")
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+	if _, err = tempFile.Write(content); err != nil {
+		_ = tempFile.Close()
+		return errorutils.CheckError(err)
+	}
+	if err = tempFile.Close(); err != nil {
+		return errorutils.CheckError(err)
+	}
+	return errorutils.CheckError(os.Rename(tempFile.Name(), sc.getEntryPath(params, serverDetails)))
+}
+
+func (sc *ScanCache) getEntryPath(params services.XrayGraphScanParams, serverDetails *config.ServerDetails) string {
+	return filepath.Join(sc.dir, getScanCacheKey(params, serverDetails)+".json")
+}
+
+// The key of the cached results is the hash of the scanned graph, the scan parameters and the Xray server and user.
+// The results of the same graph may differ between projects, watches and users, since they determine the policies that are applied.
+func getScanCacheKey(params services.XrayGraphScanParams, serverDetails *config.ServerDetails) string {
+	watches := append([]string{}, params.Watches...)
+	sort.Strings(watches)
+	keyParts := []string{
+		getGraphHash(params.Graph),
+		params.RepoPath,
+		params.ProjectKey,
+		strings.Join(watches, ","),
+		string(params.ScanType),
+		strconv.FormatBool(params.IncludeVulnerabilities),
+		strconv.FormatBool(params.IncludeLicenses),
+	}
+	if serverDetails != nil {
+		keyParts = append(keyParts, serverDetails.XrayUrl, serverDetails.User)
+	}
+	return hashStrings(keyParts)
+}
+
+// Returns a hash of the graph, which doesn't depend on the order of the nodes' children.
+func getGraphHash(node *services.GraphNode) string {
+	if node == nil {
+		return ""
+	}
+	licenses := append([]string{}, node.Licenses...)
+	sort.Strings(licenses)
+	var properties []string
+	for key, value := range node.Properties {
+		properties = append(properties, key+"="+value)
+	}
+	sort.Strings(properties)
+	var childrenHashes []string
+	for _, child := range node.Nodes {
+		childrenHashes = append(childrenHashes, getGraphHash(child))
+	}
+	sort.Strings(childrenHashes)
+	return hashStrings([]string{node.Id, node.Sha256, node.Sha1, node.Path, strings.Join(licenses, ","), strings.Join(properties, ","), strings.Join(childrenHashes, ",")})
+}
+
+// Each string is written as a quoted JSON string, so that different lists of strings never have the same hash.
+func hashStrings(values []string) string {
+	hash := sha256.New()
+	for _, value := range values {
+		_ = json.NewEncoder(hash).Encode(value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
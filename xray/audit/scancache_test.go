@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestGraph(childrenIds ...string) *services.GraphNode {
+	root := &services.GraphNode{Id: "npm://root:1.0.0"}
+	for _, childId := range childrenIds {
+		root.Nodes = append(root.Nodes, &services.GraphNode{Id: childId})
+	}
+	return root
+}
+
+func TestGetScanCacheKey(t *testing.T) {
+	server := &config.ServerDetails{XrayUrl: "https://xray.example.com/xray/", User: "user"}
+	params := services.XrayGraphScanParams{Graph: createTestGraph("npm://a:1.0.0", "npm://b:2.0.0"), Watches: []string{"watch1", "watch2"}, ScanType: services.Dependency}
+	key := getScanCacheKey(params, server)
+
+	// The order of the children and of the watches doesn't matter
+	reordered := params
+	reordered.Graph = createTestGraph("npm://b:2.0.0", "npm://a:1.0.0")
+	reordered.Watches = []string{"watch2", "watch1"}
+	assert.Equal(t, key, getScanCacheKey(reordered, server))
+
+	changedGraph := params
+	changedGraph.Graph = createTestGraph("npm://a:1.0.1", "npm://b:2.0.0")
+	assert.NotEqual(t, key, getScanCacheKey(changedGraph, server))
+
+	changedWatches := params
+	changedWatches.Watches = []string{"watch1"}
+	assert.NotEqual(t, key, getScanCacheKey(changedWatches, server))
+
+	changedProject := params
+	changedProject.ProjectKey = "project"
+	assert.NotEqual(t, key, getScanCacheKey(changedProject, server))
+
+	changedRepoPath := params
+	changedRepoPath.RepoPath = "repo/path"
+	assert.NotEqual(t, key, getScanCacheKey(changedRepoPath, server))
+
+	assert.NotEqual(t, key, getScanCacheKey(params, &config.ServerDetails{XrayUrl: server.XrayUrl, User: "other"}))
+}
+
+func TestScanCache(t *testing.T) {
+	server := &config.ServerDetails{XrayUrl: "https://xray.example.com/xray/"}
+	params := services.XrayGraphScanParams{Graph: createTestGraph("npm://a:1.0.0"), IncludeVulnerabilities: true}
+	result := &services.ScanResponse{ScanId: "scan-id", Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-1", Severity: "High"}}}
+	cacheDir := t.TempDir()
+
+	scanCache, err := newScanCacheInDir(cacheDir, time.Hour, false)
+	assert.NoError(t, err)
+	assert.Nil(t, scanCache.Get(params, server))
+	assert.NoError(t, scanCache.Put(params, server, result))
+	cachedResult := scanCache.Get(params, server)
+	if assert.NotNil(t, cachedResult) {
+		assert.Equal(t, *result, *cachedResult)
+	}
+	otherParams := params
+	otherParams.Graph = createTestGraph("npm://a:2.0.0")
+	assert.Nil(t, scanCache.Get(otherParams, server))
+
+	// No temporary files are left in the cache
+	entries, err := os.ReadDir(cacheDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// The cached results are ignored, but are still updated
+	bypassingCache, err := newScanCacheInDir(cacheDir, time.Hour, true)
+	assert.NoError(t, err)
+	assert.Nil(t, bypassingCache.Get(params, server))
+	result.ScanId = "new-scan-id"
+	assert.NoError(t, bypassingCache.Put(params, server, result))
+	cachedResult = scanCache.Get(params, server)
+	if assert.NotNil(t, cachedResult) {
+		assert.Equal(t, "new-scan-id", cachedResult.ScanId)
+	}
+
+	// Expired entries are removed
+	expiringCache, err := newScanCacheInDir(cacheDir, time.Nanosecond, false)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	assert.Nil(t, expiringCache.Get(params, server))
+	entries, err = os.ReadDir(cacheDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Corrupted entries are ignored
+	assert.NoError(t, os.WriteFile(scanCache.getEntryPath(params, server), []byte("{"), 0600))
+	assert.Nil(t, scanCache.Get(params, server))
+}
+
+func TestNewScanCache(t *testing.T) {
+	// A zero TTL disables the cache
+	scanCache, err := NewScanCache(0, false)
+	assert.NoError(t, err)
+	assert.Nil(t, scanCache)
+	assert.Nil(t, scanCache.Get(services.XrayGraphScanParams{}, nil))
+	assert.NoError(t, scanCache.Put(services.XrayGraphScanParams{}, nil, &services.ScanResponse{}))
+
+	homeDir := t.TempDir()
+	t.Setenv("JFROG_CLI_HOME_DIR", homeDir)
+	scanCache, err = NewScanCache(time.Hour, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, scanCache) {
+		assert.Equal(t, filepath.Join(homeDir, "cache", graphScanCacheDirName), scanCache.dir)
+	}
+}
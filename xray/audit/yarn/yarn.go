@@ -2,7 +2,6 @@ package yarn
 
 import (
 	biutils "github.com/jfrog/build-info-go/build/utils"
-	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
 	"github.com/jfrog/jfrog-client-go/utils/errorutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
@@ -13,21 +12,17 @@ const (
 	npmPackageTypeIdentifier = "npm://"
 )
 
-func BuildDependencyTree() (dependencyTree []*services.GraphNode, err error) {
-	currentDir, err := coreutils.GetWorkingDirectory()
-	if err != nil {
-		return
-	}
+func BuildDependencyTree(projectDir string) (dependencyTree []*services.GraphNode, err error) {
 	executablePath, err := biutils.GetYarnExecutable()
 	if errorutils.CheckError(err) != nil {
 		return
 	}
-	packageInfo, err := biutils.ReadPackageInfoFromPackageJson(currentDir, nil)
+	packageInfo, err := biutils.ReadPackageInfoFromPackageJson(projectDir, nil)
 	if errorutils.CheckError(err) != nil {
 		return
 	}
 	// Calculate Yarn dependencies
-	dependenciesMap, _, err := biutils.GetYarnDependencies(executablePath, currentDir, packageInfo, log.Logger)
+	dependenciesMap, _, err := biutils.GetYarnDependencies(executablePath, projectDir, packageInfo, log.Logger)
 	if err != nil {
 		return
 	}
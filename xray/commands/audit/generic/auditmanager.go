@@ -6,24 +6,58 @@ import (
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/jfrog/build-info-go/utils/pythonutils"
+	"github.com/jfrog/gofrog/parallel"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/bundler"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/cargo"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/composer"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/dotnet"
 	_go "github.com/jfrog/jfrog-cli-core/v2/xray/audit/go"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/java"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/npm"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/nuget"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/pnpm"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/python"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/sbom"
 	"github.com/jfrog/jfrog-cli-core/v2/xray/audit/yarn"
+	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
 	"github.com/jfrog/jfrog-client-go/utils/errorutils"
 	ioUtils "github.com/jfrog/jfrog-client-go/utils/io"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray/services"
 )
 
-// GenericAudit audits all the projects found in the given workingDirs
+type Results struct {
+	// The Xray scan results of all the audited projects.
+	ScanResults []services.ScanResponse
+	// The dependency trees that were sent to Xray, one for each scanned module.
+	DependencyTrees []*services.GraphNode
+	// The path of the descriptor (or the SBOM file) each of the DependencyTrees was built from, by index.
+	DependencyTreesPaths []string
+	// The path of the descriptor of the audited project (or the SBOM file) each of the ScanResults was created from, by index.
+	// If the descriptor of a project can't be determined, the project's directory is used.
+	ScannedPaths          []string
+	IsMultipleRootProject bool
+}
+
+// AuditOptions holds the optional parameters of GenericAudit and SbomAudit.
+type AuditOptions struct {
+	// If true, the dependency trees are built but aren't scanned, so Xray isn't contacted.
+	DependencyTreesOnly bool
+	// If not nil, dependency trees that were already scanned with the same parameters aren't scanned again (see audit.ScanCache).
+	ScanCache *audit.ScanCache
+}
+
+// GenericAudit audits all the projects found in the given workingDirs. If no workingDirs are given, the project in the current directory is audited.
+// If recursive is true, all the projects found in the workingDirs and in their subdirectories are audited, except for those in directories matching the exclusions (see coreutils.DetectProjects).
+// Each of the technologies used by each of the projects is audited as a separate task, and up to 'threads' tasks run concurrently.
+// The working directory of the process is never changed, so it is safe to call this function from a long-running process.
 func GenericAudit(
 	xrayGraphScanParams services.XrayGraphScanParams,
 	serverDetails *config.ServerDetails,
@@ -35,23 +69,21 @@ func GenericAudit(
 	requirementsFile string,
 	ignoreConfigFile bool,
 	workingDirs []string,
-	technologies ...string) (results []services.ScanResponse, isMultipleRoot bool, err error) {
+	recursive bool,
+	exclusions []string,
+	threads int,
+	options AuditOptions,
+	technologies ...string) (results *Results, err error) {
 
 	if len(workingDirs) == 0 {
-		log.Info("Auditing project: ")
-		return doAudit(xrayGraphScanParams, serverDetails, excludeTestDeps, useWrapper, insecureTls, args, progress, requirementsFile, ignoreConfigFile, technologies...)
-	}
-	projectDir, err := os.Getwd()
-	if errorutils.CheckError(err) != nil {
-		return
+		wd, e := os.Getwd()
+		if errorutils.CheckError(e) != nil {
+			return nil, e
+		}
+		workingDirs = []string{wd}
 	}
 	var errorList []string
-	defer func() {
-		e := os.Chdir(projectDir)
-		if err == nil {
-			err = e
-		}
-	}()
+	var tasks []*auditTask
 	for _, wd := range workingDirs {
 		absWd, e := filepath.Abs(wd)
 		if e != nil {
@@ -59,21 +91,60 @@ func GenericAudit(
 			errorList = append(errorList, fmt.Sprintf("the audit command couldn't find the following path: %s\n%s", wd, e.Error()))
 			continue
 		}
-		log.Info("Auditing project: " + absWd)
-		e = os.Chdir(absWd)
-		if e != nil {
-			// Save the error but continue to the other paths
-			errorList = append(errorList, fmt.Sprintf("the audit command couldn't change the current working directory to the following path: %s\n%s", absWd, e.Error()))
-			continue
+		var wdTasks []*auditTask
+		if recursive {
+			wdTasks, e = createRecursiveAuditTasks(absWd, exclusions, technologies)
+		} else {
+			log.Info("Auditing project: " + absWd)
+			wdTasks, e = createAuditTasks(absWd, technologies)
 		}
-		techResults, isMultipleRootProject, e := doAudit(xrayGraphScanParams, serverDetails, excludeTestDeps, useWrapper, insecureTls, args, progress, requirementsFile, ignoreConfigFile, technologies...)
 		if e != nil {
 			// Save the error but continue to the other paths
 			errorList = append(errorList, fmt.Sprintf("audit command in %s failed:\n%s", absWd, e.Error()))
-		} else {
-			results = append(results, techResults...)
-			isMultipleRoot = isMultipleRootProject
+			continue
+		}
+		tasks = append(tasks, wdTasks...)
+	}
+
+	if threads < 1 {
+		// The runner doesn't start any worker without threads
+		threads = 1
+	}
+	if progress != nil {
+		// The tasks update the headline concurrently
+		progress = &syncHeadlineProgressMgr{ProgressMgr: progress}
+	}
+	runner := parallel.NewRunner(threads, uint(len(tasks)), false)
+	for _, task := range tasks {
+		currentTask := task
+		_, _ = runner.AddTask(func(int) error {
+			if progress != nil {
+				progress.SetHeadlineMsg(fmt.Sprintf("Calculating %v dependencies", currentTask.tech.ToFormal()))
+			}
+			currentTask.dependencyTrees, currentTask.err = buildDependencyTree(currentTask.projectDir, currentTask.tech, excludeTestDeps, useWrapper, insecureTls, args, requirementsFile, ignoreConfigFile)
+			if currentTask.err == nil && !options.DependencyTreesOnly {
+				// If building the dependency tree was successful, run Xray scan.
+				currentTask.results, currentTask.err = audit.Audit(currentTask.dependencyTrees, xrayGraphScanParams, serverDetails, progress, currentTask.tech, audit.ScanOptions{ScanCache: options.ScanCache})
+			}
+			return nil
+		})
+	}
+	runner.Done()
+	runner.Run()
+
+	// The results are collected in the order of the tasks, so that they don't depend on the order in which the tasks finished.
+	results = &Results{}
+	for _, task := range tasks {
+		if task.err != nil {
+			// Save the error but continue to collect the results of the other tasks
+			errorList = append(errorList, fmt.Sprintf("'%s' audit command in %s failed:\n%s", task.tech, task.descriptorPath, task.err.Error()))
+			continue
 		}
+		results.ScanResults = append(results.ScanResults, task.results...)
+		results.ScannedPaths = append(results.ScannedPaths, xrutils.RepeatString(task.descriptorPath, len(task.results))...)
+		results.DependencyTrees = append(results.DependencyTrees, task.dependencyTrees...)
+		results.DependencyTreesPaths = append(results.DependencyTreesPaths, xrutils.RepeatString(task.descriptorPath, len(task.dependencyTrees))...)
+		results.IsMultipleRootProject = results.IsMultipleRootProject || len(task.dependencyTrees) > 1
 	}
 	if len(errorList) > 0 {
 		err = errors.New(strings.Join(errorList, "\n"))
@@ -81,81 +152,200 @@ func GenericAudit(
 	return
 }
 
-//  Audits the project found in the current directory using Xray.
-func doAudit(
-	xrayGraphScanParams services.XrayGraphScanParams,
-	serverDetails *config.ServerDetails,
-	excludeTestDeps,
-	useWrapper,
-	insecureTls bool,
-	args []string,
-	progress ioUtils.ProgressMgr,
-	requirementsFile string,
-	ignoreConfigFile bool,
-	technologies ...string) (results []services.ScanResponse, isMultipleRoot bool, err error) {
+// Audits a single technology used by the project in projectDir.
+type auditTask struct {
+	projectDir string
+	tech       coreutils.Technology
+	// The descriptor file of the technology in the project's directory, like its package.json or pom.xml.
+	descriptorPath  string
+	dependencyTrees []*services.GraphNode
+	results         []services.ScanResponse
+	err             error
+}
 
+func newAuditTask(projectDir string, tech coreutils.Technology, descriptorPath string) *auditTask {
+	return &auditTask{projectDir: projectDir, tech: tech, descriptorPath: descriptorPath}
+}
+
+// Creates a task for each of the given technologies used by the project in projectDir.
+// If no technologies are given, the technologies used by the project are detected.
+func createAuditTasks(projectDir string, technologies []string) (tasks []*auditTask, err error) {
 	// If no technologies were given, try to detect all types of technologies used.
 	// Otherwise, run audit for requested technologies only.
 	if len(technologies) == 0 {
-		technologies, err = detectedTechnologies()
-		if err != nil {
+		if technologies, err = detectedTechnologies(projectDir); err != nil {
 			return
 		}
 	}
-	var errorList []string
-	for _, tech := range coreutils.ToTechnologies(technologies) {
-		var dependencyTrees []*services.GraphNode
-		var e error
-		if progress != nil {
-			progress.SetHeadlineMsg(fmt.Sprintf("Calculating %v dependencies", tech.ToFormal()))
-		}
-		switch tech {
-		case coreutils.Maven:
-			dependencyTrees, e = java.BuildMvnDependencyTree(insecureTls, ignoreConfigFile)
-		case coreutils.Gradle:
-			dependencyTrees, e = java.BuildGradleDependencyTree(excludeTestDeps, useWrapper, ignoreConfigFile)
-		case coreutils.Npm:
-			dependencyTrees, e = npm.BuildDependencyTree(args)
-		case coreutils.Yarn:
-			dependencyTrees, e = yarn.BuildDependencyTree()
-		case coreutils.Go:
-			dependencyTrees, e = _go.BuildDependencyTree()
-		case coreutils.Pipenv, coreutils.Pip, coreutils.Poetry:
-			dependencyTrees, e = python.BuildDependencyTree(pythonutils.PythonTool(tech), requirementsFile)
-		case coreutils.Dotnet:
+	techs := coreutils.ToTechnologies(technologies)
+	skippedTech, err := getSkippedDotnetTechnology(projectDir, techs)
+	if err != nil {
+		return
+	}
+	for _, tech := range techs {
+		if tech == skippedTech {
 			continue
-		case coreutils.Nuget:
-			dependencyTrees, e = nuget.BuildDependencyTree()
-		default:
-			e = errors.New(string(tech) + " is currently not supported")
 		}
+		tasks = append(tasks, newAuditTask(projectDir, tech, coreutils.GetDescriptorPath(projectDir, tech)))
+	}
+	return
+}
 
-		var techResults []services.ScanResponse
-		if e == nil {
-			// If building the dependency tree was successful, run Xray scan.
-			techResults, e = audit.Audit(dependencyTrees, xrayGraphScanParams, serverDetails, progress, tech)
+// Creates a task for each of the technologies used by each of the projects found in rootDir and in its subdirectories.
+// If technologies are given, projects of other technologies are ignored.
+func createRecursiveAuditTasks(rootDir string, exclusions, technologies []string) (tasks []*auditTask, err error) {
+	projects, err := coreutils.DetectProjects(rootDir, exclusions)
+	if err != nil {
+		return
+	}
+	requestedTechnologies := make(map[coreutils.Technology]bool)
+	for _, tech := range coreutils.ToTechnologies(technologies) {
+		requestedTechnologies[tech] = true
+	}
+	for _, project := range projects {
+		var projectTechs []coreutils.Technology
+		for tech := range project.Descriptors {
+			if len(requestedTechnologies) == 0 || requestedTechnologies[tech] {
+				projectTechs = append(projectTechs, tech)
+			}
 		}
-
-		if e != nil {
-			// Save the error but continue to audit the next tech
-			errorList = append(errorList, fmt.Sprintf("'%s' audit command failed:\n%s", tech, e.Error()))
-		} else {
-			results = append(results, techResults...)
-			isMultipleRoot = len(dependencyTrees) > 1
+		var skippedTech coreutils.Technology
+		if skippedTech, err = getSkippedDotnetTechnology(project.Dir, projectTechs); err != nil {
+			return
+		}
+		for _, tech := range projectTechs {
+			if tech == skippedTech {
+				continue
+			}
+			descriptorPath := project.Descriptors[tech]
+			log.Info(fmt.Sprintf("Found %s project: %s", tech.ToFormal(), descriptorPath))
+			tasks = append(tasks, newAuditTask(project.Dir, tech, descriptorPath))
 		}
 	}
-	if len(errorList) > 0 {
-		err = errors.New(strings.Join(errorList, "\n"))
+	if len(tasks) == 0 {
+		return nil, errorutils.CheckErrorf("could not find any project to audit in %s or in its subdirectories.", rootDir)
 	}
+	// Technologies are iterated in a random order, so the tasks are sorted to keep the output stable.
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].descriptorPath < tasks[j].descriptorPath
+	})
 	return
 }
 
-func detectedTechnologies() (technologies []string, err error) {
-	wd, err := os.Getwd()
+// Dotnet and NuGet share their indicators, so both are detected in the same projects, but only one of them should be audited.
+// If some of the projects were restored by the dotnet CLI, all of the projects are audited with dotnet.BuildDependencyTree, which audits the
+// projects that weren't restored from the packages they declare. Otherwise, the projects are audited with NuGet.
+// Returns the technology that shouldn't be audited, or an empty string if techs don't include both.
+func getSkippedDotnetTechnology(projectDir string, techs []coreutils.Technology) (coreutils.Technology, error) {
+	var hasDotnet, hasNuget bool
+	for _, tech := range techs {
+		hasDotnet = hasDotnet || tech == coreutils.Dotnet
+		hasNuget = hasNuget || tech == coreutils.Nuget
+	}
+	if !hasDotnet || !hasNuget {
+		return "", nil
+	}
+	restored, err := dotnet.HasRestoreFiles(projectDir)
+	if err != nil {
+		return "", err
+	}
+	if restored {
+		return coreutils.Nuget, nil
+	}
+	return coreutils.Dotnet, nil
+}
+
+// SbomAudit audits the components listed in the given CycloneDX or SPDX file.
+// No package manager or build tool is executed.
+func SbomAudit(xrayGraphScanParams services.XrayGraphScanParams, serverDetails *config.ServerDetails, progress ioUtils.ProgressMgr, sbomFile string, options AuditOptions) (results *Results, err error) {
+	log.Info("Auditing SBOM file: " + sbomFile)
+	if progress != nil {
+		progress.SetHeadlineMsg("Reading SBOM file")
+	}
+	dependencyTrees, tech, err := sbom.BuildDependencyTree(sbomFile)
+	if err != nil {
+		return
+	}
+	var scanResults []services.ScanResponse
+	if !options.DependencyTreesOnly {
+		if scanResults, err = audit.Audit(dependencyTrees, xrayGraphScanParams, serverDetails, progress, tech, audit.ScanOptions{ScanCache: options.ScanCache}); err != nil {
+			return
+		}
+	}
+	sbomPath, err := filepath.Abs(sbomFile)
 	if errorutils.CheckError(err) != nil {
 		return
 	}
-	detectedTechnologies, err := coreutils.DetectTechnologies(wd, false, false)
+	return &Results{
+		ScanResults:          scanResults,
+		DependencyTrees:      dependencyTrees,
+		DependencyTreesPaths: xrutils.RepeatString(sbomPath, len(dependencyTrees)),
+		ScannedPaths:         xrutils.RepeatString(sbomPath, len(scanResults)),
+	}, nil
+}
+
+// Builds the dependency trees of the project in projectDir, using the package manager or build tool of the given technology.
+func buildDependencyTree(
+	projectDir string,
+	tech coreutils.Technology,
+	excludeTestDeps,
+	useWrapper,
+	insecureTls bool,
+	args []string,
+	requirementsFile string,
+	ignoreConfigFile bool) (dependencyTrees []*services.GraphNode, err error) {
+
+	switch tech {
+	case coreutils.Maven:
+		dependencyTrees, err = java.BuildMvnDependencyTree(projectDir, insecureTls, ignoreConfigFile)
+	case coreutils.Gradle:
+		dependencyTrees, err = java.BuildGradleDependencyTree(projectDir, excludeTestDeps, useWrapper, ignoreConfigFile)
+	case coreutils.Npm:
+		dependencyTrees, err = npm.BuildDependencyTree(projectDir, args)
+	case coreutils.Yarn:
+		dependencyTrees, err = yarn.BuildDependencyTree(projectDir)
+	case coreutils.Pnpm:
+		dependencyTrees, err = pnpm.BuildDependencyTree(projectDir, args)
+	case coreutils.Go:
+		dependencyTrees, err = _go.BuildDependencyTree(projectDir)
+	case coreutils.Pipenv, coreutils.Pip, coreutils.Poetry:
+		dependencyTrees, err = python.BuildDependencyTree(projectDir, pythonutils.PythonTool(tech), requirementsFile)
+	case coreutils.Nuget:
+		dependencyTrees, err = nuget.BuildDependencyTree(projectDir)
+	case coreutils.Dotnet:
+		dependencyTrees, err = dotnet.BuildDependencyTree(projectDir)
+	case coreutils.Cargo:
+		dependencyTrees, err = cargo.BuildDependencyTree(projectDir)
+	case coreutils.Composer:
+		dependencyTrees, err = composer.BuildDependencyTree(projectDir)
+	case coreutils.Bundler:
+		dependencyTrees, err = bundler.BuildDependencyTree(projectDir)
+	default:
+		err = errors.New(string(tech) + " is currently not supported")
+	}
+	return
+}
+
+// syncHeadlineProgressMgr is a ProgressMgr whose headline can be set by concurrent audit tasks.
+type syncHeadlineProgressMgr struct {
+	ioUtils.ProgressMgr
+	headlineMutex sync.Mutex
+}
+
+func (pm *syncHeadlineProgressMgr) SetHeadlineMsg(msg string) {
+	pm.headlineMutex.Lock()
+	defer pm.headlineMutex.Unlock()
+	pm.ProgressMgr.SetHeadlineMsg(msg)
+}
+
+func (pm *syncHeadlineProgressMgr) ClearHeadlineMsg() {
+	pm.headlineMutex.Lock()
+	defer pm.headlineMutex.Unlock()
+	pm.ProgressMgr.ClearHeadlineMsg()
+}
+
+func detectedTechnologies(projectDir string) (technologies []string, err error) {
+	detectedTechnologies, err := coreutils.DetectTechnologies(projectDir, false, false)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenericAuditAttributesErrorsToProjects(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	firstProject, secondProject := t.TempDir(), t.TempDir()
+
+	// No technology can be detected in empty projects
+	results, err := GenericAudit(services.XrayGraphScanParams{}, nil, false, false, false, nil, nil, "", false, []string{firstProject, secondProject}, false, nil, 2, AuditOptions{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "audit command in "+firstProject+" failed")
+		assert.Contains(t, err.Error(), "audit command in "+secondProject+" failed")
+	}
+	assert.Empty(t, results.ScanResults)
+
+	// Unsupported technologies fail each of the projects separately
+	_, err = GenericAudit(services.XrayGraphScanParams{}, nil, false, false, false, nil, nil, "", false, []string{firstProject, secondProject}, false, nil, 2, AuditOptions{}, "unknown")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "'unknown' audit command in "+firstProject+" failed")
+		assert.Contains(t, err.Error(), "'unknown' audit command in "+secondProject+" failed")
+	}
+
+	// The working directory is never changed
+	currentWd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.Equal(t, wd, currentWd)
+}
+
+func TestGenericAuditRecursive(t *testing.T) {
+	rootDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, "node_modules", "dep"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(rootDir, "node_modules", "dep", "package.json"), []byte("{}"), 0644))
+
+	// Projects in excluded directories aren't audited
+	_, err := GenericAudit(services.XrayGraphScanParams{}, nil, false, false, false, nil, nil, "", false, []string{rootDir}, true, nil, 2, AuditOptions{})
+	assert.ErrorContains(t, err, "could not find any project to audit in "+rootDir)
+
+	// Errors are attributed to the descriptor of the failing project
+	goModPath := filepath.Join(rootDir, "backend", "go.mod")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(goModPath), 0755))
+	assert.NoError(t, os.WriteFile(goModPath, []byte("invalid"), 0644))
+	_, err = GenericAudit(services.XrayGraphScanParams{}, nil, false, false, false, nil, nil, "", false, []string{rootDir}, true, []string{"frontend"}, 2, AuditOptions{}, "go")
+	assert.ErrorContains(t, err, "'go' audit command in "+goModPath+" failed")
+}
+
+func TestSbomAuditDependencyTreesOnly(t *testing.T) {
+	sbomFile := filepath.Join("..", "..", "testdata", "sbom", "cyclonedx.json")
+	// Xray isn't contacted, so no server details are needed
+	results, err := SbomAudit(services.XrayGraphScanParams{}, nil, nil, sbomFile, AuditOptions{DependencyTreesOnly: true})
+	assert.NoError(t, err)
+	assert.Empty(t, results.ScanResults)
+	assert.NotEmpty(t, results.DependencyTrees)
+	sbomPath, err := filepath.Abs(sbomFile)
+	assert.NoError(t, err)
+	assert.Len(t, results.DependencyTreesPaths, len(results.DependencyTrees))
+	assert.Equal(t, sbomPath, results.DependencyTreesPaths[0])
+}
+
+func TestGenericAuditDotnetRestoredProject(t *testing.T) {
+	projectDir := filepath.Join("..", "..", "testdata", "dotnet-project")
+	// NuGet and .NET are both detected. The restored projects are audited from their restore files only,
+	// and the Legacy project, which wasn't restored, is audited from the packages it declares.
+	results, err := GenericAudit(services.XrayGraphScanParams{}, nil, false, false, false, nil, nil, "", false, []string{projectDir}, false, nil, 2, AuditOptions{DependencyTreesOnly: true})
+	assert.NoError(t, err)
+	if assert.Len(t, results.DependencyTrees, 3) {
+		assert.Equal(t, "nuget://App", results.DependencyTrees[0].Id)
+		assert.Equal(t, "nuget://Legacy", results.DependencyTrees[1].Id)
+		assert.Equal(t, "nuget://Lib", results.DependencyTrees[2].Id)
+	}
+
+	// The tasks are run even if no threads are configured
+	results, err = GenericAudit(services.XrayGraphScanParams{}, nil, false, false, false, nil, nil, "", false, []string{projectDir}, false, nil, 0, AuditOptions{DependencyTreesOnly: true})
+	assert.NoError(t, err)
+	assert.Len(t, results.DependencyTrees, 3)
+}
@@ -2,15 +2,23 @@ package audit
 
 import (
 	"os"
+	"time"
 
 	ioUtils "github.com/jfrog/jfrog-client-go/utils/io"
 
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/audit"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
 	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray/services"
 )
 
+// The default number of projects and technologies audited concurrently.
+const defaultThreads = 3
+
 type GenericAuditCommand struct {
 	serverDetails           *config.ServerDetails
 	OutputFormat            xrutils.OutputFormat
@@ -28,11 +36,22 @@ type GenericAuditCommand struct {
 	args                    []string
 	technologies            []string
 	requirementsFile        string
+	sbomFile                string
+	baselineResultsFile     string
+	severityThresholds      *xrutils.SeverityThresholds
+	licensePolicy           *xrutils.LicensePolicy
+	threads                 int
+	recursive               bool
+	exclusions              []string
+	remediationMode         xrutils.RemediationMode
+	scanCacheTtl            time.Duration
+	noCache                 bool
+	dependencyTreeFormat    xrutils.DependencyTreeFormat
 	progress                ioUtils.ProgressMgr
 }
 
 func NewGenericAuditCommand() *GenericAuditCommand {
-	return &GenericAuditCommand{}
+	return &GenericAuditCommand{threads: defaultThreads}
 }
 
 func (auditCmd *GenericAuditCommand) SetServerDetails(server *config.ServerDetails) *GenericAuditCommand {
@@ -101,7 +120,8 @@ func (auditCmd *GenericAuditCommand) CreateXrayGraphScanParams() services.XrayGr
 		params.ProjectKey = auditCmd.projectKey
 	}
 	params.IncludeVulnerabilities = auditCmd.IncludeVulnerabilities
-	params.IncludeLicenses = auditCmd.IncludeLicenses
+	// The licenses are needed to evaluate the local license policy, even if they aren't printed.
+	params.IncludeLicenses = auditCmd.IncludeLicenses || auditCmd.licensePolicy != nil
 	return params
 }
 
@@ -110,19 +130,56 @@ func (auditCmd *GenericAuditCommand) Run() (err error) {
 	if err != nil {
 		return
 	}
-	results, isMultipleRootProject, auditErr := GenericAudit(
-		auditCmd.CreateXrayGraphScanParams(),
-		server,
-		auditCmd.excludeTestDependencies,
-		auditCmd.useWrapper,
-		auditCmd.insecureTls,
-		auditCmd.args,
-		auditCmd.progress,
-		auditCmd.requirementsFile,
-		false,
-		auditCmd.workingDirs,
-		auditCmd.technologies...,
-	)
+	if auditCmd.dependencyTreeFormat != "" {
+		return auditCmd.printDependencyTrees(server)
+	}
+	if err = auditCmd.severityThresholds.Validate(); err != nil {
+		return
+	}
+	if auditCmd.remediationMode == xrutils.RemediationDiff && auditCmd.OutputFormat != xrutils.Table {
+		return errorutils.CheckErrorf("the diff remediation mode is supported only with the table output format")
+	}
+	var baseline []services.ScanResponse
+	if auditCmd.baselineResultsFile != "" {
+		if baseline, err = xrutils.ReadBaselineResults(auditCmd.baselineResultsFile); err != nil {
+			return
+		}
+	}
+	rootDir := auditCmd.getRootDir()
+	ignoreRules, err := xrutils.GetIgnoreRules(rootDir)
+	if err != nil {
+		return
+	}
+	if auditCmd.licensePolicy, err = xrutils.GetLicensePolicy(rootDir); err != nil {
+		return
+	}
+	scanCache, err := audit.NewScanCache(auditCmd.scanCacheTtl, auditCmd.noCache)
+	if err != nil {
+		return
+	}
+	var results *Results
+	var auditErr error
+	if auditCmd.sbomFile != "" {
+		results, auditErr = SbomAudit(auditCmd.CreateXrayGraphScanParams(), server, auditCmd.progress, auditCmd.sbomFile, AuditOptions{ScanCache: scanCache})
+	} else {
+		results, auditErr = GenericAudit(
+			auditCmd.CreateXrayGraphScanParams(),
+			server,
+			auditCmd.excludeTestDependencies,
+			auditCmd.useWrapper,
+			auditCmd.insecureTls,
+			auditCmd.args,
+			auditCmd.progress,
+			auditCmd.requirementsFile,
+			false,
+			auditCmd.workingDirs,
+			auditCmd.recursive,
+			auditCmd.exclusions,
+			auditCmd.threads,
+			AuditOptions{ScanCache: scanCache},
+			auditCmd.technologies...,
+		)
+	}
 
 	if auditCmd.progress != nil {
 		err = auditCmd.progress.Quit()
@@ -130,33 +187,139 @@ func (auditCmd *GenericAuditCommand) Run() (err error) {
 			return
 		}
 	}
+	var remainingResults []services.ScanResponse
+	var suppressedResults []xrutils.SuppressedScanResults
+	if results != nil {
+		// The local license policy and ignore rules are applied before printing the results and checking whether the build should fail.
+		// The violations of the license policy can be suppressed by the ignore rules.
+		remainingResults, suppressedResults = ignoreRules.SuppressIssues(auditCmd.licensePolicy.AddLicenseViolations(results.ScanResults), results.ScannedPaths)
+	}
 	// Print Scan results on all cases except if errors accrued on Generic Audit command and no security/license issues found.
-	printScanResults := !(auditErr != nil && xrutils.IsEmptyScanResponse(results))
+	printScanResults := !(auditErr != nil && (results == nil || xrutils.IsEmptyScanResponse(results.ScanResults)))
+	var remediations []formats.RemediationRow
+	if printScanResults && auditCmd.remediationMode != "" {
+		if remediations, err = xrutils.GetRemediations(remainingResults, results.ScannedPaths, auditCmd.IncludeVulnerabilities, results.IsMultipleRootProject); err != nil {
+			return
+		}
+		if remediations == nil {
+			// An empty table is printed if no remediation is needed
+			remediations = []formats.RemediationRow{}
+		}
+	}
 	if printScanResults {
-		err = xrutils.PrintScanResults(results,
-			nil,
-			auditCmd.OutputFormat,
-			auditCmd.IncludeVulnerabilities,
-			auditCmd.IncludeLicenses,
-			isMultipleRootProject,
-			auditCmd.PrintExtendedTable,
-		)
+		err = xrutils.NewResultsWriter(remainingResults).
+			SetScannedPaths(results.ScannedPaths).
+			SetDependencyTrees(results.DependencyTrees).
+			SetBaselineResults(baseline).
+			SetSuppressedResults(suppressedResults).
+			SetRemediations(remediations).
+			SetOutputFormat(auditCmd.OutputFormat).
+			SetIncludeVulnerabilities(auditCmd.IncludeVulnerabilities).
+			SetIncludeLicenses(auditCmd.IncludeLicenses).
+			SetIsMultipleRootProject(results.IsMultipleRootProject).
+			SetPrintExtendedTable(auditCmd.PrintExtendedTable).
+			PrintScanResults()
 		if err != nil {
 			return
 		}
+		if err = auditCmd.applyRemediations(remediations); err != nil {
+			return
+		}
 	}
 	if auditErr != nil {
 		err = auditErr
 		return
 	}
 
+	if !auditCmd.Fail {
+		return
+	}
+	// In baseline mode, only new issues can fail the build.
+	newResults := xrutils.GetNewScanResults(remainingResults, baseline)
 	// Only in case Xray's context was given (!auditCmd.IncludeVulnerabilities) and the user asked to fail the build accordingly, do so.
-	if auditCmd.Fail && !auditCmd.IncludeVulnerabilities && xrutils.CheckIfFailBuild(results) {
+	if !auditCmd.IncludeVulnerabilities && xrutils.CheckIfFailBuild(newResults) {
 		err = xrutils.NewFailBuildError()
+	} else if xrutils.CheckIfLicensePolicyFailBuild(newResults) {
+		err = xrutils.NewLicensePolicyFailBuildError()
+	} else if xrutils.CheckIfExceedThresholds(newResults, auditCmd.severityThresholds) {
+		err = xrutils.NewThresholdsFailBuildError(auditCmd.severityThresholds)
 	}
 	return
 }
 
+// Builds the dependency trees of the projects and prints them, without scanning them with Xray.
+// getRootDir returns the directory the .jfrog directory of the audited project is looked for from: the first working directory, or the current directory if none was given.
+func (auditCmd *GenericAuditCommand) getRootDir() string {
+	if len(auditCmd.workingDirs) > 0 {
+		return auditCmd.workingDirs[0]
+	}
+	return "."
+}
+
+func (auditCmd *GenericAuditCommand) printDependencyTrees(server *config.ServerDetails) (err error) {
+	var results *Results
+	var auditErr error
+	if auditCmd.sbomFile != "" {
+		results, auditErr = SbomAudit(auditCmd.CreateXrayGraphScanParams(), server, auditCmd.progress, auditCmd.sbomFile, AuditOptions{DependencyTreesOnly: true})
+	} else {
+		results, auditErr = GenericAudit(
+			auditCmd.CreateXrayGraphScanParams(),
+			server,
+			auditCmd.excludeTestDependencies,
+			auditCmd.useWrapper,
+			auditCmd.insecureTls,
+			auditCmd.args,
+			auditCmd.progress,
+			auditCmd.requirementsFile,
+			false,
+			auditCmd.workingDirs,
+			auditCmd.recursive,
+			auditCmd.exclusions,
+			auditCmd.threads,
+			AuditOptions{DependencyTreesOnly: true},
+			auditCmd.technologies...,
+		)
+	}
+	if auditCmd.progress != nil {
+		if err = auditCmd.progress.Quit(); err != nil {
+			return
+		}
+	}
+	// The trees that were built are printed even if building the trees of other projects failed
+	if results != nil && len(results.DependencyTrees) > 0 {
+		if err = xrutils.PrintDependencyTrees(results.DependencyTrees, results.DependencyTreesPaths, auditCmd.dependencyTreeFormat); err != nil {
+			return
+		}
+	}
+	return auditErr
+}
+
+// Prints or applies the changes in the descriptors of the projects according to the remediation mode.
+func (auditCmd *GenericAuditCommand) applyRemediations(remediations []formats.RemediationRow) error {
+	if auditCmd.remediationMode != xrutils.RemediationDiff && auditCmd.remediationMode != xrutils.RemediationPatch {
+		return nil
+	}
+	patches, err := xrutils.CreateDescriptorPatches(remediations)
+	if err != nil {
+		return err
+	}
+	for _, patch := range patches {
+		if auditCmd.remediationMode == xrutils.RemediationDiff {
+			diff, err := patch.GetUnifiedDiff()
+			if err != nil {
+				return err
+			}
+			log.Output(diff)
+			continue
+		}
+		if err = patch.Apply(); err != nil {
+			return err
+		}
+		log.Info("Upgraded the vulnerable dependencies in " + patch.DescriptorPath)
+	}
+	return nil
+}
+
 func (auditCmd *GenericAuditCommand) CommandName() string {
 	return "generic_audit"
 }
@@ -178,6 +341,70 @@ func (auditCmd *GenericAuditCommand) SetPipRequirementsFile(requirementsFile str
 	return auditCmd
 }
 
+// SetSbomFile sets a CycloneDX or SPDX file to audit, instead of running the project's package manager.
+func (auditCmd *GenericAuditCommand) SetSbomFile(sbomFile string) *GenericAuditCommand {
+	auditCmd.sbomFile = sbomFile
+	return auditCmd
+}
+
+// SetBaselineResultsFile sets the results file of a previous audit, saved in the json format.
+// If set, only issues that were added or resolved since are printed, and only new issues can fail the build.
+func (auditCmd *GenericAuditCommand) SetBaselineResultsFile(baselineResultsFile string) *GenericAuditCommand {
+	auditCmd.baselineResultsFile = baselineResultsFile
+	return auditCmd
+}
+
+// SetSeverityThresholds sets thresholds to fail the build by, according to the severity and CVSS score of the issues found.
+// Unlike the fail build rules of Xray policies, the thresholds are applied also when no watches are provided.
+func (auditCmd *GenericAuditCommand) SetSeverityThresholds(thresholds *xrutils.SeverityThresholds) *GenericAuditCommand {
+	auditCmd.severityThresholds = thresholds
+	return auditCmd
+}
+
+// SetThreads sets the maximum number of projects and technologies audited concurrently.
+func (auditCmd *GenericAuditCommand) SetThreads(threads int) *GenericAuditCommand {
+	auditCmd.threads = threads
+	return auditCmd
+}
+
+// SetRecursive sets whether to audit all the projects found in the working directories and in their subdirectories.
+func (auditCmd *GenericAuditCommand) SetRecursive(recursive bool) *GenericAuditCommand {
+	auditCmd.recursive = recursive
+	return auditCmd
+}
+
+// SetExclusions sets glob patterns of directories to skip when looking for projects recursively, in addition to coreutils.DefaultProjectsExcludePatterns.
+func (auditCmd *GenericAuditCommand) SetExclusions(exclusions []string) *GenericAuditCommand {
+	auditCmd.exclusions = exclusions
+	return auditCmd
+}
+
+// SetRemediationMode sets whether to suggest upgrades of the vulnerable direct dependencies, and whether to print or apply the changes in the projects' descriptors.
+func (auditCmd *GenericAuditCommand) SetRemediationMode(remediationMode xrutils.RemediationMode) *GenericAuditCommand {
+	auditCmd.remediationMode = remediationMode
+	return auditCmd
+}
+
+// SetDependencyTreeFormat sets the format to print the dependency trees of the projects in.
+// If set, the command only builds and prints the dependency trees, without scanning them with Xray.
+func (auditCmd *GenericAuditCommand) SetDependencyTreeFormat(format xrutils.DependencyTreeFormat) *GenericAuditCommand {
+	auditCmd.dependencyTreeFormat = format
+	return auditCmd
+}
+
+// SetScanCacheTtl enables caching the Xray scan results of the projects' dependency trees under the JFrog CLI home directory, for the given duration.
+// Dependency trees that were already scanned with the same parameters during that time aren't scanned again. A zero TTL disables the cache.
+func (auditCmd *GenericAuditCommand) SetScanCacheTtl(ttl time.Duration) *GenericAuditCommand {
+	auditCmd.scanCacheTtl = ttl
+	return auditCmd
+}
+
+// SetNoCache sets whether to ignore the cached scan results, and scan all the dependency trees again. The cache is still updated with the new results.
+func (auditCmd *GenericAuditCommand) SetNoCache(noCache bool) *GenericAuditCommand {
+	auditCmd.noCache = noCache
+	return auditCmd
+}
+
 func (auditCmd *GenericAuditCommand) SetExcludeTestDependencies(excludeTestDependencies bool) *GenericAuditCommand {
 	auditCmd.excludeTestDependencies = excludeTestDependencies
 	return auditCmd
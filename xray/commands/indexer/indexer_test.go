@@ -0,0 +1,130 @@
+package indexer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/stretchr/testify/assert"
+)
+
+const servedIndexerVersion = "1.5.0"
+
+func getIndexerBinaryName() string {
+	if coreutils.IsWindows() {
+		return "indexer-app.exe"
+	}
+	return "indexer-app"
+}
+
+// Creates cached indexers of the given versions in a temporary JFrog home directory, and returns the directory of the cached indexers.
+func createCachedIndexers(t *testing.T, versions ...string) string {
+	t.Setenv(coreutils.HomeDir, t.TempDir())
+	indexersDir, err := xrutils.GetIndexersDir()
+	assert.NoError(t, err)
+	for _, indexerVersion := range versions {
+		assert.NoError(t, os.MkdirAll(filepath.Join(indexersDir, indexerVersion), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(indexersDir, indexerVersion, getIndexerBinaryName()), []byte(indexerVersion), 0755))
+	}
+	return indexersDir
+}
+
+func getCachedVersions(t *testing.T) (versions []string) {
+	indexers, err := xrutils.GetCachedIndexers()
+	assert.NoError(t, err)
+	for _, indexer := range indexers {
+		versions = append(versions, indexer.Version)
+	}
+	return
+}
+
+// Serves the Xray version and an indexer that reports servedIndexerVersion.
+func createXrayServer(t *testing.T) *httptest.Server {
+	indexerContent := fmt.Sprintf("#!/bin/sh\necho jfrog xray indexer-app version %s\n", servedIndexerVersion)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/system/version":
+			_, _ = w.Write([]byte(`{"xray_version":"3.60.0"}`))
+		case fmt.Sprintf("/api/v1/indexer-resources/download/%s/%s", runtime.GOOS, runtime.GOARCH):
+			_, _ = w.Write([]byte(indexerContent))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetCachedIndexerRows(t *testing.T) {
+	createCachedIndexers(t)
+	rows, err := getCachedIndexerRows()
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+
+	indexersDir := createCachedIndexers(t, "1.0.0", "1.2.0")
+	// The checksum of an indexer is saved when it's first used
+	_, err = xrutils.GetIndexer(nil, "1.2.0", nil)
+	assert.NoError(t, err)
+	rows, err = getCachedIndexerRows()
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "1.2.0", rows[0].Version)
+		assert.Equal(t, filepath.Join(indexersDir, "1.2.0", getIndexerBinaryName()), rows[0].Path)
+		assert.NotEmpty(t, rows[0].Sha256)
+		assert.Equal(t, "Yes", rows[0].Verified)
+		assert.Equal(t, cachedIndexerRow{Version: "1.0.0", Path: filepath.Join(indexersDir, "1.0.0", getIndexerBinaryName()), Verified: "No"}, rows[1])
+	}
+}
+
+func TestPruneCommand(t *testing.T) {
+	createCachedIndexers(t, "1.0.0", "1.1.0", "1.2.0", "1.3.0")
+
+	// The newest indexers are kept by default
+	assert.NoError(t, NewPruneCommand().Run())
+	assert.Equal(t, []string{"1.3.0", "1.2.0"}, getCachedVersions(t))
+
+	// A negative number of indexers to keep is rejected without deleting any indexer
+	assert.ErrorContains(t, NewPruneCommand().SetKeep(-1).Run(), "can't be negative")
+	assert.Equal(t, []string{"1.3.0", "1.2.0"}, getCachedVersions(t))
+
+	// With 0, all the indexers but those of the versions to keep are deleted
+	assert.NoError(t, NewPruneCommand().SetKeep(0).SetVersionsToKeep([]string{"1.2.0"}).Run())
+	assert.Equal(t, []string{"1.2.0"}, getCachedVersions(t))
+	assert.NoError(t, NewPruneCommand().SetKeep(0).Run())
+	assert.Empty(t, getCachedVersions(t))
+}
+
+func TestPrefetchCommand(t *testing.T) {
+	if coreutils.IsWindows() {
+		t.Skip("The served indexer is a shell script.")
+	}
+	serverDetails := &config.ServerDetails{XrayUrl: createXrayServer(t).URL + "/"}
+
+	// A pinned version that is already cached isn't downloaded again
+	createCachedIndexers(t, "1.0.0")
+	assert.NoError(t, NewPrefetchCommand().SetServerDetails(serverDetails).SetIndexerSettings(&xrutils.IndexerSettings{Version: "1.0.0"}).Run())
+	assert.Equal(t, []string{"1.0.0"}, getCachedVersions(t))
+
+	// A pinned version that Xray serves is downloaded to the cache
+	assert.NoError(t, NewPrefetchCommand().SetServerDetails(serverDetails).SetIndexerSettings(&xrutils.IndexerSettings{Version: servedIndexerVersion}).Run())
+	assert.Equal(t, []string{servedIndexerVersion, "1.0.0"}, getCachedVersions(t))
+
+	// A pinned version that Xray doesn't serve fails, and the downloaded indexer isn't cached
+	indexersDir := createCachedIndexers(t)
+	err := NewPrefetchCommand().SetServerDetails(serverDetails).SetIndexerSettings(&xrutils.IndexerSettings{Version: "1.4.0"}).Run()
+	assert.ErrorContains(t, err, "Xray Indexer 1.4.0 is required, but Xray serves version "+servedIndexerVersion)
+	assert.Empty(t, getCachedVersions(t))
+	for _, dirName := range []string{servedIndexerVersion, "1.4.0", "temp"} {
+		exists, err := fileutils.IsDirExists(filepath.Join(indexersDir, dirName), false)
+		assert.NoError(t, err)
+		assert.False(t, exists, dirName)
+	}
+}
@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
+)
+
+// Lists the Xray Indexers cached locally.
+type ListCommand struct{}
+
+type cachedIndexerRow struct {
+	Version  string `col-name:"Version"`
+	Path     string `col-name:"Path"`
+	Sha256   string `col-name:"SHA-256"`
+	Verified string `col-name:"Verified"`
+}
+
+func NewListCommand() *ListCommand {
+	return &ListCommand{}
+}
+
+func (lc *ListCommand) Run() error {
+	rows, err := getCachedIndexerRows()
+	if err != nil {
+		return err
+	}
+	return coreutils.PrintTable(rows, "Cached Xray Indexers", "No Xray Indexers are cached locally", false)
+}
+
+func getCachedIndexerRows() (rows []cachedIndexerRow, err error) {
+	indexers, err := xrutils.GetCachedIndexers()
+	if err != nil {
+		return
+	}
+	for _, indexer := range indexers {
+		row := cachedIndexerRow{Version: indexer.Version, Path: indexer.Path, Sha256: indexer.Sha256, Verified: "No"}
+		if indexer.Verified {
+			row.Verified = "Yes"
+		}
+		rows = append(rows, row)
+	}
+	return
+}
+
+func (lc *ListCommand) ServerDetails() (*config.ServerDetails, error) {
+	return nil, nil
+}
+
+func (lc *ListCommand) CommandName() string {
+	return "xr_indexer_list"
+}
@@ -0,0 +1,51 @@
+package indexer
+
+import (
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/commands"
+	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Downloads the Xray Indexer to the local cache ahead of time, so that it can be used by runners without access to Xray.
+type PrefetchCommand struct {
+	serverDetails   *config.ServerDetails
+	indexerSettings *xrutils.IndexerSettings
+}
+
+func NewPrefetchCommand() *PrefetchCommand {
+	return &PrefetchCommand{}
+}
+
+func (pc *PrefetchCommand) SetServerDetails(serverDetails *config.ServerDetails) *PrefetchCommand {
+	pc.serverDetails = serverDetails
+	return pc
+}
+
+// SetIndexerSettings sets the version of the indexer to download and its expected checksum.
+// By default, the indexer matching the Xray version is downloaded.
+func (pc *PrefetchCommand) SetIndexerSettings(settings *xrutils.IndexerSettings) *PrefetchCommand {
+	pc.indexerSettings = settings
+	return pc
+}
+
+func (pc *PrefetchCommand) Run() error {
+	xrayManager, xrayVersion, err := commands.CreateXrayServiceManagerAndGetVersion(pc.serverDetails)
+	if err != nil {
+		return err
+	}
+	indexerPath, err := xrutils.GetIndexer(xrayManager, xrayVersion, pc.indexerSettings)
+	if err != nil {
+		return err
+	}
+	log.Info("Xray Indexer is cached at " + indexerPath)
+	return nil
+}
+
+func (pc *PrefetchCommand) ServerDetails() (*config.ServerDetails, error) {
+	return pc.serverDetails, nil
+}
+
+func (pc *PrefetchCommand) CommandName() string {
+	return "xr_indexer_prefetch"
+}
@@ -0,0 +1,53 @@
+package indexer
+
+import (
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	xrutils "github.com/jfrog/jfrog-cli-core/v2/xray/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Deletes the Xray Indexers cached locally, except for the newest ones and the ones of the given versions.
+type PruneCommand struct {
+	keep           int
+	versionsToKeep []string
+}
+
+func NewPruneCommand() *PruneCommand {
+	return &PruneCommand{keep: xrutils.DefaultIndexersToKeep}
+}
+
+// SetKeep sets the number of the newest indexers that aren't deleted. With 0, all the indexers but those of the versions to keep are deleted.
+// xrutils.DefaultIndexersToKeep is used by default.
+func (pc *PruneCommand) SetKeep(keep int) *PruneCommand {
+	pc.keep = keep
+	return pc
+}
+
+// SetVersionsToKeep sets versions of indexers that aren't deleted, like the versions pinned by the scans.
+func (pc *PruneCommand) SetVersionsToKeep(versionsToKeep []string) *PruneCommand {
+	pc.versionsToKeep = versionsToKeep
+	return pc
+}
+
+func (pc *PruneCommand) Run() error {
+	deletedVersions, err := xrutils.PruneIndexers(pc.keep, pc.versionsToKeep...)
+	if err != nil {
+		return err
+	}
+	if len(deletedVersions) == 0 {
+		log.Info("No Xray Indexers were deleted.")
+		return nil
+	}
+	log.Info("Deleted Xray Indexers: " + strings.Join(deletedVersions, ", "))
+	return nil
+}
+
+func (pc *PruneCommand) ServerDetails() (*config.ServerDetails, error) {
+	return nil, nil
+}
+
+func (pc *PruneCommand) CommandName() string {
+	return "xr_indexer_prune"
+}
@@ -0,0 +1,302 @@
+package offlineupdate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jfrog/gofrog/parallel"
+	"github.com/jfrog/jfrog-client-go/http/httpclient"
+	"github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/httputils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	DefaultDownloadThreads = 3
+	// The number of times a file download is resumed after it was interrupted.
+	downloadRetries                  = 5
+	downloadRetriesIntervalMilliSecs = 5000
+	downloadManifestFileName         = "manifest.json"
+	// The downloaded files are kept in this subdirectory of the download directory, next to the manifest.
+	downloadDataDirName = "data"
+)
+
+// A file of the updates package.
+type downloadItem struct {
+	Url string
+	// The SHA-256 checksum of the file, if the updates metadata provides one.
+	Sha256 string
+}
+
+// The state of a download, persisted in the download directory, so that an interrupted download can continue from where it stopped.
+type downloadManifest struct {
+	// The files to download, by their names in the data directory.
+	Files map[string]*downloadManifestEntry `json:"files"`
+	path  string
+	mutex sync.Mutex
+}
+
+type downloadManifestEntry struct {
+	Url       string `json:"url"`
+	Sha256    string `json:"sha256,omitempty"`
+	Completed bool   `json:"completed"`
+}
+
+// Creates the manifest of the given files, by their names. If downloadDir has the manifest of a former download, the files that were downloaded
+// (completely or partially) and are still needed are kept, and the rest are removed.
+func loadDownloadManifest(downloadDir string, files map[string]downloadItem) (manifest *downloadManifest, err error) {
+	dataDir := filepath.Join(downloadDir, downloadDataDirName)
+	if err = os.MkdirAll(dataDir, 0777); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	manifest = &downloadManifest{Files: make(map[string]*downloadManifestEntry), path: filepath.Join(downloadDir, downloadManifestFileName)}
+	formerManifest := &downloadManifest{}
+	content, err := os.ReadFile(manifest.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errorutils.CheckError(err)
+	}
+	if err == nil {
+		if err = json.Unmarshal(content, formerManifest); err != nil {
+			log.Warn(fmt.Sprintf("Couldn't read the download manifest %s, the files are downloaded from scratch: %s", manifest.path, err.Error()))
+			formerManifest.Files = nil
+		}
+	}
+	// Files which were downloaded (completely or partially) by the former download are kept, unless they aren't needed anymore or might have changed since
+	keptFiles := make(map[string]bool)
+	for fileName, item := range files {
+		if formerEntry, exists := formerManifest.Files[fileName]; exists && formerEntry.Sha256 == item.Sha256 {
+			keptFiles[fileName] = formerEntry.Completed
+		}
+	}
+	dataDirEntries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	existingFiles := make(map[string]bool)
+	for _, dirEntry := range dataDirEntries {
+		if _, kept := keptFiles[dirEntry.Name()]; kept {
+			existingFiles[dirEntry.Name()] = true
+			continue
+		}
+		if err = os.RemoveAll(filepath.Join(dataDir, dirEntry.Name())); err != nil {
+			return nil, errorutils.CheckError(err)
+		}
+	}
+	for fileName, item := range files {
+		manifest.Files[fileName] = &downloadManifestEntry{Url: item.Url, Sha256: item.Sha256, Completed: keptFiles[fileName] && existingFiles[fileName]}
+	}
+	return manifest, manifest.save()
+}
+
+func (manifest *downloadManifest) setCompleted(fileName string) error {
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+	manifest.Files[fileName].Completed = true
+	return manifest.save()
+}
+
+func (manifest *downloadManifest) save() error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	return errorutils.CheckError(os.WriteFile(manifest.path, content, 0600))
+}
+
+// Downloads the files to the data subdirectory of downloadDir, using the given number of threads.
+// The download continues from where a former download to the same directory stopped. Files which were partially downloaded are resumed
+// using HTTP range requests, and files with checksums are verified.
+// If some of the files fail to download, the rest of the files are still downloaded, and downloadDir is kept for the next download.
+func downloadData(files []downloadItem, downloadDir string, fileNameFromUrlFunc func(string) (string, error), threads int) (dataDir string, err error) {
+	filesByName := make(map[string]downloadItem)
+	for _, file := range files {
+		fileName, err := fileNameFromUrlFunc(file.Url)
+		if err != nil {
+			return "", err
+		}
+		filesByName[fileName] = file
+	}
+	manifest, err := loadDownloadManifest(downloadDir, filesByName)
+	if err != nil {
+		return "", err
+	}
+	dataDir = filepath.Join(downloadDir, downloadDataDirName)
+	client, err := httpclient.ClientBuilder().SetRetries(3).Build()
+	if err != nil {
+		return "", err
+	}
+	if threads <= 0 {
+		threads = DefaultDownloadThreads
+	}
+
+	var errorsMutex sync.Mutex
+	var errorList []string
+	runner := parallel.NewRunner(threads, uint(len(manifest.Files)), false)
+	for fileName, entry := range manifest.Files {
+		currentFileName, currentEntry := fileName, entry
+		if currentEntry.Completed {
+			log.Info(fmt.Sprintf("The updates package %s was already downloaded.", currentFileName))
+			continue
+		}
+		_, _ = runner.AddTask(func(int) error {
+			e := downloadFile(client, currentEntry, filepath.Join(dataDir, currentFileName))
+			if e == nil {
+				e = manifest.setCompleted(currentFileName)
+			}
+			if e != nil {
+				// Save the error but continue to download the other files
+				errorsMutex.Lock()
+				errorList = append(errorList, fmt.Sprintf("Couldn't download from %s. Error: %s", currentEntry.Url, e.Error()))
+				errorsMutex.Unlock()
+			}
+			return nil
+		})
+	}
+	runner.Done()
+	runner.Run()
+
+	if len(errorList) > 0 {
+		return "", errorutils.CheckErrorf("%d of %d updates packages failed to download. Run the command again to resume the download.\n%s",
+			len(errorList), len(manifest.Files), strings.Join(errorList, "\n"))
+	}
+	log.Info("Download completed.")
+	return dataDir, nil
+}
+
+// Downloads the file to localPath. If localPath exists, the download continues from its end.
+// If the download is interrupted, it is resumed up to downloadRetries times.
+func downloadFile(client *httpclient.HttpClient, entry *downloadManifestEntry, localPath string) error {
+	retryExecutor := utils.RetryExecutor{
+		MaxRetries:               downloadRetries,
+		RetriesIntervalMilliSecs: downloadRetriesIntervalMilliSecs,
+		ErrorMessage:             fmt.Sprintf("Failure occurred while downloading from %s", entry.Url),
+		ExecutionHandler: func() (bool, error) {
+			resumed, shouldRetry, err := downloadFileRemainder(client, entry.Url, localPath)
+			if err != nil {
+				return shouldRetry, err
+			}
+			if err = verifyChecksum(localPath, entry.Sha256); err != nil {
+				// The file is downloaded from scratch, in case the part downloaded before has changed since
+				if e := os.Remove(localPath); e != nil {
+					return false, errorutils.CheckError(e)
+				}
+				return resumed, err
+			}
+			return false, nil
+		},
+	}
+	return retryExecutor.Execute()
+}
+
+// Downloads the part of the remote file that is missing in localPath.
+// Returns true if the download was resumed from the end of an existing file, and whether the download should be retried if it failed.
+func downloadFileRemainder(client *httpclient.HttpClient, url, localPath string) (resumed, shouldRetry bool, err error) {
+	var offset int64
+	fileInfo, err := os.Stat(localPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, false, errorutils.CheckError(err)
+	}
+	if err == nil {
+		offset = fileInfo.Size()
+	}
+	httpClientDetails := httputils.HttpClientDetails{Headers: make(map[string]string)}
+	if offset > 0 {
+		log.Info(fmt.Sprintf("Resuming the download of the updates package from %s at byte %d", url, offset))
+		httpClientDetails.Headers["Range"] = fmt.Sprintf("bytes=%d-", offset)
+	} else {
+		log.Info(fmt.Sprintf("Downloading updates package from %s", url))
+	}
+	resp, _, _, err := client.Stream(url, httpClientDetails, "")
+	if err != nil {
+		return false, true, err
+	}
+	defer func() {
+		if e := resp.Body.Close(); err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		resumed = true
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server doesn't support range requests, so the file is downloaded from scratch
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The file might have been completely downloaded before
+		if remoteSize, ok := getRangeNotSatisfiableSize(resp); ok && remoteSize == offset {
+			return true, false, nil
+		}
+		if err = os.Remove(localPath); err != nil {
+			return false, false, errorutils.CheckError(err)
+		}
+		return false, true, errorutils.CheckErrorf("the local file %s is larger than the remote file, so it was removed", localPath)
+	default:
+		return false, false, errorutils.CheckErrorf("%s received when attempting to download %s", resp.Status, url)
+	}
+	file, err := os.OpenFile(localPath, flags, 0644)
+	if err != nil {
+		return false, false, errorutils.CheckError(err)
+	}
+	defer func() {
+		if e := file.Close(); err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	// If the download is interrupted, it can be resumed from the part that was written to the file
+	_, err = io.Copy(file, resp.Body)
+	return resumed, true, errorutils.CheckError(err)
+}
+
+// Returns the size of the remote file from the 'Content-Range: bytes */<size>' header of a 416 response.
+func getRangeNotSatisfiableSize(resp *http.Response) (int64, bool) {
+	contentRange := resp.Header.Get("Content-Range")
+	if !strings.HasPrefix(contentRange, "bytes */") {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimPrefix(contentRange, "bytes */"), 10, 64)
+	return size, err == nil
+}
+
+func verifyChecksum(localPath, expectedSha256 string) error {
+	if expectedSha256 == "" {
+		return nil
+	}
+	actualSha256, err := getFileSha256(localPath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actualSha256, expectedSha256) {
+		return errorutils.CheckErrorf("the SHA-256 checksum of %s is %s, while %s was expected", localPath, actualSha256, expectedSha256)
+	}
+	return nil
+}
+
+func getFileSha256(localPath string) (checksum string, err error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	defer func() {
+		if e := file.Close(); err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,122 @@
+package offlineupdate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var remoteFiles = map[string][]byte{
+	"vuln.zip": bytes.Repeat([]byte("vulnerabilities"), 1000),
+	"comp.zip": bytes.Repeat([]byte("components"), 1000),
+}
+
+// Serves remoteFiles, with support for range requests, and records the Range header of each request.
+func createFilesServer(t *testing.T) (server *httptest.Server, requestedRanges map[string][]string) {
+	var mutex sync.Mutex
+	requestedRanges = make(map[string][]string)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fileName := strings.TrimPrefix(r.URL.Path, "/")
+		mutex.Lock()
+		requestedRanges[fileName] = append(requestedRanges[fileName], r.Header.Get("Range"))
+		mutex.Unlock()
+		content, exists := remoteFiles[fileName]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, fileName, time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return
+}
+
+func getSha256(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func readDownloadManifest(t *testing.T, downloadDir string) *downloadManifest {
+	content, err := os.ReadFile(filepath.Join(downloadDir, downloadManifestFileName))
+	assert.NoError(t, err)
+	manifest := &downloadManifest{}
+	assert.NoError(t, json.Unmarshal(content, manifest))
+	return manifest
+}
+
+func TestDownloadDataResume(t *testing.T) {
+	server, requestedRanges := createFilesServer(t)
+	files := []downloadItem{{Url: server.URL + "/vuln.zip", Sha256: getSha256(remoteFiles["vuln.zip"])}, {Url: server.URL + "/comp.zip"}}
+
+	// Simulate an interrupted download, in which half of vuln.zip and a file which isn't needed anymore were downloaded
+	downloadDir := t.TempDir()
+	dataDir := filepath.Join(downloadDir, downloadDataDirName)
+	assert.NoError(t, os.MkdirAll(dataDir, 0777))
+	partSize := len(remoteFiles["vuln.zip"]) / 2
+	assert.NoError(t, os.WriteFile(filepath.Join(dataDir, "vuln.zip"), remoteFiles["vuln.zip"][:partSize], 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dataDir, "old.zip"), []byte("old"), 0644))
+	formerManifest := &downloadManifest{Files: map[string]*downloadManifestEntry{
+		"vuln.zip": {Url: files[0].Url, Sha256: files[0].Sha256},
+		"old.zip":  {Url: server.URL + "/old.zip", Completed: true},
+	}, path: filepath.Join(downloadDir, downloadManifestFileName)}
+	assert.NoError(t, formerManifest.save())
+
+	actualDataDir, err := downloadData(files, downloadDir, createXrayFileNameFromUrlV3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, dataDir, actualDataDir)
+	for fileName, expectedContent := range remoteFiles {
+		content, err := os.ReadFile(filepath.Join(dataDir, fileName))
+		assert.NoError(t, err)
+		assert.Equal(t, expectedContent, content)
+	}
+	assert.NoFileExists(t, filepath.Join(dataDir, "old.zip"))
+	// Only the missing part of vuln.zip was downloaded
+	assert.Equal(t, map[string][]string{"vuln.zip": {fmt.Sprintf("bytes=%d-", partSize)}, "comp.zip": {""}}, requestedRanges)
+	manifest := readDownloadManifest(t, downloadDir)
+	assert.Len(t, manifest.Files, 2)
+	assert.True(t, manifest.Files["vuln.zip"].Completed)
+	assert.True(t, manifest.Files["comp.zip"].Completed)
+
+	// The files were already downloaded, so nothing is downloaded again
+	_, err = downloadData(files, downloadDir, createXrayFileNameFromUrlV3, 2)
+	assert.NoError(t, err)
+	assert.Len(t, requestedRanges["vuln.zip"], 1)
+	assert.Len(t, requestedRanges["comp.zip"], 1)
+}
+
+func TestDownloadDataFailure(t *testing.T) {
+	server, requestedRanges := createFilesServer(t)
+	files := []downloadItem{
+		{Url: server.URL + "/vuln.zip", Sha256: getSha256([]byte("other content"))},
+		{Url: server.URL + "/missing.zip"},
+		{Url: server.URL + "/comp.zip"},
+	}
+	downloadDir := t.TempDir()
+	_, err := downloadData(files, downloadDir, createXrayFileNameFromUrlV3, 0)
+	assert.ErrorContains(t, err, "2 of 3 updates packages failed to download. Run the command again to resume the download.")
+	assert.ErrorContains(t, err, "the SHA-256 checksum of")
+	assert.ErrorContains(t, err, "404 Not Found received when attempting to download")
+	// Files which were downloaded from scratch aren't downloaded again in the same run
+	assert.Len(t, requestedRanges["vuln.zip"], 1)
+	assert.Len(t, requestedRanges["missing.zip"], 1)
+
+	// The file that failed the checksum verification is removed, and the file that was downloaded is kept for the next run
+	assert.NoFileExists(t, filepath.Join(downloadDir, downloadDataDirName, "vuln.zip"))
+	assert.FileExists(t, filepath.Join(downloadDir, downloadDataDirName, "comp.zip"))
+	manifest := readDownloadManifest(t, downloadDir)
+	assert.False(t, manifest.Files["vuln.zip"].Completed)
+	assert.False(t, manifest.Files["missing.zip"].Completed)
+	assert.True(t, manifest.Files["comp.zip"].Completed)
+}
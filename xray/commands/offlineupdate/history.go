@@ -0,0 +1,136 @@
+package offlineupdate
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+)
+
+const (
+	archiveStatusOk               = "OK"
+	archiveStatusMissing          = "Missing"
+	archiveStatusChecksumMismatch = "Checksum mismatch"
+	archiveStatusCorrupted        = "Corrupted"
+)
+
+type OfflineUpdateHistoryFlags struct {
+	// The state file of the incremental updates. The file in the JFrog home directory is used if not set.
+	StateFile string
+	// If true, the archives of the exports are verified against the checksums recorded when they were created, and their content is validated.
+	Verify bool
+}
+
+type offlineUpdateExportRow struct {
+	Time          string                    `col-name:"Time"`
+	DBSyncVersion string                    `col-name:"DBSync"`
+	Type          string                    `col-name:"Type"`
+	From          string                    `col-name:"From" omitempty:"true"`
+	To            string                    `col-name:"To" omitempty:"true"`
+	LastUpdate    string                    `col-name:"Last Update" omitempty:"true"`
+	Archives      []offlineUpdateArchiveRow `embed-table:"true"`
+}
+
+type offlineUpdateArchiveRow struct {
+	Path   string `col-name:"Archive"`
+	Size   string `col-name:"Size\n(Bytes)"`
+	Status string `col-name:"Status" omitempty:"true"`
+}
+
+// OfflineUpdateHistory prints the exports of the incremental offline updates.
+// If flags.Verify is true, the archives of the exports are verified, and an error is returned if any of them is missing or corrupted.
+func OfflineUpdateHistory(flags *OfflineUpdateHistoryFlags) error {
+	state, err := LoadOfflineUpdateState(flags.StateFile)
+	if err != nil {
+		return err
+	}
+	var rows []offlineUpdateExportRow
+	var failedArchives []string
+	for _, export := range state.History {
+		row := offlineUpdateExportRow{
+			Time:          export.Time.Format(time.RFC3339),
+			DBSyncVersion: export.DBSyncVersion,
+			Type:          export.Type,
+			From:          formatUpdateTime(export.From),
+			To:            formatUpdateTime(export.To),
+			LastUpdate:    formatUpdateTime(export.LastUpdate),
+		}
+		for _, archive := range export.Archives {
+			archiveRow := offlineUpdateArchiveRow{Path: archive.Path, Size: strconv.FormatInt(archive.Size, 10)}
+			if flags.Verify {
+				if archiveRow.Status, err = verifyArchive(archive); err != nil {
+					return err
+				}
+				if archiveRow.Status != archiveStatusOk {
+					failedArchives = append(failedArchives, fmt.Sprintf("%s: %s", archive.Path, archiveRow.Status))
+				}
+			}
+			row.Archives = append(row.Archives, archiveRow)
+		}
+		rows = append(rows, row)
+	}
+	if err = coreutils.PrintTable(rows, "Offline Updates History", "No offline updates were exported", false); err != nil {
+		return err
+	}
+	if len(failedArchives) > 0 {
+		return errorutils.CheckErrorf("%d of the exported archives failed the verification:\n%s", len(failedArchives), strings.Join(failedArchives, "\n"))
+	}
+	return nil
+}
+
+func formatUpdateTime(milliseconds int64) string {
+	if milliseconds == 0 {
+		return ""
+	}
+	return time.UnixMilli(milliseconds).UTC().Format(time.RFC3339)
+}
+
+// Returns the status of the archive. An error is returned only if the verification itself failed.
+func verifyArchive(archive OfflineUpdateArchive) (string, error) {
+	if _, err := os.Stat(archive.Path); err != nil {
+		if os.IsNotExist(err) {
+			return archiveStatusMissing, nil
+		}
+		return "", errorutils.CheckError(err)
+	}
+	sha256, err := getFileSha256(archive.Path)
+	if err != nil {
+		return "", err
+	}
+	if sha256 != archive.Sha256 {
+		return archiveStatusChecksumMismatch, nil
+	}
+	if !isValidZip(archive.Path) {
+		return archiveStatusCorrupted, nil
+	}
+	return archiveStatusOk, nil
+}
+
+// Reads all the files in the zip, so that their CRC-32 checksums are verified.
+func isValidZip(zipPath string) bool {
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = zipReader.Close()
+	}()
+	for _, file := range zipReader.File {
+		fileReader, err := file.Open()
+		if err != nil {
+			return false
+		}
+		_, err = io.Copy(io.Discard, fileReader)
+		_ = fileReader.Close()
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
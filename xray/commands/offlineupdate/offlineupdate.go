@@ -4,12 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-client-go/http/httpclient"
@@ -30,61 +30,81 @@ const (
 	onboardingState     = "onboarding"
 )
 
-func OfflineUpdate(flags *OfflineUpdatesFlags) error {
+func OfflineUpdate(flags *OfflineUpdatesFlags) (err error) {
+	exportTime := time.Now()
+	var state *OfflineUpdateState
+	if flags.Incremental {
+		if state, err = LoadOfflineUpdateState(flags.StateFile); err != nil {
+			return
+		}
+		if err = state.applyNextUpdate(flags, exportTime); err != nil {
+			return
+		}
+	}
+	var archivesPaths []string
+	var lastUpdate int64
 	if flags.IsDBSyncV3 {
-		return handleDBSyncV3OfflineUpdate(flags)
+		archivesPaths, err = handleDBSyncV3OfflineUpdate(flags)
+	} else {
+		archivesPaths, lastUpdate, err = handleDBSyncV1OfflineUpdate(flags)
+	}
+	if err != nil || state == nil {
+		return
 	}
-	return handleDBSyncV1OfflineUpdate(flags)
+	return state.addExport(flags, exportTime, lastUpdate, archivesPaths)
 }
 
-func handleDBSyncV1OfflineUpdate(flags *OfflineUpdatesFlags) error {
+// Returns the paths of the created archives, and the time of the update's data as returned by Xray.
+func handleDBSyncV1OfflineUpdate(flags *OfflineUpdatesFlags) (archivesPaths []string, lastUpdate int64, err error) {
 	updatesUrl, err := buildUpdatesUrl(flags)
 	if err != nil {
-		return err
+		return
 	}
 	vulnerabilities, components, lastUpdate, err := getFilesList(updatesUrl, flags)
 	if err != nil {
-		return err
+		return
 	}
 	zipSuffix := "_" + strconv.FormatInt(lastUpdate, 10)
 	xrayTempDir, err := getXrayTempDir()
 	if err != nil {
-		return err
+		return
 	}
 
 	if flags.Target != "" && (len(vulnerabilities) > 0 || len(components) > 0) {
 		err = os.MkdirAll(flags.Target, 0777)
 		if errorutils.CheckError(err) != nil {
-			return err
+			return
 		}
 	}
 
 	if len(vulnerabilities) > 0 {
 		log.Info("Downloading vulnerabilities...")
-		err := saveData(xrayTempDir, "vuln", zipSuffix, flags.Target, vulnerabilities)
+		archivePath, err := saveData(xrayTempDir, "vuln", zipSuffix, flags.Target, vulnerabilities, flags.Threads)
 		if err != nil {
-			return err
+			return nil, 0, err
 		}
+		archivesPaths = append(archivesPaths, archivePath)
 	} else {
 		log.Info("There are no new vulnerabilities.")
 	}
 
 	if len(components) > 0 {
 		log.Info("Downloading components...")
-		err := saveData(xrayTempDir, "comp", zipSuffix, flags.Target, components)
+		archivePath, err := saveData(xrayTempDir, "comp", zipSuffix, flags.Target, components, flags.Threads)
 		if err != nil {
-			return err
+			return nil, 0, err
 		}
+		archivesPaths = append(archivesPaths, archivePath)
 	} else {
 		log.Info("There are no new components.")
 	}
-	return nil
+	return
 }
 
-func getURLsToDownloadDBSyncV3(responseBody []byte, isPeriodicUpdate bool) ([]string, error) {
+func getFilesToDownloadDBSyncV3(responseBody []byte, isPeriodicUpdate bool) ([]downloadItem, error) {
 	var onboardingResponse OnboardingResponse
 	var periodicResponse V3PeriodicUpdateResponse
-	var urlsToDownload []string
+	var filesToDownload []downloadItem
 	var err error
 	if isPeriodicUpdate {
 		err = json.Unmarshal(responseBody, &periodicResponse)
@@ -92,10 +112,10 @@ func getURLsToDownloadDBSyncV3(responseBody []byte, isPeriodicUpdate bool) ([]st
 			return nil, errorutils.CheckError(err)
 		}
 		for _, packageUrl := range periodicResponse.Update {
-			urlsToDownload = append(urlsToDownload, packageUrl.DownloadUrl)
+			filesToDownload = append(filesToDownload, downloadItem{Url: packageUrl.DownloadUrl, Sha256: packageUrl.Sha256})
 		}
 		for _, packageUrl := range periodicResponse.Deletion {
-			urlsToDownload = append(urlsToDownload, packageUrl.DownloadUrl)
+			filesToDownload = append(filesToDownload, downloadItem{Url: packageUrl.DownloadUrl, Sha256: packageUrl.Sha256})
 		}
 	} else {
 		err = json.Unmarshal(responseBody, &onboardingResponse)
@@ -103,10 +123,10 @@ func getURLsToDownloadDBSyncV3(responseBody []byte, isPeriodicUpdate bool) ([]st
 			return nil, errorutils.CheckError(err)
 		}
 		for _, packageUrl := range onboardingResponse {
-			urlsToDownload = append(urlsToDownload, packageUrl.DownloadUrl)
+			filesToDownload = append(filesToDownload, downloadItem{Url: packageUrl.DownloadUrl, Sha256: packageUrl.Sha256})
 		}
 	}
-	return urlsToDownload, nil
+	return filesToDownload, nil
 }
 
 func createV3MetadataFile(state string, body []byte, destFolder string) (err error) {
@@ -125,7 +145,8 @@ func createV3MetadataFile(state string, body []byte, destFolder string) (err err
 	return errorutils.CheckError(err)
 }
 
-func handleDBSyncV3OfflineUpdate(flags *OfflineUpdatesFlags) (err error) {
+// Returns the path of the created archive.
+func handleDBSyncV3OfflineUpdate(flags *OfflineUpdatesFlags) (archivesPaths []string, err error) {
 	url := buildUrlDBSyncV3(flags.IsDBSyncV3PeriodicUpdate)
 	log.Info("Getting updates...")
 	headers := make(map[string]string)
@@ -135,19 +156,19 @@ func handleDBSyncV3OfflineUpdate(flags *OfflineUpdatesFlags) (err error) {
 	}
 	client, err := httpclient.ClientBuilder().SetRetries(3).Build()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	resp, body, _, err := client.SendGet(url, false, httpClientDetails, "")
 	if errorutils.CheckError(err) != nil {
-		return err
+		return nil, err
 	}
 	if err = errorutils.CheckResponseStatusWithBody(resp, body, http.StatusOK); err != nil {
-		return err
+		return nil, err
 	}
 
-	urlsToDownload, err := getURLsToDownloadDBSyncV3(body, flags.IsDBSyncV3PeriodicUpdate)
+	filesToDownload, err := getFilesToDownloadDBSyncV3(body, flags.IsDBSyncV3PeriodicUpdate)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var state string
@@ -158,33 +179,31 @@ func handleDBSyncV3OfflineUpdate(flags *OfflineUpdatesFlags) (err error) {
 	}
 	xrayTempDir, err := getXrayTempDir()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	dataDir, err := ioutil.TempDir(xrayTempDir, "xray_downloaded_data")
+	// The download directory is kept if the download fails, so that the next download continues from where this one stopped
+	downloadDir := filepath.Join(xrayTempDir, "xray_downloaded_data_"+state)
+	dataDir, err := downloadData(filesToDownload, downloadDir, createXrayFileNameFromUrlV3, flags.Threads)
 	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := fileutils.RemoveTempDir(dataDir); err == nil {
-			err = cerr
-		}
-	}()
-	err = downloadData(urlsToDownload, dataDir, createXrayFileNameFromUrlV3)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = createV3MetadataFile(state, body, dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	packageName := "xray_update_package" + "_" + state
-	err = createZipArchive(dataDir, flags.Target, packageName, "")
+	zipSuffix := ""
+	if flags.Incremental {
+		// Each export gets an archive of its own, so that the archives of former exports aren't overridden
+		zipSuffix = "_" + strconv.FormatInt(time.Now().UnixMilli(), 10)
+	}
+	archivePath, err := createZipArchive(dataDir, flags.Target, packageName, zipSuffix)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return []string{archivePath}, fileutils.RemoveTempDir(downloadDir)
 }
 
 func buildUrlDBSyncV3(isPeriodic bool) string {
@@ -255,63 +274,33 @@ func getXrayTempDir() (string, error) {
 	return xrayDir, nil
 }
 
-func downloadData(urlsList []string, dataDir string, fileNameFromUrlFunc func(string) (string, error)) error {
-	//
-	for _, url := range urlsList {
-		fileName, err := fileNameFromUrlFunc(url)
-		if err != nil {
-			return err
-		}
-		log.Info(fmt.Sprintf("Downloading updates package from %s", url))
-		client, err := httpclient.ClientBuilder().SetRetries(3).Build()
-		if err != nil {
-			log.Error(fmt.Sprintf("Couldn't download from %s", url))
-			return err
-		}
-
-		details := &httpclient.DownloadFileDetails{
-			FileName:      fileName,
-			DownloadPath:  url,
-			LocalPath:     dataDir,
-			LocalFileName: fileName}
-		_, err = client.DownloadFile(details, "", httputils.HttpClientDetails{}, false)
-		if err != nil {
-			return errorutils.CheckErrorf("Couldn't download from %s. Error: %s", url, err.Error())
-		}
-	}
-	log.Info("Download completed.")
-	return nil
-}
-
-func createZipArchive(dataDir, targetPath, filesPrefix, zipSuffix string) error {
+func createZipArchive(dataDir, targetPath, filesPrefix, zipSuffix string) (string, error) {
 	log.Info("Zipping files.")
-	err := fileutils.ZipFolderFiles(dataDir, filepath.Join(targetPath, filesPrefix+zipSuffix+".zip"))
+	zipPath := filepath.Join(targetPath, filesPrefix+zipSuffix+".zip")
+	err := fileutils.ZipFolderFiles(dataDir, zipPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	log.Info("Done zipping files.")
-	return nil
+	return zipPath, nil
 }
 
-func saveData(xrayTmpDir, filesPrefix, zipSuffix, targetPath string, urlsList []string) (err error) {
-	dataDir, err := ioutil.TempDir(xrayTmpDir, filesPrefix)
-	if err != nil {
-		return err
+func saveData(xrayTmpDir, filesPrefix, zipSuffix, targetPath string, urlsList []string, threads int) (archivePath string, err error) {
+	var filesToDownload []downloadItem
+	for _, url := range urlsList {
+		filesToDownload = append(filesToDownload, downloadItem{Url: url})
 	}
-	defer func() {
-		if cerr := fileutils.RemoveTempDir(dataDir); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
-	err = downloadData(urlsList, dataDir, createXrayFileNameFromUrl)
+	// The download directory is kept if the download fails, so that the next download continues from where this one stopped
+	downloadDir := filepath.Join(xrayTmpDir, "xray_downloaded_data_"+filesPrefix)
+	dataDir, err := downloadData(filesToDownload, downloadDir, createXrayFileNameFromUrl, threads)
 	if err != nil {
-		return err
+		return
 	}
-	err = createZipArchive(dataDir, targetPath, filesPrefix, zipSuffix)
+	archivePath, err = createZipArchive(dataDir, targetPath, filesPrefix, zipSuffix)
 	if err != nil {
-		return err
+		return
 	}
-	return nil
+	return archivePath, fileutils.RemoveTempDir(downloadDir)
 }
 
 func getUrlSections(url string) []string {
@@ -388,6 +377,13 @@ type OfflineUpdatesFlags struct {
 	Target                   string
 	IsDBSyncV3               bool
 	IsDBSyncV3PeriodicUpdate bool
+	// The number of updates packages downloaded concurrently. DefaultDownloadThreads is used if not set.
+	Threads int
+	// If true, the next update is computed from the state of the former exports, which is saved to StateFile:
+	// DBSync V1 updates start where the last update ended, and DBSync V3 periodic updates follow the onboarding.
+	Incremental bool
+	// The state file of the incremental updates. The file in the JFrog home directory is used if not set.
+	StateFile string
 }
 
 type FilesList struct {
@@ -398,6 +394,8 @@ type FilesList struct {
 type V3UpdateResponseItem struct {
 	DownloadUrl string `json:"download_url"`
 	Timestamp   int64  `json:"timestamp"`
+	// Optional. If provided, the downloaded package is verified against it.
+	Sha256 string `json:"sha256,omitempty"`
 }
 
 type V3PeriodicUpdateResponse struct {
@@ -36,7 +36,7 @@ var periodicUpdateResponseSection = "\"update\":" + periodicUpdateResponse
 var periodicDeleteResponseSection = "\"deletion\":" + periodicDeletionResponse
 
 var periodicResponse = "{" + periodicUpdateResponseSection + "," + periodicDeleteResponseSection + "}"
-var onboardingResponse = "[{\"download_url\":\"some_url_to_package_onboard\",\"timestamp\":1234}]"
+var onboardingResponse = "[{\"download_url\":\"some_url_to_package_onboard\",\"timestamp\":1234,\"sha256\":\"some_checksum\"}]"
 
 func TestDBSyncV3BuildURL(t *testing.T) {
 	tests := []struct {
@@ -51,22 +51,22 @@ func TestDBSyncV3BuildURL(t *testing.T) {
 	}
 }
 
-func TestDBSyncV3getURLsToDownload(t *testing.T) {
+func TestDBSyncV3getFilesToDownload(t *testing.T) {
 	tests := []struct {
 		serverResponse []byte
 		isPeriodic     bool
-		expected       []string
+		expected       []downloadItem
 	}{
-		{[]byte(periodicResponse), true, []string{"some_url_to_package_update", "some_url_to_package_delete"}},
-		{[]byte(onboardingResponse), false, []string{"some_url_to_package_onboard"}},
+		{[]byte(periodicResponse), true, []downloadItem{{Url: "some_url_to_package_update"}, {Url: "some_url_to_package_delete"}}},
+		{[]byte(onboardingResponse), false, []downloadItem{{Url: "some_url_to_package_onboard", Sha256: "some_checksum"}}},
 	}
 
 	for _, test := range tests {
-		urls, err := getURLsToDownloadDBSyncV3(test.serverResponse, test.isPeriodic)
+		files, err := getFilesToDownloadDBSyncV3(test.serverResponse, test.isPeriodic)
 		if err != nil {
 			t.Error(err)
 		}
-		assert.Equal(t, urls, test.expected)
+		assert.Equal(t, files, test.expected)
 	}
 }
 
@@ -0,0 +1,154 @@
+package offlineupdate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	offlineUpdateStateFileName = "offline-update-state.json"
+	dbSyncV1                   = "v1"
+	dbSyncV3                   = "v3"
+)
+
+// The state of the incremental offline updates, which is saved between the exports.
+type OfflineUpdateState struct {
+	// The time of the data included in the last DBSync V1 update, as returned by Xray, in milliseconds since epoch.
+	// The next update starts from it, so that the updates don't depend on the local clock.
+	LastV1Update int64 `json:"lastV1Update,omitempty"`
+	// True if the DBSync V3 onboarding update was exported, so the next updates are periodic.
+	V3Onboarded bool                  `json:"v3Onboarded,omitempty"`
+	History     []OfflineUpdateExport `json:"history"`
+	path        string
+}
+
+// A successful export of an offline update.
+type OfflineUpdateExport struct {
+	Time          time.Time `json:"time"`
+	DBSyncVersion string    `json:"dbSyncVersion"`
+	// 'onboarding' or 'periodic'.
+	Type string `json:"type"`
+	// The window of a DBSync V1 periodic update, in milliseconds since epoch.
+	From int64 `json:"from,omitempty"`
+	To   int64 `json:"to,omitempty"`
+	// The time of the data included in a DBSync V1 update, as returned by Xray, in milliseconds since epoch.
+	LastUpdate int64                  `json:"lastUpdate,omitempty"`
+	Archives   []OfflineUpdateArchive `json:"archives"`
+}
+
+type OfflineUpdateArchive struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// LoadOfflineUpdateState reads the state of the incremental offline updates from stateFile, or from the default state file in the JFrog home
+// directory if stateFile is empty. If the state file doesn't exist, an empty state is returned.
+func LoadOfflineUpdateState(stateFile string) (state *OfflineUpdateState, err error) {
+	if stateFile == "" {
+		if stateFile, err = getDefaultStateFilePath(); err != nil {
+			return
+		}
+	}
+	state = &OfflineUpdateState{path: stateFile}
+	content, err := os.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		log.Debug("The offline update state file " + stateFile + " doesn't exist. Starting from an empty state.")
+		return state, nil
+	}
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, errorutils.CheckErrorf("failed to read the offline update state file %s: %s", stateFile, err.Error())
+	}
+	return
+}
+
+func getDefaultStateFilePath() (string, error) {
+	xrayDir, err := coreutils.CreateDirInJfrogHome("xray")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(xrayDir, offlineUpdateStateFileName), nil
+}
+
+// Sets the update to export next in flags, according to the exports done so far.
+func (state *OfflineUpdateState) applyNextUpdate(flags *OfflineUpdatesFlags, exportTime time.Time) error {
+	if flags.IsDBSyncV3 {
+		flags.IsDBSyncV3PeriodicUpdate = state.V3Onboarded
+		return nil
+	}
+	if flags.From > 0 || flags.To > 0 {
+		return errorutils.CheckErrorf("the dates range of incremental offline updates is computed from the former updates, and can't be provided")
+	}
+	// The first update is an onboarding update, which includes all the data up to now
+	if state.LastV1Update > 0 {
+		flags.From = state.LastV1Update
+		flags.To = exportTime.UnixMilli()
+	}
+	return nil
+}
+
+// Adds the export of the update in flags to the history, and saves the state.
+// lastUpdate is the time of the data included in a DBSync V1 update, as returned by Xray.
+func (state *OfflineUpdateState) addExport(flags *OfflineUpdatesFlags, exportTime time.Time, lastUpdate int64, archivesPaths []string) error {
+	export := OfflineUpdateExport{Time: exportTime, DBSyncVersion: dbSyncV1, Type: onboardingState}
+	if flags.IsDBSyncV3 {
+		export.DBSyncVersion = dbSyncV3
+		if flags.IsDBSyncV3PeriodicUpdate {
+			export.Type = periodicState
+		}
+		state.V3Onboarded = true
+	} else {
+		if flags.From > 0 && flags.To > 0 {
+			export.Type = periodicState
+			export.From, export.To = flags.From, flags.To
+		}
+		export.LastUpdate = lastUpdate
+		if lastUpdate > 0 {
+			state.LastV1Update = lastUpdate
+		} else {
+			log.Warn("Xray didn't return the time of the update's data. The next update will start from the same time as this one.")
+		}
+	}
+	for _, archivePath := range archivesPaths {
+		absPath, err := filepath.Abs(archivePath)
+		if err != nil {
+			return errorutils.CheckError(err)
+		}
+		fileInfo, err := os.Stat(absPath)
+		if err != nil {
+			return errorutils.CheckError(err)
+		}
+		sha256, err := getFileSha256(absPath)
+		if err != nil {
+			return err
+		}
+		export.Archives = append(export.Archives, OfflineUpdateArchive{Path: absPath, Size: fileInfo.Size(), Sha256: sha256})
+	}
+	state.History = append(state.History, export)
+	return state.save()
+}
+
+// The state is written to a temporary file first, so that an interrupted save doesn't corrupt the state.
+func (state *OfflineUpdateState) save() error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(state.path), 0777); err != nil {
+		return errorutils.CheckError(err)
+	}
+	tempPath := state.path + ".tmp"
+	if err = os.WriteFile(tempPath, content, 0600); err != nil {
+		return errorutils.CheckError(err)
+	}
+	return errorutils.CheckError(os.Rename(tempPath, state.path))
+}
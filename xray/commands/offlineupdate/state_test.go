@@ -0,0 +1,131 @@
+package offlineupdate
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestZip(t *testing.T, zipPath string) {
+	zipFile, err := os.Create(zipPath)
+	assert.NoError(t, err)
+	zipWriter := zip.NewWriter(zipFile)
+	fileWriter, err := zipWriter.Create("update.json")
+	assert.NoError(t, err)
+	_, err = fileWriter.Write([]byte(periodicResponse))
+	assert.NoError(t, err)
+	assert.NoError(t, zipWriter.Close())
+	assert.NoError(t, zipFile.Close())
+}
+
+func TestIncrementalDBSyncV1Updates(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state", offlineUpdateStateFileName)
+	state, err := LoadOfflineUpdateState(stateFile)
+	assert.NoError(t, err)
+
+	// The first update is an onboarding update
+	onboardingTime := time.UnixMilli(1000).UTC()
+	flags := &OfflineUpdatesFlags{Incremental: true}
+	assert.NoError(t, state.applyNextUpdate(flags, onboardingTime))
+	assert.Zero(t, flags.From)
+	assert.Zero(t, flags.To)
+	archivePath := filepath.Join(t.TempDir(), "vuln_1000.zip")
+	createTestZip(t, archivePath)
+	// The local clock is ahead of Xray's, so the time of the data returned by Xray is earlier than the export time
+	assert.NoError(t, state.addExport(flags, onboardingTime, 800, []string{archivePath}))
+
+	// The next updates start from the time of the data returned by Xray for the former ones, rather than from the local time of their export
+	for _, update := range []struct {
+		exportTime time.Time
+		lastUpdate int64
+	}{{time.UnixMilli(5000).UTC(), 4700}, {time.UnixMilli(9000).UTC(), 8600}} {
+		state, err = LoadOfflineUpdateState(stateFile)
+		assert.NoError(t, err)
+		flags = &OfflineUpdatesFlags{Incremental: true}
+		previousLastUpdate := state.LastV1Update
+		assert.NoError(t, state.applyNextUpdate(flags, update.exportTime))
+		assert.Equal(t, previousLastUpdate, flags.From)
+		assert.Equal(t, update.exportTime.UnixMilli(), flags.To)
+		// No new data was found
+		assert.NoError(t, state.addExport(flags, update.exportTime, update.lastUpdate, nil))
+	}
+
+	state, err = LoadOfflineUpdateState(stateFile)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8600), state.LastV1Update)
+	if assert.Len(t, state.History, 3) {
+		assert.Equal(t, onboardingState, state.History[0].Type)
+		assert.Equal(t, int64(800), state.History[0].LastUpdate)
+		if assert.Len(t, state.History[0].Archives, 1) {
+			assert.Equal(t, archivePath, state.History[0].Archives[0].Path)
+			assert.NotEmpty(t, state.History[0].Archives[0].Sha256)
+		}
+		assert.Equal(t, OfflineUpdateExport{Time: time.UnixMilli(5000).UTC(), DBSyncVersion: dbSyncV1, Type: periodicState, From: 800, To: 5000, LastUpdate: 4700}, state.History[1])
+		assert.Equal(t, OfflineUpdateExport{Time: time.UnixMilli(9000).UTC(), DBSyncVersion: dbSyncV1, Type: periodicState, From: 4700, To: 9000, LastUpdate: 8600}, state.History[2])
+	}
+
+	// If Xray doesn't return the time of the data, the next update starts from the same time
+	flags = &OfflineUpdatesFlags{Incremental: true}
+	assert.NoError(t, state.applyNextUpdate(flags, time.UnixMilli(12000)))
+	assert.NoError(t, state.addExport(flags, time.UnixMilli(12000), 0, nil))
+	assert.Equal(t, int64(8600), state.LastV1Update)
+
+	// The dates range can't be provided
+	flags = &OfflineUpdatesFlags{Incremental: true, From: 1000, To: 2000}
+	assert.ErrorContains(t, state.applyNextUpdate(flags, time.Now()), "can't be provided")
+}
+
+func TestIncrementalDBSyncV3Updates(t *testing.T) {
+	state, err := LoadOfflineUpdateState(filepath.Join(t.TempDir(), offlineUpdateStateFileName))
+	assert.NoError(t, err)
+	flags := &OfflineUpdatesFlags{Incremental: true, IsDBSyncV3: true, IsDBSyncV3PeriodicUpdate: true}
+	assert.NoError(t, state.applyNextUpdate(flags, time.Now()))
+	// The onboarding update is exported first
+	assert.False(t, flags.IsDBSyncV3PeriodicUpdate)
+	assert.NoError(t, state.addExport(flags, time.Now(), 0, nil))
+
+	flags = &OfflineUpdatesFlags{Incremental: true, IsDBSyncV3: true}
+	assert.NoError(t, state.applyNextUpdate(flags, time.Now()))
+	assert.True(t, flags.IsDBSyncV3PeriodicUpdate)
+	assert.NoError(t, state.addExport(flags, time.Now(), 0, nil))
+	if assert.Len(t, state.History, 2) {
+		assert.Equal(t, onboardingState, state.History[0].Type)
+		assert.Equal(t, periodicState, state.History[1].Type)
+	}
+}
+
+func TestOfflineUpdateHistoryVerify(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), offlineUpdateStateFileName)
+	state, err := LoadOfflineUpdateState(stateFile)
+	assert.NoError(t, err)
+	archivesDir := t.TempDir()
+	var archivesPaths []string
+	for _, name := range []string{"ok.zip", "missing.zip", "modified.zip"} {
+		archivePath := filepath.Join(archivesDir, name)
+		createTestZip(t, archivePath)
+		archivesPaths = append(archivesPaths, archivePath)
+	}
+	assert.NoError(t, state.addExport(&OfflineUpdatesFlags{}, time.Now(), 1000, archivesPaths))
+
+	flags := &OfflineUpdateHistoryFlags{StateFile: stateFile, Verify: true}
+	assert.NoError(t, OfflineUpdateHistory(flags))
+
+	assert.NoError(t, os.Remove(archivesPaths[1]))
+	assert.NoError(t, os.WriteFile(archivesPaths[2], []byte("modified"), 0644))
+	err = OfflineUpdateHistory(flags)
+	assert.ErrorContains(t, err, "2 of the exported archives failed the verification")
+	assert.ErrorContains(t, err, archivesPaths[1]+": "+archiveStatusMissing)
+	assert.ErrorContains(t, err, archivesPaths[2]+": "+archiveStatusChecksumMismatch)
+
+	// Without verification, only the history is printed
+	flags.Verify = false
+	assert.NoError(t, OfflineUpdateHistory(flags))
+
+	status, err := verifyArchive(OfflineUpdateArchive{Path: archivesPaths[2], Sha256: getSha256([]byte("modified"))})
+	assert.NoError(t, err)
+	assert.Equal(t, archiveStatusCorrupted, status)
+}
@@ -24,6 +24,7 @@ type BuildScanCommand struct {
 	failBuild              bool
 	printExtendedTable     bool
 	rescan                 bool
+	severityThresholds     *xrutils.SeverityThresholds
 }
 
 func NewBuildScanCommand() *BuildScanCommand {
@@ -69,8 +70,18 @@ func (bsc *BuildScanCommand) SetRescan(rescan bool) *BuildScanCommand {
 	return bsc
 }
 
+// SetSeverityThresholds sets thresholds to fail the build by, according to the severity and CVSS score of the issues found.
+// Vulnerabilities are checked only if they are included in the scan, otherwise only security violations are checked.
+func (bsc *BuildScanCommand) SetSeverityThresholds(thresholds *xrutils.SeverityThresholds) *BuildScanCommand {
+	bsc.severityThresholds = thresholds
+	return bsc
+}
+
 // Scan published builds with Xray
 func (bsc *BuildScanCommand) Run() (err error) {
+	if err = bsc.severityThresholds.Validate(); err != nil {
+		return err
+	}
 	xrayManager, xrayVersion, err := commands.CreateXrayServiceManagerAndGetVersion(bsc.serverDetails)
 	if err != nil {
 		return err
@@ -100,21 +111,26 @@ func (bsc *BuildScanCommand) Run() (err error) {
 		Rescan:      bsc.rescan,
 	}
 
-	isFailBuildResponse, err := bsc.runBuildScanAndPrintResults(xrayManager, params)
+	isFailBuildResponse, exceedThresholds, err := bsc.runBuildScanAndPrintResults(xrayManager, params)
 	if err != nil {
 		return err
 	}
-	// If failBuild flag is true and also got fail build response from Xray
-	if bsc.failBuild && isFailBuildResponse {
-		return xrutils.NewFailBuildError()
+	if bsc.failBuild {
+		// Got fail build response from Xray
+		if isFailBuildResponse {
+			return xrutils.NewFailBuildError()
+		}
+		if exceedThresholds {
+			return xrutils.NewThresholdsFailBuildError(bsc.severityThresholds)
+		}
 	}
 	return
 }
 
-func (bsc *BuildScanCommand) runBuildScanAndPrintResults(xrayManager *xray.XrayServicesManager, params services.XrayBuildParams) (isFailBuildResponse bool, err error) {
+func (bsc *BuildScanCommand) runBuildScanAndPrintResults(xrayManager *xray.XrayServicesManager, params services.XrayBuildParams) (isFailBuildResponse, exceedThresholds bool, err error) {
 	buildScanResults, noFailBuildPolicy, err := xrayManager.BuildScan(params, bsc.includeVulnerabilities)
 	if err != nil {
-		return false, err
+		return false, false, err
 	}
 	log.Info("The scan data is available at: " + buildScanResults.MoreDetailsUrl)
 	isFailBuildResponse = buildScanResults.FailBuild
@@ -124,6 +140,7 @@ func (bsc *BuildScanCommand) runBuildScanAndPrintResults(xrayManager *xray.XrayS
 		Vulnerabilities: buildScanResults.Vulnerabilities,
 		XrayDataUrl:     buildScanResults.MoreDetailsUrl,
 	}}
+	exceedThresholds = xrutils.CheckIfExceedThresholds(scanResponse, bsc.severityThresholds)
 
 	if bsc.outputFormat == xrutils.Json || bsc.outputFormat == xrutils.SimpleJson {
 		// Print the violations and/or vulnerabilities as part of one JSON.
@@ -135,13 +152,13 @@ func (bsc *BuildScanCommand) runBuildScanAndPrintResults(xrayManager *xray.XrayS
 		if !noFailBuildPolicy {
 			err = xrutils.PrintScanResults(scanResponse, nil, bsc.outputFormat, false, false, false, bsc.printExtendedTable)
 			if err != nil {
-				return false, err
+				return false, false, err
 			}
 		}
 		if bsc.includeVulnerabilities {
 			err = xrutils.PrintScanResults(scanResponse, nil, bsc.outputFormat, true, false, false, bsc.printExtendedTable)
 			if err != nil {
-				return false, err
+				return false, false, err
 			}
 		}
 	}
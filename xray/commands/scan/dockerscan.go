@@ -22,6 +22,8 @@ const (
 type DockerScanCommand struct {
 	ScanCommand
 	imageTag string
+	// An existing image archive or OCI image layout to scan, instead of saving the image from the Docker daemon.
+	imagePath string
 }
 
 func NewDockerScanCommand() *DockerScanCommand {
@@ -33,6 +35,13 @@ func (dsc *DockerScanCommand) SetImageTag(imageTag string) *DockerScanCommand {
 	return dsc
 }
 
+// SetImagePath sets a 'docker save' archive, an OCI image layout directory or an oci-archive file to scan.
+// The image is scanned without running Docker. Each of the platforms of multi-platform images is scanned separately.
+func (dsc *DockerScanCommand) SetImagePath(imagePath string) *DockerScanCommand {
+	dsc.imagePath = imagePath
+	return dsc
+}
+
 func (dsc *DockerScanCommand) Run() (err error) {
 	// Validate Xray minimum version
 	_, xrayVersion, err := commands.CreateXrayServiceManagerAndGetVersion(dsc.ScanCommand.serverDetails)
@@ -55,22 +64,17 @@ func (dsc *DockerScanCommand) Run() (err error) {
 		}
 	}()
 
-	// Run the 'docker save' command, to create tar file from the docker image, and pass it to the indexer-app
-	if dsc.progress != nil {
-		dsc.progress.SetHeadlineMsg("Creating image archive 📦")
-	}
-	log.Info("Creating image archive...")
-	imageTarPath := filepath.Join(tempDirPath, "image.tar")
-	dockerSaveCmd := exec.Command("docker", "save", dsc.imageTag, "-o", imageTarPath)
-	var stderr bytes.Buffer
-	dockerSaveCmd.Stderr = &stderr
-	err = dockerSaveCmd.Run()
+	imageArchivesPaths, err := dsc.prepareImageArchives(tempDirPath)
 	if err != nil {
-		return fmt.Errorf("failed running command: '%s' with error: %s - %s", strings.Join(dockerSaveCmd.Args, " "), err.Error(), stderr.String())
+		return err
 	}
 
-	// Perform scan on image.tar
-	dsc.SetSpec(spec.NewBuilder().Pattern(imageTarPath).BuildSpec()).SetThreads(1)
+	// Perform scan on the image archives
+	var specFiles []spec.File
+	for _, imageArchivePath := range imageArchivesPaths {
+		specFiles = append(specFiles, spec.NewBuilder().Pattern(imageArchivePath).BuildSpec().Files...)
+	}
+	dsc.SetSpec(&spec.SpecFiles{Files: specFiles}).SetThreads(1)
 	err = dsc.setCredentialEnvsForIndexerApp()
 	if err != nil {
 		return errorutils.CheckError(err)
@@ -84,6 +88,30 @@ func (dsc *DockerScanCommand) Run() (err error) {
 	return dsc.ScanCommand.Run()
 }
 
+// Returns the paths of the 'docker save' archives to scan. If no image path was set, the image is saved from the Docker daemon to tempDirPath.
+func (dsc *DockerScanCommand) prepareImageArchives(tempDirPath string) ([]string, error) {
+	if dsc.imagePath != "" {
+		if dsc.progress != nil {
+			dsc.progress.SetHeadlineMsg("Preparing image archive 📦")
+		}
+		return prepareImageArchives(dsc.imagePath, dsc.imageTag, tempDirPath)
+	}
+
+	// Run the 'docker save' command, to create tar file from the docker image, and pass it to the indexer-app
+	if dsc.progress != nil {
+		dsc.progress.SetHeadlineMsg("Creating image archive 📦")
+	}
+	log.Info("Creating image archive...")
+	imageTarPath := filepath.Join(tempDirPath, "image.tar")
+	dockerSaveCmd := exec.Command("docker", "save", dsc.imageTag, "-o", imageTarPath)
+	var stderr bytes.Buffer
+	dockerSaveCmd.Stderr = &stderr
+	if err := dockerSaveCmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed running command: '%s' with error: %s - %s", strings.Join(dockerSaveCmd.Args, " "), err.Error(), stderr.String())
+	}
+	return []string{imageTarPath}, nil
+}
+
 // When indexing RPM files inside the docker container, the indexer-app needs to connect to the Xray Server.
 // This is because RPM indexing is performed on the server side. This method therefore sets the Xray credentials as env vars to be read and used by the indexer-app.
 func (dsc *DockerScanCommand) setCredentialEnvsForIndexerApp() error {
@@ -0,0 +1,397 @@
+package scan
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	dockerArchiveManifestFileName = "manifest.json"
+	ociLayoutFileName             = "oci-layout"
+	ociIndexFileName              = "index.json"
+
+	ociIndexMediaType           = "application/vnd.oci.image.index.v1+json"
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	// The annotations of the manifests of the build attestations that BuildKit adds to multi-platform images.
+	attestationReferenceTypeAnnotation = "vnd.docker.reference.type"
+	attestationManifestReferenceType   = "attestation-manifest"
+	imageNameAnnotation                = "io.containerd.image.name"
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Platform    *ociPlatform      `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// An image manifest, or an image index (manifest list) of a multi-platform image.
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// An image in the manifest.json file of a 'docker save' archive.
+type dockerArchiveManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// Returns the paths of 'docker save' archives of the image in imagePath, which may be a 'docker save' archive, an OCI image layout directory or
+// an oci-archive file (a tar archive of an OCI image layout).
+// OCI images are converted to 'docker save' archives in tempDirPath, one for each of the platforms of multi-platform images.
+// If imageTag isn't empty, it's used as the tag of the converted images.
+func prepareImageArchives(imagePath, imageTag, tempDirPath string) (archivesPaths []string, err error) {
+	fileInfo, err := os.Stat(imagePath)
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	layoutDir := imagePath
+	if !fileInfo.IsDir() {
+		var isDockerArchive, isOciArchive bool
+		if isDockerArchive, isOciArchive, err = getImageArchiveType(imagePath); err != nil {
+			return
+		}
+		if isDockerArchive {
+			return []string{imagePath}, nil
+		}
+		if !isOciArchive {
+			return nil, errorutils.CheckErrorf("%s is not a 'docker save' archive or an oci-archive", imagePath)
+		}
+		log.Info("Extracting oci-archive...")
+		layoutDir = filepath.Join(tempDirPath, "oci-layout")
+		if err = extractTar(imagePath, layoutDir); err != nil {
+			return
+		}
+	}
+	return convertOciLayout(layoutDir, imageTag, tempDirPath)
+}
+
+// Archives created by 'docker save' have a manifest.json file. Recent Docker versions also add the files of an OCI image layout to them.
+func getImageArchiveType(archivePath string) (isDockerArchive, isOciArchive bool, err error) {
+	archive, err := os.Open(archivePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	defer func() {
+		e := archive.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	tarReader := tar.NewReader(archive)
+	for {
+		header, e := tarReader.Next()
+		if e == io.EOF {
+			return
+		}
+		if e != nil {
+			return false, false, errorutils.CheckErrorf("failed to read %s: %s", archivePath, e.Error())
+		}
+		switch path.Clean(header.Name) {
+		case dockerArchiveManifestFileName:
+			return true, false, nil
+		case ociLayoutFileName:
+			isOciArchive = true
+		}
+	}
+}
+
+// Extracts the regular files of the tar archive to targetDir. Entries with paths outside targetDir are rejected.
+func extractTar(archivePath, targetDir string) (err error) {
+	archive, err := os.Open(archivePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	defer func() {
+		e := archive.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	tarReader := tar.NewReader(archive)
+	for {
+		header, e := tarReader.Next()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return errorutils.CheckErrorf("failed to read %s: %s", archivePath, e.Error())
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		entryPath := path.Clean(header.Name)
+		if path.IsAbs(entryPath) || entryPath == ".." || strings.HasPrefix(entryPath, "../") {
+			return errorutils.CheckErrorf("the entry %s of %s points outside the archive", header.Name, archivePath)
+		}
+		targetPath := filepath.Join(targetDir, filepath.FromSlash(entryPath))
+		if err = os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+			return errorutils.CheckError(err)
+		}
+		if err = writeFile(targetPath, tarReader); err != nil {
+			return
+		}
+	}
+}
+
+// Converts each of the platform images of the OCI image layout in layoutDir to a 'docker save' archive in targetDir.
+func convertOciLayout(layoutDir, imageTag, targetDir string) (archivesPaths []string, err error) {
+	if _, err = os.Stat(filepath.Join(layoutDir, ociLayoutFileName)); err != nil {
+		return nil, errorutils.CheckErrorf("%s is not an OCI image layout: %s", layoutDir, err.Error())
+	}
+	var index ociManifest
+	if err = coreutils.ReadJsonFile(filepath.Join(layoutDir, ociIndexFileName), &index); err != nil {
+		return
+	}
+	manifests, err := collectImageManifests(layoutDir, index.Manifests)
+	if err != nil {
+		return
+	}
+	if len(manifests) == 0 {
+		return nil, errorutils.CheckErrorf("couldn't find any image in the OCI image layout %s", layoutDir)
+	}
+	usedNames := make(map[string]bool)
+	for i, manifest := range manifests {
+		// The archives of multi-platform images are named after their platforms, so that they can be told apart in the results
+		archiveName := "image.tar"
+		if len(manifests) > 1 {
+			archiveName = fmt.Sprintf("image-%d.tar", i+1)
+			if platform := manifest.Platform; platform != nil && !usedNames[getPlatformArchiveName(platform)] {
+				archiveName = getPlatformArchiveName(platform)
+			}
+		}
+		usedNames[archiveName] = true
+		repoTag := imageTag
+		if repoTag == "" {
+			repoTag = manifest.Annotations[imageNameAnnotation]
+		}
+		archivePath := filepath.Join(targetDir, archiveName)
+		log.Info(fmt.Sprintf("Converting the image %s to a 'docker save' archive...", manifest.Digest))
+		if err = writeDockerArchive(layoutDir, manifest, repoTag, archivePath, targetDir); err != nil {
+			return
+		}
+		archivesPaths = append(archivesPaths, archivePath)
+	}
+	return
+}
+
+// Returns the descriptors of the image manifests, replacing image indexes (of multi-platform images) with the manifests of their platforms.
+// The manifests of build attestations aren't images, so they're skipped.
+func collectImageManifests(layoutDir string, descriptors []ociDescriptor) (manifests []ociDescriptor, err error) {
+	for _, descriptor := range descriptors {
+		if descriptor.MediaType == ociIndexMediaType || descriptor.MediaType == dockerManifestListMediaType {
+			var index ociManifest
+			if err = readBlob(layoutDir, descriptor.Digest, &index); err != nil {
+				return
+			}
+			var platformManifests []ociDescriptor
+			if platformManifests, err = collectImageManifests(layoutDir, index.Manifests); err != nil {
+				return
+			}
+			// The image name is annotated on the index
+			if imageName := descriptor.Annotations[imageNameAnnotation]; imageName != "" {
+				for i := range platformManifests {
+					if platformManifests[i].Annotations == nil {
+						platformManifests[i].Annotations = make(map[string]string)
+					}
+					if platformManifests[i].Annotations[imageNameAnnotation] == "" {
+						platformManifests[i].Annotations[imageNameAnnotation] = imageName
+					}
+				}
+			}
+			manifests = append(manifests, platformManifests...)
+			continue
+		}
+		if descriptor.Annotations[attestationReferenceTypeAnnotation] == attestationManifestReferenceType {
+			continue
+		}
+		manifests = append(manifests, descriptor)
+	}
+	return
+}
+
+// Writes a 'docker save' archive of the image with the given manifest to archivePath.
+// Compressed layers are decompressed, because 'docker save' archives contain uncompressed layers. tempDirPath is used to store the decompressed layers.
+func writeDockerArchive(layoutDir string, manifestDescriptor ociDescriptor, repoTag, archivePath, tempDirPath string) (err error) {
+	var manifest ociManifest
+	if err = readBlob(layoutDir, manifestDescriptor.Digest, &manifest); err != nil {
+		return
+	}
+	archive, err := os.Create(archivePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	defer func() {
+		e := archive.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	tarWriter := tar.NewWriter(archive)
+	defer func() {
+		e := tarWriter.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+
+	configPath, err := getBlobPath(layoutDir, manifest.Config.Digest)
+	if err != nil {
+		return
+	}
+	configHex := getDigestHex(manifest.Config.Digest)
+	archiveManifest := dockerArchiveManifest{Config: configHex + ".json"}
+	if repoTag != "" {
+		archiveManifest.RepoTags = []string{repoTag}
+	}
+	if err = addFileToTar(tarWriter, configPath, archiveManifest.Config); err != nil {
+		return
+	}
+	addedLayers := make(map[string]bool)
+	for _, layer := range manifest.Layers {
+		layerName := getDigestHex(layer.Digest) + "/layer.tar"
+		archiveManifest.Layers = append(archiveManifest.Layers, layerName)
+		if addedLayers[layerName] {
+			continue
+		}
+		addedLayers[layerName] = true
+		var layerPath string
+		if layerPath, err = getUncompressedLayer(layoutDir, layer, tempDirPath); err != nil {
+			return
+		}
+		if err = addFileToTar(tarWriter, layerPath, layerName); err != nil {
+			return
+		}
+	}
+	manifestContent, err := json.Marshal([]dockerArchiveManifest{archiveManifest})
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	if err = tarWriter.WriteHeader(&tar.Header{Name: dockerArchiveManifestFileName, Mode: 0644, Size: int64(len(manifestContent))}); err != nil {
+		return errorutils.CheckError(err)
+	}
+	_, err = tarWriter.Write(manifestContent)
+	return errorutils.CheckError(err)
+}
+
+// Returns the path of the layer's uncompressed tar. Gzip compressed layers are decompressed to tempDirPath.
+func getUncompressedLayer(layoutDir string, layer ociDescriptor, tempDirPath string) (layerPath string, err error) {
+	blobPath, err := getBlobPath(layoutDir, layer.Digest)
+	if err != nil {
+		return
+	}
+	switch {
+	case strings.HasSuffix(layer.MediaType, "+gzip") || strings.HasSuffix(layer.MediaType, ".tar.gzip"):
+	case strings.HasSuffix(layer.MediaType, "+zstd"):
+		return "", errorutils.CheckErrorf("the layer %s is compressed with zstd, which is not supported", layer.Digest)
+	default:
+		return blobPath, nil
+	}
+	blob, err := os.Open(blobPath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	defer func() {
+		e := blob.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	gzipReader, err := gzip.NewReader(blob)
+	if err != nil {
+		return "", errorutils.CheckErrorf("failed to decompress the layer %s: %s", layer.Digest, err.Error())
+	}
+	layerPath = filepath.Join(tempDirPath, getDigestHex(layer.Digest)+".tar")
+	return layerPath, writeFile(layerPath, gzipReader)
+}
+
+func addFileToTar(tarWriter *tar.Writer, filePath, name string) (err error) {
+	file, err := os.Open(filePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	defer func() {
+		e := file.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	fileInfo, err := file.Stat()
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	if err = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: fileInfo.Size()}); err != nil {
+		return errorutils.CheckError(err)
+	}
+	_, err = io.Copy(tarWriter, file)
+	return errorutils.CheckError(err)
+}
+
+func writeFile(filePath string, reader io.Reader) (err error) {
+	file, err := os.Create(filePath)
+	if errorutils.CheckError(err) != nil {
+		return
+	}
+	defer func() {
+		e := file.Close()
+		if err == nil {
+			err = errorutils.CheckError(e)
+		}
+	}()
+	_, err = io.Copy(file, reader)
+	return errorutils.CheckError(err)
+}
+
+// Returns the path of the blob with the given digest, like 'sha256:<hex>', in the OCI image layout.
+func getBlobPath(layoutDir, digest string) (string, error) {
+	algorithm, hex, found := strings.Cut(digest, ":")
+	if !found || algorithm == "" || hex == "" || strings.ContainsAny(digest, `/\.`) {
+		return "", errorutils.CheckErrorf("invalid digest '%s' in the OCI image layout %s", digest, layoutDir)
+	}
+	return filepath.Join(layoutDir, "blobs", algorithm, hex), nil
+}
+
+func getDigestHex(digest string) string {
+	_, hex, _ := strings.Cut(digest, ":")
+	return hex
+}
+
+func readBlob(layoutDir, digest string, target interface{}) error {
+	blobPath, err := getBlobPath(layoutDir, digest)
+	if err != nil {
+		return err
+	}
+	return coreutils.ReadJsonFile(blobPath, target)
+}
+
+// Returns a name like 'image-linux-arm64-v8.tar'.
+func getPlatformArchiveName(platform *ociPlatform) string {
+	nameParts := []string{"image"}
+	for _, part := range []string{platform.OS, platform.Architecture, platform.Variant} {
+		if part != "" {
+			nameParts = append(nameParts, part)
+		}
+	}
+	return strings.Join(nameParts, "-") + ".tar"
+}
@@ -0,0 +1,162 @@
+package scan
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes a blob to the OCI image layout and returns its descriptor.
+func writeTestBlob(t *testing.T, layoutDir, mediaType string, content []byte) ociDescriptor {
+	hash := sha256.Sum256(content)
+	digestHex := hex.EncodeToString(hash[:])
+	blobPath := filepath.Join(layoutDir, "blobs", "sha256", digestHex)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(blobPath), 0755))
+	assert.NoError(t, os.WriteFile(blobPath, content, 0644))
+	return ociDescriptor{MediaType: mediaType, Digest: "sha256:" + digestHex}
+}
+
+func writeTestJsonBlob(t *testing.T, layoutDir, mediaType string, content interface{}) ociDescriptor {
+	jsonContent, err := json.Marshal(content)
+	assert.NoError(t, err)
+	return writeTestBlob(t, layoutDir, mediaType, jsonContent)
+}
+
+func createTestTar(t *testing.T, files map[string][]byte) []byte {
+	var buffer bytes.Buffer
+	tarWriter := tar.NewWriter(&buffer)
+	for name, content := range files {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write(content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	return buffer.Bytes()
+}
+
+func readTestTar(t *testing.T, tarPath string) map[string][]byte {
+	archive, err := os.Open(tarPath)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, archive.Close())
+	}()
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(archive)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		assert.NoError(t, err)
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		files[header.Name] = content
+	}
+}
+
+// Creates an OCI image layout of a multi-platform image with a gzip compressed layer, an uncompressed layer and a build attestation.
+func createTestOciLayout(t *testing.T, layoutDir string) (layer []byte, gzipLayer ociDescriptor) {
+	layer = createTestTar(t, map[string][]byte{"etc/os-release": []byte("ID=alpine")})
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, err := gzipWriter.Write(layer)
+	assert.NoError(t, err)
+	assert.NoError(t, gzipWriter.Close())
+	gzipLayer = writeTestBlob(t, layoutDir, "application/vnd.oci.image.layer.v1.tar+gzip", compressed.Bytes())
+	uncompressedLayer := writeTestBlob(t, layoutDir, "application/vnd.oci.image.layer.v1.tar", layer)
+	config := writeTestBlob(t, layoutDir, "application/vnd.oci.image.config.v1+json", []byte(`{"architecture":"amd64","os":"linux"}`))
+
+	amd64Manifest := writeTestJsonBlob(t, layoutDir, "application/vnd.oci.image.manifest.v1+json", ociManifest{Config: config, Layers: []ociDescriptor{gzipLayer}})
+	amd64Manifest.Platform = &ociPlatform{OS: "linux", Architecture: "amd64"}
+	arm64Manifest := writeTestJsonBlob(t, layoutDir, "application/vnd.oci.image.manifest.v1+json", ociManifest{Config: config, Layers: []ociDescriptor{uncompressedLayer}})
+	arm64Manifest.Platform = &ociPlatform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	attestationManifest := writeTestJsonBlob(t, layoutDir, "application/vnd.oci.image.manifest.v1+json", ociManifest{Config: config})
+	attestationManifest.Platform = &ociPlatform{OS: "unknown", Architecture: "unknown"}
+	attestationManifest.Annotations = map[string]string{attestationReferenceTypeAnnotation: attestationManifestReferenceType}
+	imageIndex := writeTestJsonBlob(t, layoutDir, ociIndexMediaType, ociManifest{Manifests: []ociDescriptor{amd64Manifest, arm64Manifest, attestationManifest}})
+	imageIndex.Annotations = map[string]string{imageNameAnnotation: "docker.io/library/test:1.0"}
+
+	indexContent, err := json.Marshal(ociManifest{Manifests: []ociDescriptor{imageIndex}})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(layoutDir, ociIndexFileName), indexContent, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(layoutDir, ociLayoutFileName), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644))
+	return
+}
+
+func assertConvertedArchives(t *testing.T, archivesPaths []string, tempDir string, layer []byte, gzipLayer ociDescriptor, expectedRepoTag string) {
+	// The attestation isn't an image, and each platform has an archive of its own
+	assert.Equal(t, []string{filepath.Join(tempDir, "image-linux-amd64.tar"), filepath.Join(tempDir, "image-linux-arm64-v8.tar")}, archivesPaths)
+	for _, archivePath := range archivesPaths {
+		files := readTestTar(t, archivePath)
+		var manifests []dockerArchiveManifest
+		assert.NoError(t, json.Unmarshal(files[dockerArchiveManifestFileName], &manifests))
+		if assert.Len(t, manifests, 1) && assert.Len(t, manifests[0].Layers, 1) {
+			assert.Equal(t, []string{expectedRepoTag}, manifests[0].RepoTags)
+			assert.Contains(t, files, manifests[0].Config)
+			// The layers are uncompressed
+			assert.Equal(t, layer, files[manifests[0].Layers[0]])
+		}
+	}
+	amd64Files := readTestTar(t, archivesPaths[0])
+	assert.Contains(t, amd64Files, getDigestHex(gzipLayer.Digest)+"/layer.tar")
+}
+
+func TestPrepareImageArchivesOciLayout(t *testing.T) {
+	layoutDir, tempDir := t.TempDir(), t.TempDir()
+	layer, gzipLayer := createTestOciLayout(t, layoutDir)
+	archivesPaths, err := prepareImageArchives(layoutDir, "", tempDir)
+	assert.NoError(t, err)
+	assertConvertedArchives(t, archivesPaths, tempDir, layer, gzipLayer, "docker.io/library/test:1.0")
+}
+
+func TestPrepareImageArchivesOciArchive(t *testing.T) {
+	layoutDir, tempDir := t.TempDir(), t.TempDir()
+	layer, gzipLayer := createTestOciLayout(t, layoutDir)
+	layoutFiles := make(map[string][]byte)
+	assert.NoError(t, filepath.Walk(layoutDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(layoutDir, path)
+		if err != nil {
+			return err
+		}
+		layoutFiles[filepath.ToSlash(relativePath)], err = os.ReadFile(path)
+		return err
+	}))
+	ociArchivePath := filepath.Join(t.TempDir(), "image.oci.tar")
+	assert.NoError(t, os.WriteFile(ociArchivePath, createTestTar(t, layoutFiles), 0644))
+
+	// The given image tag replaces the name in the layout
+	archivesPaths, err := prepareImageArchives(ociArchivePath, "my-image:2.0", tempDir)
+	assert.NoError(t, err)
+	assertConvertedArchives(t, archivesPaths, tempDir, layer, gzipLayer, "my-image:2.0")
+}
+
+func TestPrepareImageArchivesDockerArchive(t *testing.T) {
+	dockerArchivePath := filepath.Join(t.TempDir(), "image.tar")
+	assert.NoError(t, os.WriteFile(dockerArchivePath, createTestTar(t, map[string][]byte{dockerArchiveManifestFileName: []byte("[]")}), 0644))
+	// 'docker save' archives are scanned as they are
+	archivesPaths, err := prepareImageArchives(dockerArchivePath, "", t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{dockerArchivePath}, archivesPaths)
+
+	otherArchivePath := filepath.Join(t.TempDir(), "other.tar")
+	assert.NoError(t, os.WriteFile(otherArchivePath, createTestTar(t, map[string][]byte{"file.txt": []byte("content")}), 0644))
+	_, err = prepareImageArchives(otherArchivePath, "", t.TempDir())
+	assert.ErrorContains(t, err, "is not a 'docker save' archive or an oci-archive")
+
+	maliciousArchivePath := filepath.Join(t.TempDir(), "malicious.tar")
+	assert.NoError(t, os.WriteFile(maliciousArchivePath, createTestTar(t, map[string][]byte{ociLayoutFileName: []byte("{}"), "../evil": []byte("content")}), 0644))
+	_, err = prepareImageArchives(maliciousArchivePath, "", t.TempDir())
+	assert.ErrorContains(t, err, "points outside the archive")
+}
@@ -34,6 +34,13 @@ const (
 	fileNotSupportedExitCode = 3
 )
 
+// Holds the Xray scan results of an indexed file.
+type indexedFileScanResults struct {
+	filePath string
+	graph    *services.GraphNode
+	results  *services.ScanResponse
+}
+
 type ScanCommand struct {
 	serverDetails *config.ServerDetails
 	spec          *spec.SpecFiles
@@ -41,6 +48,7 @@ type ScanCommand struct {
 	// The location of the downloaded Xray indexer binary on the local file system.
 	indexerPath            string
 	indexerTempDir         string
+	indexerSettings        *xrutils.IndexerSettings
 	outputFormat           xrutils.OutputFormat
 	projectKey             string
 	watches                []string
@@ -49,6 +57,9 @@ type ScanCommand struct {
 	fail                   bool
 	printExtendedTable     bool
 	bypassArchiveLimits    bool
+	baselineResultsFile    string
+	severityThresholds     *xrutils.SeverityThresholds
+	licensePolicy          *xrutils.LicensePolicy
 	progress               ioUtils.ProgressMgr
 }
 
@@ -115,6 +126,27 @@ func (scanCmd *ScanCommand) SetBypassArchiveLimits(bypassArchiveLimits bool) *Sc
 	return scanCmd
 }
 
+// SetBaselineResultsFile sets the results file of a previous scan, saved in the json format.
+// If set, only issues that were added or resolved since are printed, and only new issues can fail the build.
+func (scanCmd *ScanCommand) SetBaselineResultsFile(baselineResultsFile string) *ScanCommand {
+	scanCmd.baselineResultsFile = baselineResultsFile
+	return scanCmd
+}
+
+// SetSeverityThresholds sets thresholds to fail the build by, according to the severity and CVSS score of the issues found.
+// Unlike the fail build rules of Xray policies, the thresholds are applied also when no watches are provided.
+func (scanCmd *ScanCommand) SetSeverityThresholds(thresholds *xrutils.SeverityThresholds) *ScanCommand {
+	scanCmd.severityThresholds = thresholds
+	return scanCmd
+}
+
+// SetIndexerSettings sets the path or the version of the Xray Indexer to use, and its expected checksum.
+// By default, the indexer matching the Xray version is downloaded and used.
+func (scanCmd *ScanCommand) SetIndexerSettings(settings *xrutils.IndexerSettings) *ScanCommand {
+	scanCmd.indexerSettings = settings
+	return scanCmd
+}
+
 func (scanCmd *ScanCommand) indexFile(filePath string) (*services.GraphNode, error) {
 	var indexerResults services.GraphNode
 	indexerCmd := exec.Command(scanCmd.indexerPath, indexingCommand, filePath, "--temp-dir", scanCmd.indexerTempDir)
@@ -152,6 +184,26 @@ func (scanCmd *ScanCommand) Run() (err error) {
 			}
 		}
 	}()
+	if err = scanCmd.severityThresholds.Validate(); err != nil {
+		return err
+	}
+	var baseline []services.ScanResponse
+	if scanCmd.baselineResultsFile != "" {
+		if baseline, err = xrutils.ReadBaselineResults(scanCmd.baselineResultsFile); err != nil {
+			return err
+		}
+	}
+	rootDir, err := scanCmd.getRootDir()
+	if err != nil {
+		return err
+	}
+	ignoreRules, err := xrutils.GetIgnoreRules(rootDir)
+	if err != nil {
+		return err
+	}
+	if scanCmd.licensePolicy, err = xrutils.GetLicensePolicy(rootDir); err != nil {
+		return err
+	}
 	xrayManager, xrayVersion, err := commands.CreateXrayServiceManagerAndGetVersion(scanCmd.serverDetails)
 	if err != nil {
 		return err
@@ -172,7 +224,7 @@ func (scanCmd *ScanCommand) Run() (err error) {
 	}
 	log.Info("JFrog Xray version is:", xrayVersion)
 	// First download Xray Indexer if needed
-	scanCmd.indexerPath, err = xrutils.DownloadIndexerIfNeeded(xrayManager, xrayVersion)
+	scanCmd.indexerPath, err = xrutils.GetIndexer(xrayManager, xrayVersion, scanCmd.indexerSettings)
 	if err != nil {
 		return err
 	}
@@ -193,7 +245,7 @@ func (scanCmd *ScanCommand) Run() (err error) {
 	}
 
 	// resultsArr is a two-dimensional array. Each array in it contains a list of ScanResponses that were requested and collected by a specific thread.
-	resultsArr := make([][]*services.ScanResponse, threads)
+	resultsArr := make([][]*indexedFileScanResults, threads)
 	fileProducerConsumer := parallel.NewRunner(scanCmd.threads, 20000, false)
 	fileProducerErrors := make([][]formats.SimpleJsonError, threads)
 	indexedFileProducerConsumer := parallel.NewRunner(scanCmd.threads, 20000, false)
@@ -206,11 +258,17 @@ func (scanCmd *ScanCommand) Run() (err error) {
 
 	// Handle results
 	flatResults := []services.ScanResponse{}
+	var scannedGraphs []*services.GraphNode
+	var scannedPaths []string
 	for _, arr := range resultsArr {
 		for _, res := range arr {
-			flatResults = append(flatResults, *res)
+			flatResults = append(flatResults, *res.results)
+			scannedGraphs = append(scannedGraphs, res.graph)
+			scannedPaths = append(scannedPaths, res.filePath)
 		}
 	}
+	// The local license policy and ignore rules are applied before printing the results and checking whether the build should fail.
+	flatResults, suppressedResults := ignoreRules.SuppressIssues(scanCmd.licensePolicy.AddLicenseViolations(flatResults), scannedPaths)
 	if scanCmd.progress != nil {
 		if err = scanCmd.progress.Quit(); err != nil {
 			return err
@@ -225,23 +283,35 @@ func (scanCmd *ScanCommand) Run() (err error) {
 	}
 	scanErrors = appendErrorSlice(scanErrors, fileProducerErrors)
 	scanErrors = appendErrorSlice(scanErrors, indexedFileProducerErrors)
-	err = xrutils.PrintScanResults(flatResults,
-		scanErrors,
-		scanCmd.outputFormat,
-		scanCmd.includeVulnerabilities,
-		scanCmd.includeLicenses,
-		true,
-		scanCmd.printExtendedTable,
-	)
+	err = xrutils.NewResultsWriter(flatResults).
+		SetDependencyTrees(scannedGraphs).
+		SetScannedPaths(scannedPaths).
+		SetBaselineResults(baseline).
+		SetSuppressedResults(suppressedResults).
+		SetErrors(scanErrors).
+		SetOutputFormat(scanCmd.outputFormat).
+		SetIncludeVulnerabilities(scanCmd.includeVulnerabilities).
+		SetIncludeLicenses(scanCmd.includeLicenses).
+		SetIsMultipleRootProject(true).
+		SetPrintExtendedTable(scanCmd.printExtendedTable).
+		PrintScanResults()
 	if err != nil {
 		return err
 	}
-	// If includeVulnerabilities is false it means that context was provided, so we need to check for build violations.
 	// If user provided --fail=false, don't fail the build.
-	if scanCmd.fail && !scanCmd.includeVulnerabilities {
-		if xrutils.CheckIfFailBuild(flatResults) {
+	// In baseline mode, only new issues can fail the build.
+	if scanCmd.fail {
+		newResults := xrutils.GetNewScanResults(flatResults, baseline)
+		// If includeVulnerabilities is false it means that context was provided, so we need to check for build violations.
+		if !scanCmd.includeVulnerabilities && xrutils.CheckIfFailBuild(newResults) {
 			return xrutils.NewFailBuildError()
 		}
+		if xrutils.CheckIfLicensePolicyFailBuild(newResults) {
+			return xrutils.NewLicensePolicyFailBuildError()
+		}
+		if xrutils.CheckIfExceedThresholds(newResults, scanCmd.severityThresholds) {
+			return xrutils.NewThresholdsFailBuildError(scanCmd.severityThresholds)
+		}
 	}
 	if len(scanErrors) > 0 {
 		return errorutils.CheckErrorf(scanErrors[0].ErrorMessage)
@@ -250,6 +320,23 @@ func (scanCmd *ScanCommand) Run() (err error) {
 	return nil
 }
 
+// getRootDir returns the directory the .jfrog directory of the scanned files is looked for from: the root path of the first file spec's pattern, or the current directory if there is no file spec.
+func (scanCmd *ScanCommand) getRootDir() (string, error) {
+	if scanCmd.spec == nil || len(scanCmd.spec.Files) == 0 {
+		return ".", nil
+	}
+	fileData := scanCmd.spec.Files[0]
+	rootPath, err := fspatterns.GetRootPath(clientutils.ReplaceTildeWithUserHome(fileData.Pattern), fileData.Target, "", fileData.GetPatternType(), false)
+	if err != nil {
+		return "", err
+	}
+	isDir, err := fileutils.IsDirExists(rootPath, false)
+	if err != nil || isDir {
+		return rootPath, err
+	}
+	return filepath.Dir(rootPath), nil
+}
+
 func NewScanCommand() *ScanCommand {
 	return &ScanCommand{}
 }
@@ -258,7 +345,7 @@ func (scanCmd *ScanCommand) CommandName() string {
 	return "xr_scan"
 }
 
-func (scanCmd *ScanCommand) prepareScanTasks(fileProducer, indexedFileProducer parallel.Runner, resultsArr [][]*services.ScanResponse, fileErrors, indexedFileErrors [][]formats.SimpleJsonError, fileCollectingErrorsQueue *clientutils.ErrorsQueue, xrayVersion string) {
+func (scanCmd *ScanCommand) prepareScanTasks(fileProducer, indexedFileProducer parallel.Runner, resultsArr [][]*indexedFileScanResults, fileErrors, indexedFileErrors [][]formats.SimpleJsonError, fileCollectingErrorsQueue *clientutils.ErrorsQueue, xrayVersion string) {
 	go func() {
 		defer fileProducer.Done()
 		// Iterate over file-spec groups and produce indexing tasks.
@@ -277,7 +364,7 @@ func (scanCmd *ScanCommand) prepareScanTasks(fileProducer, indexedFileProducer p
 	}()
 }
 
-func (scanCmd *ScanCommand) createIndexerHandlerFunc(file *spec.File, indexedFileProducer parallel.Runner, resultsArr [][]*services.ScanResponse, fileErrors, indexedFileErrors [][]formats.SimpleJsonError, xrayVersion string) FileContext {
+func (scanCmd *ScanCommand) createIndexerHandlerFunc(file *spec.File, indexedFileProducer parallel.Runner, resultsArr [][]*indexedFileScanResults, fileErrors, indexedFileErrors [][]formats.SimpleJsonError, xrayVersion string) FileContext {
 	return func(filePath string) parallel.TaskFunc {
 		return func(threadId int) (err error) {
 			logMsgPrefix := clientutils.GetLogMsgPrefix(threadId, false)
@@ -309,13 +396,15 @@ func (scanCmd *ScanCommand) createIndexerHandlerFunc(file *spec.File, indexedFil
 				if scanCmd.progress != nil {
 					scanCmd.progress.SetHeadlineMsg("Scanning 🔍")
 				}
-				scanResults, err := commands.RunScanGraphAndGetResults(scanCmd.serverDetails, params, scanCmd.includeVulnerabilities, scanCmd.includeLicenses, xrayVersion)
+				// The licenses are needed to evaluate the local license policy, even if they aren't printed.
+				includeLicenses := scanCmd.includeLicenses || scanCmd.licensePolicy != nil
+				scanResults, err := commands.RunScanGraphAndGetResults(scanCmd.serverDetails, params, scanCmd.includeVulnerabilities, includeLicenses, xrayVersion)
 				if err != nil {
 					log.Error(fmt.Sprintf("Scanning %s failed with error: %s", graph.Id, err.Error()))
 					indexedFileErrors[threadId] = append(indexedFileErrors[threadId], formats.SimpleJsonError{FilePath: filePath, ErrorMessage: err.Error()})
 					return
 				}
-				resultsArr[threadId] = append(resultsArr[threadId], scanResults)
+				resultsArr[threadId] = append(resultsArr[threadId], &indexedFileScanResults{filePath: filePath, graph: graph, results: scanResults})
 				return
 			}
 
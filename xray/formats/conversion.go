@@ -1,6 +1,7 @@
 package formats
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -16,6 +17,7 @@ func ConvertToVulnerabilityTableRow(rows []VulnerabilityOrViolationRow) (tableRo
 			Components:             ConvertToComponentTableRow(rows[i].Components),
 			Cves:                   ConvertToCveTableRow(rows[i].Cves),
 			IssueId:                rows[i].IssueId,
+			DescriptorPath:         rows[i].DescriptorPath,
 		})
 	}
 	return
@@ -31,6 +33,7 @@ func ConvertToLicenseViolationTableRow(rows []LicenseViolationRow) (tableRows []
 			ImpactedPackageVersion: rows[i].ImpactedPackageVersion,
 			ImpactedPackageType:    rows[i].ImpactedPackageType,
 			Components:             ConvertToComponentTableRow(rows[i].Components),
+			DescriptorPath:         rows[i].DescriptorPath,
 		})
 	}
 	return
@@ -44,6 +47,7 @@ func ConvertToLicenseTableRow(rows []LicenseRow) (tableRows []LicenseTableRow) {
 			ImpactedPackageVersion: rows[i].ImpactedPackageVersion,
 			ImpactedPackageType:    rows[i].ImpactedPackageType,
 			Components:             ConvertToComponentTableRow(rows[i].Components),
+			DescriptorPath:         rows[i].DescriptorPath,
 		})
 	}
 	return
@@ -66,11 +70,61 @@ func ConvertToOperationalRiskViolationTableRow(rows []OperationalRiskViolationRo
 			LatestVersion:          rows[i].LatestVersion,
 			RiskReason:             rows[i].RiskReason,
 			EolMessage:             rows[i].EolMessage,
+			DescriptorPath:         rows[i].DescriptorPath,
 		})
 	}
 	return
 }
 
+func ConvertToSuppressedIssueTableRow(rows []SuppressedIssueRow) (tableRows []SuppressedIssueTableRow) {
+	for i := range rows {
+		tableRows = append(tableRows, SuppressedIssueTableRow{
+			Severity:               rows[i].Severity,
+			SeverityNumValue:       rows[i].SeverityNumValue,
+			IssueId:                rows[i].IssueId,
+			Cves:                   ConvertToCveTableRow(rows[i].Cves),
+			LicenseKey:             rows[i].LicenseKey,
+			ImpactedPackageName:    rows[i].ImpactedPackageName,
+			ImpactedPackageVersion: rows[i].ImpactedPackageVersion,
+			ImpactedPackageType:    rows[i].ImpactedPackageType,
+			Components:             ConvertToComponentTableRow(rows[i].Components),
+			Justification:          rows[i].Justification,
+			ExpiresAt:              rows[i].ExpiresAt,
+			DescriptorPath:         rows[i].DescriptorPath,
+		})
+	}
+	return
+}
+
+func ConvertToRemediationTableRow(rows []RemediationRow) (tableRows []RemediationTableRow) {
+	for i := range rows {
+		tableRows = append(tableRows, RemediationTableRow{
+			DirectDependencyName:    rows[i].DirectDependencyName,
+			DirectDependencyVersion: rows[i].DirectDependencyVersion,
+			DirectDependencyType:    rows[i].DirectDependencyType,
+			SuggestedVersion:        rows[i].SuggestedVersion,
+			FixedIssues:             strings.Join(rows[i].FixedIssues, "\n"),
+			RemainingIssues:         strings.Join(rows[i].RemainingIssues, "\n"),
+			TransitiveIssues:        convertTransitiveIssues(rows[i].TransitiveIssues),
+			DescriptorPath:          rows[i].DescriptorPath,
+		})
+	}
+	return
+}
+
+// Each transitive issue is converted to a line like: XRAY-1 in qs:6.7.0, fixed in [6.7.3]
+func convertTransitiveIssues(transitiveIssues []TransitiveIssueRow) string {
+	var lines []string
+	for _, issue := range transitiveIssues {
+		line := fmt.Sprintf("%s in %s:%s", issue.IssueId, issue.ImpactedPackageName, issue.ImpactedPackageVersion)
+		if len(issue.FixedVersions) > 0 {
+			line += ", fixed in " + strings.Join(issue.FixedVersions, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func ConvertToComponentTableRow(rows []ComponentRow) (tableRows []ComponentTableRow) {
 	for i := range rows {
 		tableRows = append(tableRows, ComponentTableRow{
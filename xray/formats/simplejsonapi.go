@@ -13,6 +13,12 @@ type SimpleJsonResults struct {
 	Licenses                  []LicenseRow                  `json:"licenses"`
 	OperationalRiskViolations []OperationalRiskViolationRow `json:"operationalRiskViolations"`
 	Errors                    []SimpleJsonError             `json:"errors"`
+	// Holds the issues that were suppressed by local ignore rules.
+	SuppressedIssues []SuppressedIssueRow `json:"suppressedIssues,omitempty"`
+	// Holds the issues that were resolved compared to the baseline results. Set only in baseline mode.
+	Resolved *SimpleJsonResults `json:"resolved,omitempty"`
+	// Holds the upgrades of direct dependencies suggested to fix the issues found. Set only if remediation was requested.
+	Remediations []RemediationRow `json:"remediations,omitempty"`
 }
 
 // Used for vulnerabilities and security violations
@@ -31,6 +37,8 @@ type VulnerabilityOrViolationRow struct {
 	ImpactPaths              [][]ComponentRow          `json:"impactPaths"`
 	JfrogResearchInformation *JfrogResearchInformation `json:"jfrogResearchInformation"`
 	Technology               coreutils.Technology      `json:"-"`
+	// The path of the descriptor file of the audited project the issue was found in, like its package.json or pom.xml.
+	DescriptorPath string `json:"descriptorPath,omitempty"`
 }
 
 type LicenseRow struct {
@@ -40,6 +48,7 @@ type LicenseRow struct {
 	ImpactedPackageType    string           `json:"impactedPackageType"`
 	Components             []ComponentRow   `json:"components"`
 	ImpactPaths            [][]ComponentRow `json:"impactPaths"`
+	DescriptorPath         string           `json:"descriptorPath,omitempty"`
 }
 
 type LicenseViolationRow struct {
@@ -50,6 +59,7 @@ type LicenseViolationRow struct {
 	ImpactedPackageVersion string         `json:"impactedPackageVersion"`
 	ImpactedPackageType    string         `json:"impactedPackageType"`
 	Components             []ComponentRow `json:"components"`
+	DescriptorPath         string         `json:"descriptorPath,omitempty"`
 }
 
 type OperationalRiskViolationRow struct {
@@ -67,6 +77,23 @@ type OperationalRiskViolationRow struct {
 	Committers             string         `json:"committers"`
 	NewerVersions          string         `json:"newerVersions"`
 	LatestVersion          string         `json:"latestVersion"`
+	DescriptorPath         string         `json:"descriptorPath,omitempty"`
+}
+
+// Used for vulnerabilities, violations and licenses that were suppressed by a local ignore rule
+type SuppressedIssueRow struct {
+	IssueId                string         `json:"issueId"`
+	Cves                   []CveRow       `json:"cves"`
+	LicenseKey             string         `json:"licenseKey"`
+	Severity               string         `json:"severity"`
+	SeverityNumValue       int            `json:"-"` // For sorting
+	ImpactedPackageName    string         `json:"impactedPackageName"`
+	ImpactedPackageVersion string         `json:"impactedPackageVersion"`
+	ImpactedPackageType    string         `json:"impactedPackageType"`
+	Components             []ComponentRow `json:"components"`
+	Justification          string         `json:"justification"`
+	ExpiresAt              string         `json:"expiresAt"`
+	DescriptorPath         string         `json:"descriptorPath,omitempty"`
 }
 
 type ComponentRow struct {
@@ -98,3 +125,28 @@ type JfrogResearchSeverityReason struct {
 	Description string `json:"description,omitempty"`
 	IsPositive  bool   `json:"isPositive,omitempty"`
 }
+
+// Holds an upgrade of a direct dependency, suggested to fix the vulnerabilities and security violations found in it.
+type RemediationRow struct {
+	DirectDependencyName    string `json:"directDependencyName"`
+	DirectDependencyVersion string `json:"directDependencyVersion"`
+	DirectDependencyType    string `json:"directDependencyType"`
+	// Empty if no upgrade fixes any of the issues.
+	SuggestedVersion string `json:"suggestedVersion"`
+	// The issues fixed by upgrading to the suggested version.
+	FixedIssues []string `json:"fixedIssues"`
+	// The issues found in the direct dependency itself that are not fixed by the upgrade.
+	RemainingIssues []string `json:"remainingIssues"`
+	// The issues found in the transitive dependencies of the direct dependency. They are fixed by upgrading the direct dependency to a version
+	// that depends on a fixed version of the transitive dependency.
+	TransitiveIssues []TransitiveIssueRow `json:"transitiveIssues,omitempty"`
+	DescriptorPath   string               `json:"descriptorPath,omitempty"`
+}
+
+// Holds an issue found in a transitive dependency of a direct dependency, and the versions of the transitive dependency that fix it.
+type TransitiveIssueRow struct {
+	IssueId                string   `json:"issueId"`
+	ImpactedPackageName    string   `json:"impactedPackageName"`
+	ImpactedPackageVersion string   `json:"impactedPackageVersion"`
+	FixedVersions          []string `json:"fixedVersions"`
+}
@@ -15,6 +15,7 @@ type VulnerabilityTableRow struct {
 	Components             []ComponentTableRow `embed-table:"true"`
 	Cves                   []CveTableRow       `embed-table:"true"`
 	IssueId                string              `col-name:"Issue ID" extended:"true"`
+	DescriptorPath         string              `col-name:"Descriptor" omitempty:"true"`
 }
 
 type LicenseTableRow struct {
@@ -23,6 +24,7 @@ type LicenseTableRow struct {
 	ImpactedPackageVersion string              `col-name:"Impacted\nPackage\nVersion"`
 	ImpactedPackageType    string              `col-name:"Type"`
 	Components             []ComponentTableRow `embed-table:"true"`
+	DescriptorPath         string              `col-name:"Descriptor" omitempty:"true"`
 }
 
 type LicenseViolationTableRow struct {
@@ -33,6 +35,7 @@ type LicenseViolationTableRow struct {
 	ImpactedPackageVersion string              `col-name:"Impacted\nPackage\nVersion"`
 	ImpactedPackageType    string              `col-name:"Type"`
 	Components             []ComponentTableRow `embed-table:"true"`
+	DescriptorPath         string              `col-name:"Descriptor" omitempty:"true"`
 }
 
 type OperationalRiskViolationTableRow struct {
@@ -50,6 +53,33 @@ type OperationalRiskViolationTableRow struct {
 	Committers             string              `col-name:"Committers"  extended:"true"`
 	NewerVersions          string              `col-name:"Newer\nVersions" extended:"true"`
 	LatestVersion          string              `col-name:"Latest\nVersion" extended:"true"`
+	DescriptorPath         string              `col-name:"Descriptor" omitempty:"true"`
+}
+
+type SuppressedIssueTableRow struct {
+	Severity               string              `col-name:"Severity"`
+	SeverityNumValue       int                 // For sorting
+	IssueId                string              `col-name:"Issue ID"`
+	Cves                   []CveTableRow       `embed-table:"true"`
+	LicenseKey             string              `col-name:"License"`
+	ImpactedPackageName    string              `col-name:"Impacted\nPackage"`
+	ImpactedPackageVersion string              `col-name:"Impacted\nPackage\nVersion"`
+	ImpactedPackageType    string              `col-name:"Type"`
+	Components             []ComponentTableRow `embed-table:"true" extended:"true"`
+	Justification          string              `col-name:"Justification"`
+	ExpiresAt              string              `col-name:"Expires\nAt"`
+	DescriptorPath         string              `col-name:"Descriptor" omitempty:"true"`
+}
+
+type RemediationTableRow struct {
+	DirectDependencyName    string `col-name:"Direct\nDependency"`
+	DirectDependencyVersion string `col-name:"Current\nVersion"`
+	DirectDependencyType    string `col-name:"Type"`
+	SuggestedVersion        string `col-name:"Suggested\nVersion"`
+	FixedIssues             string `col-name:"Fixed\nIssues"`
+	RemainingIssues         string `col-name:"Remaining\nIssues"`
+	TransitiveIssues        string `col-name:"Transitive\nIssues"`
+	DescriptorPath          string `col-name:"Descriptor" omitempty:"true"`
 }
 
 type ComponentTableRow struct {
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+// ReadBaselineResults reads the results of a previous scan, saved in the Json format (or the full results file written by the Table format).
+// Issues that were suppressed in the previous scan are considered part of the baseline only if the baseline is the full results file.
+// The Json format of a scan in baseline mode holds only the issues that were new in that scan.
+func ReadBaselineResults(baselineFilePath string) ([]services.ScanResponse, error) {
+	content, err := os.ReadFile(baselineFilePath)
+	if errorutils.CheckError(err) != nil {
+		return nil, err
+	}
+	baseline := []services.ScanResponse{}
+	if err = json.Unmarshal(content, &baseline); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the baseline results file %s: %s", baselineFilePath, err.Error())
+	}
+	if baseline == nil {
+		// The file content is 'null'
+		baseline = []services.ScanResponse{}
+	}
+	return baseline, nil
+}
+
+// GetNewScanResults returns the issues that don't exist in the baseline.
+// An issue is identified by its type, its ID (or license key) and the impacted component, so an existing issue that impacts a new component is considered new.
+// If baseline is nil, the results are returned as is.
+func GetNewScanResults(results, baseline []services.ScanResponse) []services.ScanResponse {
+	if baseline == nil {
+		return results
+	}
+	return subtractScanResults(results, baseline)
+}
+
+// Returns the issues of minuend that don't exist in subtrahend. Scan responses that are left with no issues are omitted.
+func subtractScanResults(minuend, subtrahend []services.ScanResponse) (results []services.ScanResponse) {
+	results, _ = subtractScanResultsWithPaths(minuend, nil, subtrahend)
+	return
+}
+
+// Same as subtractScanResults, but also returns the scanned path of each of the returned results.
+// minuendPaths holds the scanned path of each of the minuend results (by index), and may be nil.
+func subtractScanResultsWithPaths(minuend []services.ScanResponse, minuendPaths []string, subtrahend []services.ScanResponse) (results []services.ScanResponse, paths []string) {
+	existingIssues := make(issueKeys)
+	for _, response := range subtrahend {
+		for _, violation := range response.Violations {
+			for componentId := range violation.Components {
+				existingIssues[getViolationKey(violation, componentId)] = true
+			}
+		}
+		for _, vulnerability := range response.Vulnerabilities {
+			for componentId := range vulnerability.Components {
+				existingIssues[getVulnerabilityKey(vulnerability, componentId)] = true
+			}
+		}
+		for _, license := range response.Licenses {
+			for componentId := range license.Components {
+				existingIssues[getLicenseKey(license, componentId)] = true
+			}
+		}
+	}
+	for i, response := range minuend {
+		_, newIssues := splitScanResponse(response, existingIssues)
+		if IsEmptyScanResponse([]services.ScanResponse{newIssues}) {
+			continue
+		}
+		results = append(results, newIssues)
+		if i < len(minuendPaths) {
+			paths = append(paths, minuendPaths[i])
+		} else {
+			paths = append(paths, "")
+		}
+	}
+	return
+}
+
+// A set of issue keys, which matches the issues it contains.
+type issueKeys map[string]bool
+
+func (ik issueKeys) matchViolation(violation services.Violation, componentId string) bool {
+	return ik[getViolationKey(violation, componentId)]
+}
+
+func (ik issueKeys) matchVulnerability(vulnerability services.Vulnerability, componentId string) bool {
+	return ik[getVulnerabilityKey(vulnerability, componentId)]
+}
+
+func (ik issueKeys) matchLicense(license services.License, componentId string) bool {
+	return ik[getLicenseKey(license, componentId)]
+}
+
+func getViolationKey(violation services.Violation, componentId string) string {
+	return "violation|" + violation.ViolationType + "|" + violation.IssueId + "|" + violation.LicenseKey + "|" + componentId
+}
+
+func getVulnerabilityKey(vulnerability services.Vulnerability, componentId string) string {
+	return "vulnerability|" + vulnerability.IssueId + "|" + componentId
+}
+
+func getLicenseKey(license services.License, componentId string) string {
+	return "license|" + license.Key + "|" + componentId
+}
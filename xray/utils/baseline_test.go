@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNewScanResults(t *testing.T) {
+	baseline := []services.ScanResponse{{
+		Violations: []services.Violation{
+			{IssueId: "XRAY-1", ViolationType: "security", FailBuild: true, Components: map[string]services.Component{"npm://debug:2.6.9": {}}},
+			{IssueId: "XRAY-2", ViolationType: "security", Components: map[string]services.Component{"npm://lodash:4.17.20": {}}},
+		},
+		Licenses: []services.License{{Key: "MIT", Components: map[string]services.Component{"npm://debug:2.6.9": {}}}},
+	}}
+	current := []services.ScanResponse{{
+		Violations: []services.Violation{
+			// Existing issue that impacts a new component
+			{IssueId: "XRAY-1", ViolationType: "security", FailBuild: true, Components: map[string]services.Component{"npm://debug:2.6.9": {}, "npm://debug:3.0.0": {}}},
+			{IssueId: "XRAY-3", ViolationType: "license", LicenseKey: "GPL-3.0", Components: map[string]services.Component{"npm://gpl-lib:1.0.0": {}}},
+		},
+		Licenses: []services.License{{Key: "MIT", Components: map[string]services.Component{"npm://debug:2.6.9": {}}}},
+	}}
+
+	newResults, resolvedResults := GetNewScanResults(current, baseline), subtractScanResults(baseline, current)
+	if assert.Len(t, newResults, 1) {
+		assert.Empty(t, newResults[0].Licenses)
+		if assert.Len(t, newResults[0].Violations, 2) {
+			assert.Equal(t, map[string]services.Component{"npm://debug:3.0.0": {}}, newResults[0].Violations[0].Components)
+			assert.Equal(t, "XRAY-3", newResults[0].Violations[1].IssueId)
+		}
+	}
+	if assert.Len(t, resolvedResults, 1) && assert.Len(t, resolvedResults[0].Violations, 1) {
+		assert.Equal(t, "XRAY-2", resolvedResults[0].Violations[0].IssueId)
+	}
+	assert.True(t, CheckIfFailBuild(GetNewScanResults(current, baseline)))
+
+	// Nothing changed
+	newResults, resolvedResults = GetNewScanResults(baseline, baseline), subtractScanResults(baseline, baseline)
+	assert.Empty(t, newResults)
+	assert.Empty(t, resolvedResults)
+	assert.False(t, CheckIfFailBuild(GetNewScanResults(baseline, baseline)))
+
+	// No baseline
+	assert.Equal(t, current, GetNewScanResults(current, nil))
+}
@@ -0,0 +1,347 @@
+package utils
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	xrayToolName   = "JFrog Xray"
+	xrayToolVendor = "JFrog"
+	xrayIssueIdKey = "jfrog:xray:issue-id"
+	// Set on the components that violate a license policy, with the license key, the severity and the summary of the violation.
+	xrayLicenseViolationKey = "jfrog:xray:license-violation"
+	// Added to the BOM reference of vulnerabilities that were suppressed by a local ignore rule, to keep them separated from the unsuppressed ones.
+	suppressedRefSuffix = "-suppressed"
+)
+
+// GenerateCycloneDxBomFromScan creates a CycloneDX 1.4 JSON document from Xray scan results.
+// The document lists all the components of the scanned dependency trees, the dependency relationships between them and the vulnerabilities Xray reported.
+// If no dependency trees are provided (as in build scan), the components and relationships are taken from the impact paths of the results.
+// Vulnerabilities that were suppressed by local ignore rules are listed with an analysis that holds the rule's justification.
+func GenerateCycloneDxBomFromScan(results []services.ScanResponse, suppressed []SuppressedScanResults, dependencyTrees []*services.GraphNode) (string, error) {
+	bom := createCycloneDxBom(results, suppressed, dependencyTrees)
+	bom.SerialNumber = "urn:uuid:" + uuid.New().String()
+	bom.Metadata.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	var content bytes.Buffer
+	encoder := cdx.NewBOMEncoder(&content, cdx.BOMFileFormatJSON)
+	encoder.SetPretty(true)
+	if err := encoder.Encode(bom); err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	return content.String(), nil
+}
+
+func createCycloneDxBom(results []services.ScanResponse, suppressed []SuppressedScanResults, dependencyTrees []*services.GraphNode) *cdx.BOM {
+	builder := newCycloneDxBuilder()
+	for _, tree := range dependencyTrees {
+		builder.addComponent(tree.Id, cdx.ComponentTypeApplication)
+		builder.addTree(tree, []string{tree.Id})
+	}
+	builder.addScanResults(results, nil)
+	for _, suppressedResults := range suppressed {
+		builder.addScanResults(suppressedResults.Results, &cdx.VulnerabilityAnalysis{
+			Response: &[]cdx.ImpactAnalysisResponse{cdx.IARWillNotFix},
+			Detail:   suppressedResults.Rule.getSuppressionDetail(),
+		})
+	}
+
+	bom := cdx.NewBOM()
+	bom.Metadata = &cdx.Metadata{
+		Tools: &[]cdx.Tool{{Vendor: xrayToolVendor, Name: xrayToolName}},
+	}
+	if userAgent := coreutils.GetCliUserAgentName(); userAgent != "" {
+		*bom.Metadata.Tools = append(*bom.Metadata.Tools, cdx.Tool{Vendor: xrayToolVendor, Name: userAgent, Version: coreutils.GetCliUserAgentVersion()})
+	}
+	if len(dependencyTrees) == 1 {
+		root := builder.components[dependencyTrees[0].Id]
+		bom.Metadata.Component = &root
+		delete(builder.components, dependencyTrees[0].Id)
+	}
+	components := builder.sortedComponents()
+	bom.Components = &components
+	dependencies := builder.sortedDependencies()
+	bom.Dependencies = &dependencies
+	if len(builder.vulnerabilities) > 0 {
+		bomVulnerabilities := builder.sortedVulnerabilities()
+		bom.Vulnerabilities = &bomVulnerabilities
+	}
+	return bom
+}
+
+type cycloneDxBuilder struct {
+	components      map[string]cdx.Component
+	dependencies    map[string]map[string]bool
+	vulnerabilities map[string]*cdx.Vulnerability
+}
+
+func newCycloneDxBuilder() *cycloneDxBuilder {
+	return &cycloneDxBuilder{
+		components:      make(map[string]cdx.Component),
+		dependencies:    make(map[string]map[string]bool),
+		vulnerabilities: make(map[string]*cdx.Vulnerability),
+	}
+}
+
+// Add the vulnerabilities, security violations, license violations and licenses of the results. The analysis is set on the added vulnerabilities, if not nil.
+// License violations are added as properties of the impacted components, unless they were suppressed (analysis isn't nil).
+func (cb *cycloneDxBuilder) addScanResults(results []services.ScanResponse, analysis *cdx.VulnerabilityAnalysis) {
+	violations, vulnerabilities, licenses := splitScanResults(results)
+	for _, vulnerability := range vulnerabilities {
+		cb.addVulnerability(vulnerability.IssueId, vulnerability.Summary, vulnerability.Severity, vulnerability.Cves, vulnerability.References, vulnerability.Components, analysis)
+	}
+	for _, violation := range violations {
+		switch violation.ViolationType {
+		case "security":
+			cb.addVulnerability(violation.IssueId, violation.Summary, violation.Severity, violation.Cves, violation.References, violation.Components, analysis)
+		case "license":
+			if analysis == nil {
+				cb.addLicenseViolation(violation)
+			}
+		}
+	}
+	for _, license := range licenses {
+		cb.addLicense(license)
+	}
+}
+
+// Add the node's children and their relationships, while preventing circular dependencies parsing.
+func (cb *cycloneDxBuilder) addTree(node *services.GraphNode, path []string) {
+	for _, child := range node.Nodes {
+		cb.addComponent(child.Id, cdx.ComponentTypeLibrary)
+		cb.addDependency(node.Id, child.Id)
+		if hasComponentLoop(path, child.Id) {
+			continue
+		}
+		cb.addTree(child, append(path, child.Id))
+	}
+}
+
+func (cb *cycloneDxBuilder) addComponent(componentId string, componentType cdx.ComponentType) {
+	if _, exist := cb.components[componentId]; exist {
+		return
+	}
+	compName, compVersion, _ := splitComponentId(componentId)
+	component := cdx.Component{
+		BOMRef:     componentId,
+		Type:       componentType,
+		Name:       compName,
+		Version:    compVersion,
+		PackageURL: componentIdToPurl(componentId),
+	}
+	if strings.HasPrefix(componentId, "gav://") {
+		if groupAndName := strings.SplitN(compName, ":", 2); len(groupAndName) == 2 {
+			component.Group, component.Name = groupAndName[0], groupAndName[1]
+		}
+	}
+	cb.components[componentId] = component
+}
+
+func (cb *cycloneDxBuilder) addDependency(parentId, childId string) {
+	if cb.dependencies[parentId] == nil {
+		cb.dependencies[parentId] = make(map[string]bool)
+	}
+	cb.dependencies[parentId][childId] = true
+}
+
+// Add the components of the impact paths, so that the document contains them even if they are missing from the dependency trees.
+func (cb *cycloneDxBuilder) addImpactPaths(impactPaths [][]services.ImpactPathNode) {
+	for _, impactPath := range impactPaths {
+		for i, node := range impactPath {
+			componentType := cdx.ComponentTypeLibrary
+			if i == 0 {
+				componentType = cdx.ComponentTypeApplication
+			} else {
+				cb.addDependency(impactPath[i-1].ComponentId, node.ComponentId)
+			}
+			cb.addComponent(node.ComponentId, componentType)
+		}
+	}
+}
+
+func (cb *cycloneDxBuilder) addVulnerability(issueId, summary, severity string, cves []services.Cve, references []string, components map[string]services.Component, analysis *cdx.VulnerabilityAnalysis) {
+	bomRef := issueId
+	if analysis != nil {
+		bomRef += suppressedRefSuffix
+	}
+	vulnerability, exist := cb.vulnerabilities[bomRef]
+	if !exist {
+		vulnerability = createCycloneDxVulnerability(issueId, summary, severity, cves, references)
+		vulnerability.BOMRef = bomRef
+		vulnerability.Analysis = analysis
+		cb.vulnerabilities[bomRef] = vulnerability
+	}
+	var fixedVersions []string
+	for componentId, component := range components {
+		cb.addComponent(componentId, cdx.ComponentTypeLibrary)
+		cb.addImpactPaths(component.ImpactPaths)
+		if !containsAffectedRef(*vulnerability.Affects, componentId) {
+			*vulnerability.Affects = append(*vulnerability.Affects, cdx.Affects{Ref: componentId})
+		}
+		fixedVersions = append(fixedVersions, component.FixedVersions...)
+	}
+	if len(fixedVersions) > 0 && vulnerability.Recommendation == "" {
+		vulnerability.Recommendation = "Fixed in versions: " + strings.Join(fixedVersions, ", ")
+	}
+}
+
+func createCycloneDxVulnerability(issueId, summary, severity string, cves []services.Cve, references []string) *cdx.Vulnerability {
+	vulnerability := &cdx.Vulnerability{
+		BOMRef:      issueId,
+		ID:          issueId,
+		Source:      &cdx.Source{Name: xrayToolName},
+		Description: summary,
+		Affects:     &[]cdx.Affects{},
+		Properties:  &[]cdx.Property{{Name: xrayIssueIdKey, Value: issueId}},
+	}
+	// Prefer the CVE ID as the vulnerability ID, as it is recognized by all SBOM consumers.
+	if len(cves) > 0 && cves[0].Id != "" {
+		vulnerability.ID = cves[0].Id
+		vulnerability.Source = &cdx.Source{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + cves[0].Id}
+	}
+	ratings := []cdx.VulnerabilityRating{{
+		Source:   &cdx.Source{Name: xrayToolName},
+		Severity: toCycloneDxSeverity(severity),
+		Method:   cdx.ScoringMethodOther,
+	}}
+	for _, cve := range cves {
+		if score, err := strconv.ParseFloat(cve.CvssV3Score, 64); err == nil {
+			ratings = append(ratings, cdx.VulnerabilityRating{Score: &score, Method: cdx.ScoringMethodCVSSv3, Vector: cve.CvssV3Vector})
+		}
+		if score, err := strconv.ParseFloat(cve.CvssV2Score, 64); err == nil {
+			ratings = append(ratings, cdx.VulnerabilityRating{Score: &score, Method: cdx.ScoringMethodCVSSv2, Vector: cve.CvssV2Vector})
+		}
+	}
+	vulnerability.Ratings = &ratings
+	if len(references) > 0 {
+		var advisories []cdx.Advisory
+		for _, reference := range references {
+			advisories = append(advisories, cdx.Advisory{URL: reference})
+		}
+		vulnerability.Advisories = &advisories
+	}
+	return vulnerability
+}
+
+func (cb *cycloneDxBuilder) addLicense(license services.License) {
+	for componentId, component := range license.Components {
+		cb.addComponent(componentId, cdx.ComponentTypeLibrary)
+		cb.addImpactPaths(component.ImpactPaths)
+		bomComponent := cb.components[componentId]
+		if bomComponent.Licenses == nil {
+			bomComponent.Licenses = &cdx.Licenses{}
+		}
+		*bomComponent.Licenses = append(*bomComponent.Licenses, cdx.LicenseChoice{License: &cdx.License{ID: license.Key, Name: license.Name}})
+		cb.components[componentId] = bomComponent
+	}
+}
+
+func (cb *cycloneDxBuilder) addLicenseViolation(violation services.Violation) {
+	description := violation.LicenseKey + " (" + violation.Severity + ")"
+	if violation.Summary != "" {
+		description += ": " + violation.Summary
+	}
+	for componentId, component := range violation.Components {
+		cb.addComponent(componentId, cdx.ComponentTypeLibrary)
+		cb.addImpactPaths(component.ImpactPaths)
+		bomComponent := cb.components[componentId]
+		if bomComponent.Properties == nil {
+			bomComponent.Properties = &[]cdx.Property{}
+		}
+		property := cdx.Property{Name: xrayLicenseViolationKey, Value: description}
+		if !containsProperty(*bomComponent.Properties, property) {
+			*bomComponent.Properties = append(*bomComponent.Properties, property)
+		}
+		cb.components[componentId] = bomComponent
+	}
+}
+
+func containsProperty(properties []cdx.Property, property cdx.Property) bool {
+	for _, existing := range properties {
+		if existing == property {
+			return true
+		}
+	}
+	return false
+}
+
+func (cb *cycloneDxBuilder) sortedComponents() (components []cdx.Component) {
+	for _, component := range cb.components {
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].BOMRef < components[j].BOMRef
+	})
+	return
+}
+
+func (cb *cycloneDxBuilder) sortedDependencies() (dependencies []cdx.Dependency) {
+	for ref, dependsOn := range cb.dependencies {
+		var dependsOnRefs []string
+		for childRef := range dependsOn {
+			dependsOnRefs = append(dependsOnRefs, childRef)
+		}
+		sort.Strings(dependsOnRefs)
+		dependencies = append(dependencies, cdx.Dependency{Ref: ref, Dependencies: &dependsOnRefs})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Ref < dependencies[j].Ref
+	})
+	return
+}
+
+func (cb *cycloneDxBuilder) sortedVulnerabilities() (vulnerabilities []cdx.Vulnerability) {
+	for _, vulnerability := range cb.vulnerabilities {
+		sort.Slice(*vulnerability.Affects, func(i, j int) bool {
+			return (*vulnerability.Affects)[i].Ref < (*vulnerability.Affects)[j].Ref
+		})
+		vulnerabilities = append(vulnerabilities, *vulnerability)
+	}
+	sort.Slice(vulnerabilities, func(i, j int) bool {
+		return vulnerabilities[i].BOMRef < vulnerabilities[j].BOMRef
+	})
+	return
+}
+
+func toCycloneDxSeverity(severity string) cdx.Severity {
+	switch severity {
+	case "Critical":
+		return cdx.SeverityCritical
+	case "High":
+		return cdx.SeverityHigh
+	case "Medium":
+		return cdx.SeverityMedium
+	case "Low":
+		return cdx.SeverityLow
+	default:
+		return cdx.SeverityUnknown
+	}
+}
+
+func containsAffectedRef(affects []cdx.Affects, ref string) bool {
+	for _, affected := range affects {
+		if affected.Ref == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func hasComponentLoop(path []string, componentId string) bool {
+	for _, id := range path {
+		if id == componentId {
+			return true
+		}
+	}
+	return false
+}
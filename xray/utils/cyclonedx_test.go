@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCycloneDxBom(t *testing.T) {
+	dependencyTree := &services.GraphNode{
+		Id: "npm://root:1.0.0",
+		Nodes: []*services.GraphNode{
+			{Id: "npm://express:4.17.1", Nodes: []*services.GraphNode{{Id: "npm://debug:2.6.9"}}},
+			{Id: "npm://@jfrog/scoped:1.0.0"},
+		},
+	}
+	results := []services.ScanResponse{{
+		Vulnerabilities: []services.Vulnerability{{
+			IssueId:  "XRAY-1",
+			Summary:  "summary-1",
+			Severity: "High",
+			Cves:     []services.Cve{{Id: "CVE-2022-0001", CvssV3Score: "7.5"}},
+			Components: map[string]services.Component{
+				"npm://debug:2.6.9": {
+					FixedVersions: []string{"[2.6.10]"},
+					ImpactPaths:   [][]services.ImpactPathNode{{{ComponentId: "npm://root:1.0.0"}, {ComponentId: "npm://express:4.17.1"}, {ComponentId: "npm://debug:2.6.9"}}},
+				},
+			},
+		}},
+		Licenses: []services.License{{
+			Key:        "MIT",
+			Components: map[string]services.Component{"npm://express:4.17.1": {}},
+		}},
+	}}
+
+	bom := createCycloneDxBom(results, nil, []*services.GraphNode{dependencyTree})
+	assert.Equal(t, cdx.SpecVersion1_4, bom.SpecVersion)
+	if assert.NotNil(t, bom.Metadata.Component) {
+		assert.Equal(t, "npm://root:1.0.0", bom.Metadata.Component.BOMRef)
+		assert.Equal(t, cdx.ComponentTypeApplication, bom.Metadata.Component.Type)
+	}
+
+	// Components
+	assert.Len(t, *bom.Components, 3)
+	expectedPurls := map[string]string{
+		"npm://@jfrog/scoped:1.0.0": "pkg:npm/%40jfrog/scoped@1.0.0",
+		"npm://debug:2.6.9":         "pkg:npm/debug@2.6.9",
+		"npm://express:4.17.1":      "pkg:npm/express@4.17.1",
+	}
+	for _, component := range *bom.Components {
+		assert.Equal(t, expectedPurls[component.BOMRef], component.PackageURL)
+		if component.BOMRef == "npm://express:4.17.1" && assert.NotNil(t, component.Licenses) {
+			assert.Equal(t, "MIT", (*component.Licenses)[0].License.ID)
+		}
+	}
+
+	// Dependencies
+	expectedDependencies := []cdx.Dependency{
+		{Ref: "npm://express:4.17.1", Dependencies: &[]string{"npm://debug:2.6.9"}},
+		{Ref: "npm://root:1.0.0", Dependencies: &[]string{"npm://@jfrog/scoped:1.0.0", "npm://express:4.17.1"}},
+	}
+	assert.Equal(t, expectedDependencies, *bom.Dependencies)
+
+	// Vulnerabilities
+	if assert.Len(t, *bom.Vulnerabilities, 1) {
+		vulnerability := (*bom.Vulnerabilities)[0]
+		assert.Equal(t, "CVE-2022-0001", vulnerability.ID)
+		assert.Equal(t, "XRAY-1", vulnerability.BOMRef)
+		assert.Equal(t, []cdx.Affects{{Ref: "npm://debug:2.6.9"}}, *vulnerability.Affects)
+		assert.Equal(t, "Fixed in versions: [2.6.10]", vulnerability.Recommendation)
+		if assert.Len(t, *vulnerability.Ratings, 2) {
+			assert.Equal(t, cdx.SeverityHigh, (*vulnerability.Ratings)[0].Severity)
+			assert.Equal(t, 7.5, *(*vulnerability.Ratings)[1].Score)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The location of a dependency's declaration in the content of a descriptor file.
+type dependencyDeclaration struct {
+	// The byte offset of the dependency's name.
+	nameOffset int
+	// The byte offsets of the dependency's version ([versionStart, versionEnd)). Both are -1 if the version isn't declared in the descriptor.
+	versionStart int
+	versionEnd   int
+	// The name of the property the version is set by, like lib.version for ${lib.version} in pom.xml. Empty if the version is set directly.
+	versionProperty string
+}
+
+func (dd *dependencyDeclaration) hasVersion() bool {
+	return dd.versionStart >= 0
+}
+
+// Finds the declarations of a dependency in the content of a descriptor file.
+type dependencyLocator func(content, dependencyName string) []dependencyDeclaration
+
+// Returns the locator of the dependencies declared in the given descriptor file, or nil if the descriptor type isn't supported.
+func getDependencyLocator(descriptorPath string) dependencyLocator {
+	fileName := filepath.Base(descriptorPath)
+	switch {
+	case fileName == "package.json":
+		return newRegexpLocator(func(dependencyName string) string {
+			// Only exact versions and simple ranges, like ^1.2.3, are considered as the declared version.
+			return `"(?P<name>` + regexp.QuoteMeta(dependencyName) + `)"\s*:\s*"(?:(?:[\^~]|[<>]?=)?\s*(?P<version>\d[^"\s|]*)|[^"]*)"`
+		})
+	case fileName == "go.mod":
+		return newRegexpLocator(func(dependencyName string) string {
+			// Replace directives are ignored
+			return `(?m)^(?:\s*require)?\s+(?P<name>` + regexp.QuoteMeta(dependencyName) + `)\s+(?P<version>v\S+)[ \t]*(?:$|//)`
+		})
+	case fileName == "requirements.txt":
+		return newRegexpLocator(func(dependencyName string) string {
+			return `(?mi)^\s*(?P<name>` + getPythonPackageNamePattern(dependencyName) + `)(?:\[[^\]]*\])?\s*(?:(?:===|==|~=|>=)\s*(?P<version>[^\s;#,]+)|[<>!;#]|$)`
+		})
+	case fileName == "setup.py":
+		return newRegexpLocator(func(dependencyName string) string {
+			return `(?i)["'](?P<name>` + getPythonPackageNamePattern(dependencyName) + `)(?:\[[^\]]*\])?\s*(?:(?:===|==|~=|>=)\s*(?P<version>[^"'\s,;]+)|[<>!;"'])`
+		})
+	case fileName == "Pipfile" || fileName == "pyproject.toml":
+		return newRegexpLocator(func(dependencyName string) string {
+			return `(?mi)^\s*["']?(?P<name>` + getPythonPackageNamePattern(dependencyName) + `)["']?\s*=\s*(?:["'](?:[\^~]|[<>=~]=)?(?P<version>\d[^"'\s,]*)["'])?`
+		})
+	case fileName == "pom.xml":
+		return locatePomXmlDependency
+	case strings.HasSuffix(fileName, ".gradle") || strings.HasSuffix(fileName, ".gradle.kts"):
+		return newRegexpLocator(func(dependencyName string) string {
+			groupId, artifactId, _ := strings.Cut(dependencyName, ":")
+			// Either the string notation ('group:name:version') or the map notation (group: 'group', name: 'name', version: 'version')
+			return `["'](?P<name>` + regexp.QuoteMeta(dependencyName) + `)(?::(?P<version>[^:@"']+))?[:@"']|` +
+				`(?P<name>group\s*[:=]\s*["']` + regexp.QuoteMeta(groupId) + `["']\s*,\s*name\s*[:=]\s*["']` + regexp.QuoteMeta(artifactId) + `["'])(?:\s*,\s*version\s*[:=]\s*["'](?P<version>[^"']+)["'])?`
+		})
+	case strings.HasSuffix(fileName, ".csproj"):
+		return newRegexpLocator(func(dependencyName string) string {
+			return `(?i)<PackageReference\s+Include\s*=\s*"(?P<name>` + regexp.QuoteMeta(dependencyName) + `)"(?:\s+Version\s*=\s*"(?P<version>[^"]+)")?`
+		})
+	case fileName == "packages.config":
+		return newRegexpLocator(func(dependencyName string) string {
+			return `(?i)<package\s+id\s*=\s*"(?P<name>` + regexp.QuoteMeta(dependencyName) + `)"(?:\s+version\s*=\s*"(?P<version>[^"]+)")?`
+		})
+	}
+	return nil
+}
+
+// Creates a locator from a regexp with a 'name' group, and an optional 'version' group.
+// The regexp may have several alternatives, each with its own 'name' and 'version' groups.
+func newRegexpLocator(getPattern func(dependencyName string) string) dependencyLocator {
+	return func(content, dependencyName string) (declarations []dependencyDeclaration) {
+		declarationRegexp := regexp.MustCompile(getPattern(dependencyName))
+		for _, match := range declarationRegexp.FindAllStringSubmatchIndex(content, -1) {
+			declaration := dependencyDeclaration{nameOffset: -1, versionStart: -1, versionEnd: -1}
+			for groupIndex, groupName := range declarationRegexp.SubexpNames() {
+				if match[2*groupIndex] < 0 {
+					// The group didn't participate in the match
+					continue
+				}
+				switch groupName {
+				case "name":
+					declaration.nameOffset = match[2*groupIndex]
+				case "version":
+					declaration.versionStart, declaration.versionEnd = match[2*groupIndex], match[2*groupIndex+1]
+				}
+			}
+			declarations = append(declarations, declaration)
+		}
+		return
+	}
+}
+
+var pythonPackageNameSeparatorsRegexp = regexp.MustCompile(`[-_.]+`)
+
+// Python package names are case-insensitive, and '-', '_' and '.' are interchangeable.
+func getPythonPackageNamePattern(packageName string) string {
+	var nameParts []string
+	for _, namePart := range pythonPackageNameSeparatorsRegexp.Split(packageName, -1) {
+		nameParts = append(nameParts, regexp.QuoteMeta(namePart))
+	}
+	return strings.Join(nameParts, `[-_.]+`)
+}
+
+var (
+	pomDependencyRegexp = regexp.MustCompile(`(?s)<dependency>.*?</dependency>`)
+	pomVersionRegexp    = regexp.MustCompile(`<version>\s*([^<\s]+)\s*</version>`)
+	pomPropertyRegexp   = regexp.MustCompile(`^\$\{(.+)}$`)
+)
+
+// Locates a dependency declared in pom.xml, where the dependency name is 'groupId:artifactId'.
+// If the version is a property reference, like ${lib.version}, the version's location is the property's value.
+func locatePomXmlDependency(content, dependencyName string) (declarations []dependencyDeclaration) {
+	groupId, artifactId, found := strings.Cut(dependencyName, ":")
+	if !found {
+		return
+	}
+	groupIdRegexp := regexp.MustCompile(`<groupId>\s*` + regexp.QuoteMeta(groupId) + `\s*</groupId>`)
+	artifactIdRegexp := regexp.MustCompile(`<artifactId>\s*` + regexp.QuoteMeta(artifactId) + `\s*</artifactId>`)
+
+	for _, dependencyMatch := range pomDependencyRegexp.FindAllStringIndex(content, -1) {
+		dependency := content[dependencyMatch[0]:dependencyMatch[1]]
+		artifactIdMatch := artifactIdRegexp.FindStringIndex(dependency)
+		if artifactIdMatch == nil || !groupIdRegexp.MatchString(dependency) {
+			continue
+		}
+		declaration := dependencyDeclaration{nameOffset: dependencyMatch[0] + artifactIdMatch[0], versionStart: -1, versionEnd: -1}
+		if versionMatch := pomVersionRegexp.FindStringSubmatchIndex(dependency); versionMatch != nil {
+			version := dependency[versionMatch[2]:versionMatch[3]]
+			if propertyMatch := pomPropertyRegexp.FindStringSubmatch(version); propertyMatch != nil {
+				propertyValueRegexp := regexp.MustCompile(`<` + regexp.QuoteMeta(propertyMatch[1]) + `>\s*([^<\s]+)\s*</` + regexp.QuoteMeta(propertyMatch[1]) + `>`)
+				if propertyValueMatch := propertyValueRegexp.FindStringSubmatchIndex(content); propertyValueMatch != nil {
+					declaration.versionStart, declaration.versionEnd = propertyValueMatch[2], propertyValueMatch[3]
+					declaration.versionProperty = propertyMatch[1]
+				}
+			} else {
+				declaration.versionStart, declaration.versionEnd = dependencyMatch[0]+versionMatch[2], dependencyMatch[0]+versionMatch[3]
+			}
+		}
+		// Otherwise, the version is managed by a parent POM or a BOM
+		declarations = append(declarations, declaration)
+	}
+	return
+}
+
+// Returns the version as it should be written in the descriptor.
+func formatDescriptorVersion(descriptorPath, version string) string {
+	if filepath.Base(descriptorPath) == "go.mod" && !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
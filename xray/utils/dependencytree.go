@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+type DependencyTreeFormat string
+
+const (
+	// An indented tree, like the output of 'npm ls'.
+	DependencyTreeText DependencyTreeFormat = "tree"
+	// The dependency trees as they are sent to Xray, along with the descriptors they were built from.
+	DependencyTreeJson DependencyTreeFormat = "json"
+	// A Graphviz directed graph.
+	DependencyTreeDot DependencyTreeFormat = "dot"
+	// A Mermaid flowchart, which can be embedded in Markdown documents.
+	DependencyTreeMermaid DependencyTreeFormat = "mermaid"
+)
+
+var DependencyTreeFormats = []string{string(DependencyTreeText), string(DependencyTreeJson), string(DependencyTreeDot), string(DependencyTreeMermaid)}
+
+// GetDependencyTreeFormat converts a dependency tree format name to a DependencyTreeFormat. An empty name means the dependency trees shouldn't be printed.
+func GetDependencyTreeFormat(format string) (DependencyTreeFormat, error) {
+	if format == "" {
+		return "", nil
+	}
+	for _, treeFormat := range DependencyTreeFormats {
+		if strings.EqualFold(format, treeFormat) {
+			return DependencyTreeFormat(treeFormat), nil
+		}
+	}
+	return "", errorutils.CheckErrorf("only the following dependency tree formats are supported: " + coreutils.ListToText(DependencyTreeFormats))
+}
+
+type dependencyTreeJson struct {
+	DescriptorPath string              `json:"descriptorPath,omitempty"`
+	Tree           *services.GraphNode `json:"tree"`
+}
+
+// PrintDependencyTrees prints the dependency trees of the audited modules in the given format.
+// descriptorPaths holds the path of the descriptor each of the trees was built from (by index), and may be nil.
+func PrintDependencyTrees(dependencyTrees []*services.GraphNode, descriptorPaths []string, format DependencyTreeFormat) error {
+	output, err := getDependencyTreesOutput(dependencyTrees, descriptorPaths, format)
+	if err != nil {
+		return err
+	}
+	log.Output(output)
+	return nil
+}
+
+func getDependencyTreesOutput(dependencyTrees []*services.GraphNode, descriptorPaths []string, format DependencyTreeFormat) (string, error) {
+	switch format {
+	case DependencyTreeText:
+		return getTextDependencyTrees(dependencyTrees, descriptorPaths), nil
+	case DependencyTreeJson:
+		var trees []dependencyTreeJson
+		for i, tree := range dependencyTrees {
+			trees = append(trees, dependencyTreeJson{DescriptorPath: getDescriptorPath(descriptorPaths, i, false), Tree: tree})
+		}
+		out, err := json.MarshalIndent(trees, "", "  ")
+		return string(out), errorutils.CheckError(err)
+	case DependencyTreeDot:
+		return getDotDependencyGraph(dependencyTrees), nil
+	case DependencyTreeMermaid:
+		return getMermaidDependencyGraph(dependencyTrees), nil
+	}
+	return "", errorutils.CheckErrorf("unsupported dependency tree format: %s", format)
+}
+
+func getTextDependencyTrees(dependencyTrees []*services.GraphNode, descriptorPaths []string) string {
+	var builder strings.Builder
+	for i, tree := range dependencyTrees {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		if descriptorPath := getDescriptorPath(descriptorPaths, i, true); descriptorPath != "" {
+			builder.WriteString(descriptorPath + "\n")
+		}
+		builder.WriteString(tree.Id + "\n")
+		writeTextDependencies(&builder, tree, "")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func writeTextDependencies(builder *strings.Builder, node *services.GraphNode, indent string) {
+	for i, child := range node.Nodes {
+		branch, childIndent := "├── ", "│   "
+		if i == len(node.Nodes)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+		builder.WriteString(indent + branch + child.Id + "\n")
+		writeTextDependencies(builder, child, indent+childIndent)
+	}
+}
+
+// A dependency that appears in several trees, or several times in the same tree, is a single node of the graph.
+func getDotDependencyGraph(dependencyTrees []*services.GraphNode) string {
+	lines := []string{"digraph dependencies {", "  rankdir=LR;"}
+	for _, tree := range dependencyTrees {
+		lines = append(lines, fmt.Sprintf("  %s [shape=box];", strconv.Quote(tree.Id)))
+	}
+	forEachDependencyEdge(dependencyTrees, func(parent, child string) {
+		lines = append(lines, fmt.Sprintf("  %s -> %s;", strconv.Quote(parent), strconv.Quote(child)))
+	})
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n")
+}
+
+// Mermaid node IDs can't contain most special characters, so each dependency gets a generated ID, and its component ID is used as its label.
+func getMermaidDependencyGraph(dependencyTrees []*services.GraphNode) string {
+	nodes := []string{"graph LR"}
+	var edges []string
+	nodesIds := make(map[string]string)
+	getNodeId := func(componentId string) string {
+		nodeId, exists := nodesIds[componentId]
+		if !exists {
+			nodeId = "n" + strconv.Itoa(len(nodesIds))
+			nodesIds[componentId] = nodeId
+			nodes = append(nodes, fmt.Sprintf(`  %s["%s"]`, nodeId, strings.ReplaceAll(componentId, `"`, "#quot;")))
+		}
+		return nodeId
+	}
+	for _, tree := range dependencyTrees {
+		getNodeId(tree.Id)
+	}
+	forEachDependencyEdge(dependencyTrees, func(parent, child string) {
+		edges = append(edges, fmt.Sprintf("  %s --> %s", getNodeId(parent), getNodeId(child)))
+	})
+	return strings.Join(append(nodes, edges...), "\n")
+}
+
+// Calls handleEdge once for each distinct parent-child pair in the trees, in the order of their first appearance.
+func forEachDependencyEdge(dependencyTrees []*services.GraphNode, handleEdge func(parent, child string)) {
+	visitedEdges := make(map[[2]string]bool)
+	var visit func(node *services.GraphNode)
+	visit = func(node *services.GraphNode) {
+		for _, child := range node.Nodes {
+			edge := [2]string{node.Id, child.Id}
+			if visitedEdges[edge] {
+				continue
+			}
+			visitedEdges[edge] = true
+			handleEdge(node.Id, child.Id)
+			visit(child)
+		}
+	}
+	for _, tree := range dependencyTrees {
+		visit(tree)
+	}
+}
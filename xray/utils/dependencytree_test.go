@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestDependencyTrees() []*services.GraphNode {
+	shared := &services.GraphNode{Id: "npm://shared:1.0.0"}
+	return []*services.GraphNode{
+		{Id: "npm://frontend:1.0.0", Nodes: []*services.GraphNode{
+			{Id: "npm://a:1.0.0", Nodes: []*services.GraphNode{shared}},
+			{Id: "npm://b:2.0.0", Nodes: []*services.GraphNode{shared}},
+		}},
+		{Id: "npm://backend:1.0.0", Nodes: []*services.GraphNode{shared}},
+	}
+}
+
+func TestGetDependencyTreeFormat(t *testing.T) {
+	format, err := GetDependencyTreeFormat("")
+	assert.NoError(t, err)
+	assert.Empty(t, format)
+	format, err = GetDependencyTreeFormat("Mermaid")
+	assert.NoError(t, err)
+	assert.Equal(t, DependencyTreeMermaid, format)
+	_, err = GetDependencyTreeFormat("svg")
+	assert.Error(t, err)
+}
+
+func TestGetDependencyTreesOutput(t *testing.T) {
+	trees := createTestDependencyTrees()
+	frontendDescriptor, err := filepath.Abs(filepath.Join("frontend", "package.json"))
+	assert.NoError(t, err)
+	descriptorPaths := []string{frontendDescriptor}
+
+	output, err := getDependencyTreesOutput(trees, descriptorPaths, DependencyTreeText)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("frontend", "package.json")+`
+npm://frontend:1.0.0
+├── npm://a:1.0.0
+│   └── npm://shared:1.0.0
+└── npm://b:2.0.0
+    └── npm://shared:1.0.0
+
+npm://backend:1.0.0
+└── npm://shared:1.0.0`, output)
+
+	output, err = getDependencyTreesOutput(trees, descriptorPaths, DependencyTreeJson)
+	assert.NoError(t, err)
+	var jsonTrees []dependencyTreeJson
+	assert.NoError(t, json.Unmarshal([]byte(output), &jsonTrees))
+	if assert.Len(t, jsonTrees, 2) {
+		assert.Equal(t, frontendDescriptor, jsonTrees[0].DescriptorPath)
+		assert.Equal(t, "npm://a:1.0.0", jsonTrees[0].Tree.Nodes[0].Id)
+		assert.Empty(t, jsonTrees[1].DescriptorPath)
+	}
+
+	// Shared dependencies are single nodes in the graphs
+	output, err = getDependencyTreesOutput(trees, descriptorPaths, DependencyTreeDot)
+	assert.NoError(t, err)
+	assert.Equal(t, `digraph dependencies {
+  rankdir=LR;
+  "npm://frontend:1.0.0" [shape=box];
+  "npm://backend:1.0.0" [shape=box];
+  "npm://frontend:1.0.0" -> "npm://a:1.0.0";
+  "npm://a:1.0.0" -> "npm://shared:1.0.0";
+  "npm://frontend:1.0.0" -> "npm://b:2.0.0";
+  "npm://b:2.0.0" -> "npm://shared:1.0.0";
+  "npm://backend:1.0.0" -> "npm://shared:1.0.0";
+}`, output)
+
+	output, err = getDependencyTreesOutput(trees, descriptorPaths, DependencyTreeMermaid)
+	assert.NoError(t, err)
+	assert.Equal(t, `graph LR
+  n0["npm://frontend:1.0.0"]
+  n1["npm://backend:1.0.0"]
+  n2["npm://a:1.0.0"]
+  n3["npm://shared:1.0.0"]
+  n4["npm://b:2.0.0"]
+  n0 --> n2
+  n2 --> n3
+  n0 --> n4
+  n4 --> n3
+  n1 --> n3`, output)
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// The descriptors that can be patched, by file name.
+var patchableDescriptors = map[string]bool{"package.json": true, "go.mod": true, "requirements.txt": true, "pom.xml": true}
+
+// DescriptorPatch holds the content of a descriptor file before and after upgrading the dependencies suggested by the remediation.
+type DescriptorPatch struct {
+	DescriptorPath  string
+	OriginalContent string
+	PatchedContent  string
+}
+
+// CreateDescriptorPatches applies the upgrades suggested in the remediations (see GetRemediations) to the content of the descriptors they refer to, without changing the files.
+// Descriptors of types other than package.json, go.mod, requirements.txt and pom.xml are skipped, as well as dependencies that aren't declared in the descriptor (like dependencies of Maven parent POMs).
+func CreateDescriptorPatches(remediations []formats.RemediationRow) (patches []DescriptorPatch, err error) {
+	remediationsByDescriptor := make(map[string][]formats.RemediationRow)
+	for _, remediation := range remediations {
+		if remediation.SuggestedVersion == "" || remediation.DescriptorPath == "" {
+			continue
+		}
+		remediationsByDescriptor[remediation.DescriptorPath] = append(remediationsByDescriptor[remediation.DescriptorPath], remediation)
+	}
+	var descriptorPaths []string
+	for descriptorPath := range remediationsByDescriptor {
+		descriptorPaths = append(descriptorPaths, descriptorPath)
+	}
+	sort.Strings(descriptorPaths)
+
+	for _, descriptorPath := range descriptorPaths {
+		if !patchableDescriptors[filepath.Base(descriptorPath)] {
+			log.Info(fmt.Sprintf("Skipping %s: only package.json, go.mod, requirements.txt and pom.xml descriptors can be patched.", descriptorPath))
+			continue
+		}
+		content, err := os.ReadFile(descriptorPath)
+		if errorutils.CheckError(err) != nil {
+			return nil, err
+		}
+		patch := DescriptorPatch{DescriptorPath: descriptorPath, OriginalContent: string(content), PatchedContent: string(content)}
+		for _, remediation := range remediationsByDescriptor[descriptorPath] {
+			var found bool
+			patch.PatchedContent, found = upgradeDependency(descriptorPath, patch.PatchedContent, remediation.DirectDependencyName, remediation.SuggestedVersion)
+			if !found {
+				log.Warn(fmt.Sprintf("Couldn't find the dependency %s in %s. Upgrade it to version %s manually.", remediation.DirectDependencyName, descriptorPath, remediation.SuggestedVersion))
+			}
+		}
+		if patch.PatchedContent != patch.OriginalContent {
+			patches = append(patches, patch)
+		}
+	}
+	return
+}
+
+// GetUnifiedDiff returns the changes in the descriptor in the unified diff format.
+// The descriptor path is written relative to the current working directory, if it's under it.
+func (dp *DescriptorPatch) GetUnifiedDiff() (string, error) {
+	displayedPath := filepath.ToSlash(getDescriptorPath([]string{dp.DescriptorPath}, 0, true))
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(dp.OriginalContent),
+		B:        difflib.SplitLines(dp.PatchedContent),
+		FromFile: "a/" + displayedPath,
+		ToFile:   "b/" + displayedPath,
+		Context:  3,
+	})
+	return diff, errorutils.CheckError(err)
+}
+
+// Apply writes the patched content to the descriptor.
+func (dp *DescriptorPatch) Apply() error {
+	fileInfo, err := os.Stat(dp.DescriptorPath)
+	if errorutils.CheckError(err) != nil {
+		return err
+	}
+	return errorutils.CheckError(os.WriteFile(dp.DescriptorPath, []byte(dp.PatchedContent), fileInfo.Mode()))
+}
+
+// Replaces the declared versions of a dependency in the content of a descriptor with the suggested version.
+// Versions set by a property that also sets the versions of other dependencies aren't replaced, since that would upgrade the other dependencies too.
+// Returns false if no version of the dependency is declared in the descriptor (like dependencies managed by Maven parent POMs).
+func upgradeDependency(descriptorPath, content, dependencyName, suggestedVersion string) (string, bool) {
+	declarations := getDependencyLocator(descriptorPath)(content, dependencyName)
+	propertiesReferences := make(map[string]int)
+	for _, declaration := range declarations {
+		if declaration.versionProperty != "" {
+			propertiesReferences[declaration.versionProperty]++
+		}
+	}
+	var versionsOffsets [][2]int
+	var sharedProperties []string
+	for _, declaration := range declarations {
+		if !declaration.hasVersion() {
+			continue
+		}
+		if property := declaration.versionProperty; property != "" && strings.Count(content, "${"+property+"}") > propertiesReferences[property] {
+			sharedProperties = coreutils.AppendUnique(sharedProperties, property)
+			continue
+		}
+		versionsOffsets = append(versionsOffsets, [2]int{declaration.versionStart, declaration.versionEnd})
+	}
+	for _, property := range sharedProperties {
+		log.Warn(fmt.Sprintf("The version of %s is set by the %s property in %s, which is used by other dependencies too. Upgrade %s to version %s manually.",
+			dependencyName, property, descriptorPath, dependencyName, suggestedVersion))
+	}
+	if len(versionsOffsets) == 0 {
+		return content, len(sharedProperties) > 0
+	}
+	// Several dependencies may refer to the same Maven property
+	sort.Slice(versionsOffsets, func(i, j int) bool {
+		return versionsOffsets[i][0] < versionsOffsets[j][0]
+	})
+	var builder strings.Builder
+	lastIndex := 0
+	for _, versionOffsets := range versionsOffsets {
+		if versionOffsets[0] < lastIndex {
+			continue
+		}
+		builder.WriteString(content[lastIndex:versionOffsets[0]])
+		builder.WriteString(formatDescriptorVersion(descriptorPath, suggestedVersion))
+		lastIndex = versionOffsets[1]
+	}
+	builder.WriteString(content[lastIndex:])
+	return builder.String(), true
+}
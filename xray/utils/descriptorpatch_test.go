@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/tests"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDescriptorPatches(t *testing.T) {
+	testCases := []struct {
+		fileName         string
+		content          string
+		dependencyName   string
+		suggestedVersion string
+		expectedContent  string
+	}{
+		{
+			fileName:         "package.json",
+			content:          "{\n  \"name\": \"app\",\n  \"dependencies\": {\n    \"lodash\": \"^4.17.15\",\n    \"lodash-es\": \"4.17.15\"\n  }\n}\n",
+			dependencyName:   "lodash",
+			suggestedVersion: "4.17.21",
+			expectedContent:  "{\n  \"name\": \"app\",\n  \"dependencies\": {\n    \"lodash\": \"^4.17.21\",\n    \"lodash-es\": \"4.17.15\"\n  }\n}\n",
+		},
+		{
+			fileName:         "go.mod",
+			content:          "module app\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.7.0\n\tgolang.org/x/text v0.3.6 // indirect\n)\n\nreplace golang.org/x/text v0.3.6 => ../text\n",
+			dependencyName:   "golang.org/x/text",
+			suggestedVersion: "0.3.8",
+			expectedContent:  "module app\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.7.0\n\tgolang.org/x/text v0.3.8 // indirect\n)\n\nreplace golang.org/x/text v0.3.6 => ../text\n",
+		},
+		{
+			fileName:         "requirements.txt",
+			content:          "requests==2.25.0\nPyYAML>=5.3 ; python_version >= '3.6'\n",
+			dependencyName:   "pyyaml",
+			suggestedVersion: "5.4",
+			expectedContent:  "requests==2.25.0\nPyYAML>=5.4 ; python_version >= '3.6'\n",
+		},
+		{
+			fileName: "pom.xml",
+			content: "<project>\n  <properties>\n    <jackson.version>2.9.8</jackson.version>\n  </properties>\n  <dependencies>\n" +
+				"    <dependency>\n      <groupId>com.fasterxml.jackson.core</groupId>\n      <artifactId>jackson-databind</artifactId>\n      <version>${jackson.version}</version>\n    </dependency>\n" +
+				"  </dependencies>\n</project>\n",
+			dependencyName:   "com.fasterxml.jackson.core:jackson-databind",
+			suggestedVersion: "2.9.10",
+			expectedContent: "<project>\n  <properties>\n    <jackson.version>2.9.10</jackson.version>\n  </properties>\n  <dependencies>\n" +
+				"    <dependency>\n      <groupId>com.fasterxml.jackson.core</groupId>\n      <artifactId>jackson-databind</artifactId>\n      <version>${jackson.version}</version>\n    </dependency>\n" +
+				"  </dependencies>\n</project>\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.fileName, func(t *testing.T) {
+			descriptorPath := filepath.Join(t.TempDir(), testCase.fileName)
+			assert.NoError(t, os.WriteFile(descriptorPath, []byte(testCase.content), 0644))
+			remediations := []formats.RemediationRow{
+				{DirectDependencyName: testCase.dependencyName, SuggestedVersion: testCase.suggestedVersion, DescriptorPath: descriptorPath},
+				// Not declared in the descriptor
+				{DirectDependencyName: "missing:dependency", SuggestedVersion: "1.0.0", DescriptorPath: descriptorPath},
+			}
+			patches, err := CreateDescriptorPatches(remediations)
+			assert.NoError(t, err)
+			if assert.Len(t, patches, 1) {
+				assert.Equal(t, testCase.expectedContent, patches[0].PatchedContent)
+				assert.NoError(t, patches[0].Apply())
+				content, err := os.ReadFile(descriptorPath)
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedContent, string(content))
+			}
+		})
+	}
+}
+
+func TestCreateDescriptorPatchesSharedPomProperty(t *testing.T) {
+	content := "<project>\n  <properties>\n    <jackson.version>2.9.8</jackson.version>\n  </properties>\n  <dependencies>\n" +
+		"    <dependency>\n      <groupId>com.fasterxml.jackson.core</groupId>\n      <artifactId>jackson-databind</artifactId>\n      <version>${jackson.version}</version>\n    </dependency>\n" +
+		"    <dependency>\n      <groupId>com.fasterxml.jackson.core</groupId>\n      <artifactId>jackson-core</artifactId>\n      <version>${jackson.version}</version>\n    </dependency>\n" +
+		"  </dependencies>\n</project>\n"
+	descriptorPath := filepath.Join(t.TempDir(), "pom.xml")
+	assert.NoError(t, os.WriteFile(descriptorPath, []byte(content), 0644))
+
+	// Upgrading jackson-databind through the property would silently upgrade jackson-core too
+	_, stderrBuffer, previousLog := tests.RedirectLogOutputToBuffer()
+	defer log.SetLogger(previousLog)
+	patches, err := CreateDescriptorPatches([]formats.RemediationRow{{DirectDependencyName: "com.fasterxml.jackson.core:jackson-databind", SuggestedVersion: "2.9.10", DescriptorPath: descriptorPath}})
+	assert.NoError(t, err)
+	assert.Empty(t, patches)
+	assert.Contains(t, stderrBuffer.String(), "set by the jackson.version property")
+	assert.NotContains(t, stderrBuffer.String(), "Couldn't find the dependency")
+}
+
+func TestDescriptorPatchUnifiedDiff(t *testing.T) {
+	patch := DescriptorPatch{
+		DescriptorPath:  filepath.Join(t.TempDir(), "requirements.txt"),
+		OriginalContent: "requests==2.25.0\nurllib3==1.26.4\n",
+		PatchedContent:  "requests==2.25.0\nurllib3==1.26.5\n",
+	}
+	diff, err := patch.GetUnifiedDiff()
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "-urllib3==1.26.4\n+urllib3==1.26.5\n")
+	assert.Contains(t, diff, "requirements.txt")
+}
@@ -22,21 +22,66 @@ import (
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray"
+	"golang.org/x/exp/slices"
 )
 
 const (
 	indexerDirName     = "xray-indexer"
 	tempIndexerDirName = "temp"
+	// The SHA-256 checksum of a downloaded indexer is saved next to it, in a file with the indexer's name and this suffix.
+	indexerChecksumFileSuffix = ".sha256"
+	// The number of the newest indexers kept when a new indexer is downloaded, and by default when the indexers are pruned.
+	DefaultIndexersToKeep = 2
 )
 
+// Selects and verifies the Xray Indexer used for scanning.
+type IndexerSettings struct {
+	// The path of an indexer binary to use, instead of the downloaded indexers.
+	Path string
+	// The version of the indexer to use. If it isn't cached locally, it is downloaded from Xray, which must serve this version.
+	// By default, the indexer matching the Xray version is used.
+	Version string
+	// The expected SHA-256 checksum of the indexer binary. The indexer isn't executed if its checksum is different.
+	Sha256 string
+}
+
+// A locally cached Xray Indexer.
+type CachedIndexer struct {
+	Version string
+	Path    string
+	// The checksum of the binary, as calculated when it was downloaded. Empty if it wasn't saved.
+	Sha256 string
+	// True if the binary wasn't modified since it was downloaded.
+	Verified bool
+}
+
 func DownloadIndexerIfNeeded(xrayManager *xray.XrayServicesManager, xrayVersionStr string) (indexerPath string, err error) {
-	dependenciesPath, err := config.GetJfrogDependenciesPath()
+	return GetIndexer(xrayManager, xrayVersionStr, nil)
+}
+
+// GetIndexer returns the path of the Xray Indexer to use according to settings, after verifying its checksum.
+// Unless settings sets the path of the indexer, the indexer is downloaded from Xray if it isn't cached locally.
+func GetIndexer(xrayManager *xray.XrayServicesManager, xrayVersionStr string, settings *IndexerSettings) (indexerPath string, err error) {
+	if settings == nil {
+		settings = &IndexerSettings{}
+	}
+	if settings.Path != "" {
+		log.Debug("Using the Xray Indexer at " + settings.Path)
+		if err = verifyIndexerChecksum(settings.Path, settings.Sha256); err != nil {
+			return "", err
+		}
+		return settings.Path, nil
+	}
+	indexerVersion := xrayVersionStr
+	if settings.Version != "" {
+		indexerVersion = settings.Version
+	}
+	indexerDirPath, err := GetIndexersDir()
 	if err != nil {
 		return
 	}
-	indexerDirPath := filepath.Join(dependenciesPath, indexerDirName)
 	indexerBinaryName := getIndexerBinaryName()
-	indexerPath = filepath.Join(indexerDirPath, xrayVersionStr, indexerBinaryName)
+	indexerPath = filepath.Join(indexerDirPath, indexerVersion, indexerBinaryName)
 
 	locksDirPath, err := coreutils.GetJfrogLocksDir()
 	if err != nil {
@@ -54,19 +99,95 @@ func DownloadIndexerIfNeeded(xrayManager *xray.XrayServicesManager, xrayVersionS
 		return
 	}
 	exists, err := fileutils.IsFileExists(indexerPath, false)
-	if exists || err != nil {
+	if err != nil {
 		return
 	}
+	if !exists {
+		log.Info("JFrog Xray Indexer " + indexerVersion + " is not cached locally. Downloading it now...")
+		indexerPath, err = downloadIndexer(xrayManager, indexerDirPath, indexerBinaryName, settings)
+		if err != nil {
+			return "", errors.New("failed while attempting to download Xray indexer: " + err.Error())
+		}
+	}
+	if err = verifyCachedIndexer(indexerPath, settings.Sha256); err != nil {
+		return "", err
+	}
+	return
+}
 
-	log.Info("JFrog Xray Indexer " + xrayVersionStr + " is not cached locally. Downloading it now...")
-	indexerPath, err = downloadIndexer(xrayManager, indexerDirPath, indexerBinaryName)
+// GetIndexersDir returns the directory of the locally cached Xray Indexers. Each indexer is in a subdirectory named after its version.
+func GetIndexersDir() (string, error) {
+	dependenciesPath, err := config.GetJfrogDependenciesPath()
 	if err != nil {
-		err = errors.New("failed while attempting to download Xray indexer: " + err.Error())
+		return "", err
+	}
+	return filepath.Join(dependenciesPath, indexerDirName), nil
+}
+
+// GetCachedIndexers returns the locally cached Xray Indexers, from the newest version to the oldest.
+func GetCachedIndexers() (indexers []CachedIndexer, err error) {
+	indexerDirPath, err := GetIndexersDir()
+	if err != nil {
+		return
+	}
+	versions, err := getCachedIndexersVersions(indexerDirPath)
+	if err != nil {
+		return
+	}
+	for _, indexerVersion := range versions {
+		indexerPath := filepath.Join(indexerDirPath, indexerVersion, getIndexerBinaryName())
+		exists, err := fileutils.IsFileExists(indexerPath, false)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		indexer := CachedIndexer{Version: indexerVersion, Path: indexerPath}
+		if indexer.Sha256, err = readIndexerChecksumFile(indexerPath); err != nil {
+			return nil, err
+		}
+		if indexer.Sha256 != "" {
+			actualSha256, err := getFileSha256(indexerPath)
+			if err != nil {
+				return nil, err
+			}
+			indexer.Verified = actualSha256 == indexer.Sha256
+		}
+		indexers = append(indexers, indexer)
 	}
 	return
 }
 
-func downloadIndexer(xrayManager *xray.XrayServicesManager, indexerDirPath, indexerBinaryName string) (string, error) {
+// PruneIndexers deletes the locally cached Xray Indexers, except for the newest indexersToKeep indexers and the indexers of versionsToKeep.
+// Returns the versions of the deleted indexers.
+func PruneIndexers(indexersToKeep int, versionsToKeep ...string) (deletedVersions []string, err error) {
+	if indexersToKeep < 0 {
+		return nil, errorutils.CheckErrorf("the number of Xray Indexers to keep can't be negative, but %d was provided", indexersToKeep)
+	}
+	indexerDirPath, err := GetIndexersDir()
+	if err != nil {
+		return
+	}
+	locksDirPath, err := coreutils.GetJfrogLocksDir()
+	if err != nil {
+		return
+	}
+	unlockFunc, err := lock.CreateLock(filepath.Join(locksDirPath, "xray-indexer"))
+	defer func() {
+		e := unlockFunc()
+		if err == nil {
+			err = e
+		}
+	}()
+	if err != nil {
+		return
+	}
+	return pruneIndexers(indexerDirPath, indexersToKeep, versionsToKeep...)
+}
+
+// Downloads the indexer served by Xray. The indexer isn't executed unless its checksum matches settings.Sha256 (if provided) and the checksum sent by Xray (if sent).
+func downloadIndexer(xrayManager *xray.XrayServicesManager, indexerDirPath, indexerBinaryName string, settings *IndexerSettings) (string, error) {
 	tempDirPath := filepath.Join(indexerDirPath, tempIndexerDirName)
 
 	// Delete the temporary directory if it exists
@@ -81,8 +202,8 @@ func downloadIndexer(xrayManager *xray.XrayServicesManager, indexerDirPath, inde
 		}
 	}
 
-	// Delete all old indexers, but the two newest
-	err = deleteOldIndexers(indexerDirPath)
+	// Delete all old indexers, but the two newest and the pinned version
+	err = deleteOldIndexers(indexerDirPath, settings.Version)
 	if err != nil {
 		return "", err
 	}
@@ -108,8 +229,21 @@ func downloadIndexer(xrayManager *xray.XrayServicesManager, indexerDirPath, inde
 		return "", errorutils.CheckErrorf("%s received when attempting to download %s\n%s", resp.Status, url, body)
 	}
 
-	// Add execution permissions to the indexer
+	if err != nil {
+		return "", err
+	}
+
+	// Verify the indexer before executing it
 	indexerPath := filepath.Join(tempDirPath, indexerBinaryName)
+	actualSha256, err := verifyDownloadedIndexer(indexerPath, resp.Header.Get("X-Checksum-Sha256"), settings.Sha256)
+	if err != nil {
+		return "", err
+	}
+	if err = writeIndexerChecksumFile(indexerPath, actualSha256); err != nil {
+		return "", err
+	}
+
+	// Add execution permissions to the indexer
 	err = os.Chmod(indexerPath, 0777)
 	if err != nil {
 		return "", errorutils.CheckError(err)
@@ -119,6 +253,14 @@ func downloadIndexer(xrayManager *xray.XrayServicesManager, indexerDirPath, inde
 		return "", err
 	}
 	log.Info("The downloaded Xray Indexer version is " + indexerVersion)
+	if settings.Version != "" && indexerVersion != settings.Version {
+		// Don't cache an indexer of a version that wasn't requested
+		if err = fileutils.RemoveTempDir(tempDirPath); err != nil {
+			return "", errorutils.CheckError(err)
+		}
+		return "", errorutils.CheckErrorf("Xray Indexer %s is required, but Xray serves version %s. "+
+			"Download Xray Indexer %s from a matching Xray server to the cache or set the path of the indexer binary.", settings.Version, indexerVersion, settings.Version)
+	}
 	newDirPath := filepath.Join(indexerDirPath, indexerVersion)
 
 	// In case of a hot upgrade of Xray in progress, the version of the downloaded indexer might be different from the Xray version we got above,
@@ -151,42 +293,137 @@ func getIndexerVersion(indexerPath string) (string, error) {
 	return indexerVersion, nil
 }
 
-func deleteOldIndexers(indexerDirPath string) error {
+func deleteOldIndexers(indexerDirPath string, versionsToKeep ...string) error {
+	_, err := pruneIndexers(indexerDirPath, DefaultIndexersToKeep, versionsToKeep...)
+	return err
+}
+
+func pruneIndexers(indexerDirPath string, indexersToKeep int, versionsToKeep ...string) (deletedVersions []string, err error) {
+	versions, err := getCachedIndexersVersions(indexerDirPath)
+	if err != nil || len(versions) <= indexersToKeep {
+		return
+	}
+	for _, indexerVersion := range versions[indexersToKeep:] {
+		if slices.Contains(versionsToKeep, indexerVersion) {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(indexerDirPath, indexerVersion))
+		if err != nil {
+			return nil, errorutils.CheckError(err)
+		}
+		deletedVersions = append(deletedVersions, indexerVersion)
+	}
+	return
+}
+
+// Returns the versions of the indexers in indexerDirPath, from the newest to the oldest.
+func getCachedIndexersVersions(indexerDirPath string) ([]string, error) {
 	indexerDirExists, err := fileutils.IsDirExists(indexerDirPath, false)
 	if !indexerDirExists || err != nil {
-		return err
+		return nil, err
 	}
 
 	filesList, err := ioutil.ReadDir(indexerDirPath)
 	if err != nil {
-		return errorutils.CheckError(err)
+		return nil, errorutils.CheckError(err)
 	}
 	var dirsList []string
 	for _, file := range filesList {
-		if file.IsDir() {
+		if file.IsDir() && file.Name() != tempIndexerDirName {
 			dirsList = append(dirsList, file.Name())
 		}
 	}
 
-	if len(dirsList) <= 2 {
-		return nil
-	}
-
 	sort.Slice(dirsList, func(i, j int) bool {
 		currVersion := version.NewVersion(dirsList[i])
 		return currVersion.AtLeast(dirsList[j])
 	})
+	return dirsList, nil
+}
 
-	for i := 2; i < len(dirsList); i++ {
-		err = os.RemoveAll(filepath.Join(indexerDirPath, dirsList[i]))
-		if err != nil {
-			return errorutils.CheckError(err)
+// Verifies that the cached indexer wasn't modified since it was downloaded, and that it has the expected checksum, if provided.
+func verifyCachedIndexer(indexerPath, expectedSha256 string) error {
+	actualSha256, err := getFileSha256(indexerPath)
+	if err != nil {
+		return err
+	}
+	savedSha256, err := readIndexerChecksumFile(indexerPath)
+	if err != nil {
+		return err
+	}
+	if savedSha256 == "" {
+		// Indexers downloaded by former versions have no saved checksums
+		log.Debug("The checksum of the Xray Indexer " + indexerPath + " wasn't saved when it was downloaded. Saving its current checksum.")
+		if err = writeIndexerChecksumFile(indexerPath, actualSha256); err != nil {
+			return err
 		}
+	} else if savedSha256 != actualSha256 {
+		return errorutils.CheckErrorf("the Xray Indexer %s was modified after it was downloaded: its SHA-256 checksum is %s, while %s was expected. "+
+			"Delete its directory to download it again.", indexerPath, actualSha256, savedSha256)
 	}
+	return compareIndexerChecksum(indexerPath, actualSha256, expectedSha256)
+}
+
+// Verifies a downloaded indexer against the checksum sent by Xray and the expected checksum, and returns its checksum.
+// If neither of them is available, the indexer can't be verified, and a warning is logged.
+func verifyDownloadedIndexer(indexerPath, xraySha256, expectedSha256 string) (string, error) {
+	actualSha256, err := getFileSha256(indexerPath)
+	if err != nil {
+		return "", err
+	}
+	if xraySha256 == "" && expectedSha256 == "" {
+		log.Warn("Xray didn't send the checksum of the downloaded Xray Indexer, and no checksum of the indexer was configured, so the indexer can't be verified. " +
+			"Its SHA-256 checksum is " + actualSha256 + ". Configure the expected checksum of the indexer to verify it.")
+		return actualSha256, nil
+	}
+	if err = compareIndexerChecksum(indexerPath, actualSha256, xraySha256); err != nil {
+		return "", err
+	}
+	return actualSha256, compareIndexerChecksum(indexerPath, actualSha256, expectedSha256)
+}
+
+func verifyIndexerChecksum(indexerPath, expectedSha256 string) error {
+	if expectedSha256 == "" {
+		return nil
+	}
+	actualSha256, err := getFileSha256(indexerPath)
+	if err != nil {
+		return err
+	}
+	return compareIndexerChecksum(indexerPath, actualSha256, expectedSha256)
+}
 
+func compareIndexerChecksum(indexerPath, actualSha256, expectedSha256 string) error {
+	if expectedSha256 != "" && !strings.EqualFold(actualSha256, expectedSha256) {
+		return errorutils.CheckErrorf("the SHA-256 checksum of the Xray Indexer %s is %s, while %s was expected", indexerPath, actualSha256, expectedSha256)
+	}
 	return nil
 }
 
+func getFileSha256(filePath string) (string, error) {
+	details, err := fileutils.GetFileDetails(filePath, true)
+	if err != nil {
+		return "", err
+	}
+	return details.Checksum.Sha256, nil
+}
+
+// Returns an empty string if the checksum file doesn't exist.
+func readIndexerChecksumFile(indexerPath string) (string, error) {
+	content, err := os.ReadFile(indexerPath + indexerChecksumFileSuffix)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func writeIndexerChecksumFile(indexerPath, sha256 string) error {
+	return errorutils.CheckError(os.WriteFile(indexerPath+indexerChecksumFileSuffix, []byte(sha256), 0644))
+}
+
 func getIndexerBinaryName() string {
 	switch runtime.GOOS {
 	case "windows":
@@ -1,12 +1,15 @@
 package utils
 
 import (
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/tests"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,6 +39,42 @@ func TestDeleteOldIndexers(t *testing.T) {
 	assert.False(t, checkIndexerExists(t, indexersDirsPaths[0]))
 	assert.True(t, checkIndexerExists(t, indexersDirsPaths[1]))
 	assert.True(t, checkIndexerExists(t, indexersDirsPaths[2]))
+
+	// Test a pinned version is kept along with the two newest versions
+	createDummyIndexer(t, indexersDirsPaths[0])
+	createDummyIndexer(t, filepath.Join(indexersDir, "1.4.0"))
+	assert.NoError(t, deleteOldIndexers(indexersDir, "1.0.0"))
+	assert.True(t, checkIndexerExists(t, indexersDirsPaths[0]))
+	assert.False(t, checkIndexerExists(t, indexersDirsPaths[1]))
+	assert.True(t, checkIndexerExists(t, indexersDirsPaths[2]))
+	assert.True(t, checkIndexerExists(t, filepath.Join(indexersDir, "1.4.0")))
+}
+
+func TestVerifyDownloadedIndexer(t *testing.T) {
+	indexerDir := t.TempDir()
+	createDummyIndexer(t, indexerDir)
+	indexerPath := filepath.Join(indexerDir, getIndexerBinaryName())
+	sha256, err := getFileSha256(indexerPath)
+	assert.NoError(t, err)
+
+	// The indexer is verified against the checksum sent by Xray and the expected checksum
+	for _, checksums := range [][2]string{{sha256, ""}, {"", sha256}, {sha256, strings.ToUpper(sha256)}} {
+		actualSha256, err := verifyDownloadedIndexer(indexerPath, checksums[0], checksums[1])
+		assert.NoError(t, err)
+		assert.Equal(t, sha256, actualSha256)
+	}
+	_, err = verifyDownloadedIndexer(indexerPath, "0123", sha256)
+	assert.ErrorContains(t, err, "while 0123 was expected")
+	_, err = verifyDownloadedIndexer(indexerPath, sha256, "0123")
+	assert.ErrorContains(t, err, "while 0123 was expected")
+
+	// Without any checksum, the indexer can't be verified, and a warning is logged
+	_, stderrBuffer, previousLog := tests.RedirectLogOutputToBuffer()
+	defer log.SetLogger(previousLog)
+	actualSha256, err := verifyDownloadedIndexer(indexerPath, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, sha256, actualSha256)
+	assert.Contains(t, stderrBuffer.String(), "the indexer can't be verified")
 }
 
 func createDummyIndexer(t *testing.T, dirPath string) {
@@ -57,3 +96,54 @@ func checkIndexerExists(t *testing.T, dirPath string) bool {
 	assert.NoError(t, err)
 	return exists
 }
+
+func TestGetCachedIndexer(t *testing.T) {
+	t.Setenv(coreutils.HomeDir, t.TempDir())
+	indexersDir, err := GetIndexersDir()
+	assert.NoError(t, err)
+	for _, indexerVersion := range []string{"1.0.0", "1.2.0", "1.3.0"} {
+		createDummyIndexer(t, filepath.Join(indexersDir, indexerVersion))
+	}
+
+	// The checksum of an indexer without a saved checksum is saved when it's first used
+	indexerPath, err := GetIndexer(nil, "1.2.0", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(indexersDir, "1.2.0", getIndexerBinaryName()), indexerPath)
+	sha256, err := getFileSha256(indexerPath)
+	assert.NoError(t, err)
+	savedSha256, err := readIndexerChecksumFile(indexerPath)
+	assert.NoError(t, err)
+	assert.Equal(t, sha256, savedSha256)
+
+	// A pinned version and checksum
+	_, err = GetIndexer(nil, "1.3.0", &IndexerSettings{Version: "1.2.0", Sha256: strings.ToUpper(sha256)})
+	assert.NoError(t, err)
+	_, err = GetIndexer(nil, "1.2.0", &IndexerSettings{Sha256: "0123"})
+	assert.ErrorContains(t, err, "while 0123 was expected")
+
+	// The indexer was modified since its checksum was saved
+	assert.NoError(t, os.WriteFile(indexerPath, []byte("modified"), 0644))
+	_, err = GetIndexer(nil, "1.2.0", nil)
+	assert.ErrorContains(t, err, "was modified after it was downloaded")
+
+	// An indexer binary set by path is used as is, and verified only against the provided checksum
+	indexerPath, err = GetIndexer(nil, "1.2.0", &IndexerSettings{Path: indexerPath})
+	assert.NoError(t, err)
+	_, err = GetIndexer(nil, "1.2.0", &IndexerSettings{Path: indexerPath, Sha256: sha256})
+	assert.ErrorContains(t, err, "while "+sha256+" was expected")
+
+	indexers, err := GetCachedIndexers()
+	assert.NoError(t, err)
+	if assert.Len(t, indexers, 3) {
+		assert.Equal(t, CachedIndexer{Version: "1.3.0", Path: filepath.Join(indexersDir, "1.3.0", getIndexerBinaryName())}, indexers[0])
+		assert.Equal(t, CachedIndexer{Version: "1.2.0", Path: indexerPath, Sha256: sha256, Verified: false}, indexers[1])
+		assert.Equal(t, "1.0.0", indexers[2].Version)
+	}
+
+	deletedVersions, err := PruneIndexers(1, "1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.2.0"}, deletedVersions)
+	assert.True(t, checkIndexerExists(t, filepath.Join(indexersDir, "1.0.0")))
+	assert.False(t, checkIndexerExists(t, filepath.Join(indexersDir, "1.2.0")))
+	assert.True(t, checkIndexerExists(t, filepath.Join(indexersDir, "1.3.0")))
+}
@@ -0,0 +1,289 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	IgnoreRulesFileName   = "xray-ignore.yaml"
+	ignoreRulesDateLayout = "2006-01-02"
+)
+
+// IgnoreRule suppresses the Xray findings that match all of its non-empty criteria.
+type IgnoreRule struct {
+	// A CVE ID, like CVE-2021-44228
+	Cve string `yaml:"cve,omitempty" json:"cve,omitempty"`
+	// A Xray issue ID, like XRAY-191516
+	IssueId string `yaml:"issueId,omitempty" json:"issueId,omitempty"`
+	// A license key, like GPL-3.0. Matches licenses and license violations.
+	License string `yaml:"license,omitempty" json:"license,omitempty"`
+	// A Xray component ID, like npm://lodash:4.17.20. Wildcards (*) are supported, for example npm://lodash:*
+	Component string `yaml:"component,omitempty" json:"component,omitempty"`
+	// Limits the rule to the projects or files under this path. Relative paths are relative to the directory containing the '.jfrog' directory.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// The reason the findings are suppressed. Mandatory.
+	Justification string `yaml:"justification" json:"justification"`
+	// The last day the rule is applied on, in the format YYYY-MM-DD. If empty, the rule never expires.
+	ExpiresAt string `yaml:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+
+	componentRegexp *regexp.Regexp
+	expiryTime      time.Time
+}
+
+// IgnoreRules holds the content of the xray-ignore.yaml file.
+type IgnoreRules struct {
+	Rules []IgnoreRule `yaml:"rules"`
+	// The directory relative rule paths are relative to.
+	rootDir string
+}
+
+// SuppressedScanResults holds the scan results that were suppressed by an ignore rule.
+type SuppressedScanResults struct {
+	Rule    IgnoreRule              `json:"rule"`
+	Results []services.ScanResponse `json:"results"`
+	// The path of the descriptor or file each of the Results was created from, by index.
+	ScannedPaths []string `json:"scannedPaths,omitempty"`
+}
+
+// GetIgnoreRules looks for the .jfrog/xray-ignore.yaml file in rootDir or in one of its parent directories.
+// If the file doesn't exist, nil is returned.
+func GetIgnoreRules(rootDir string) (*IgnoreRules, error) {
+	filePath, err := findJfrogDirFile(rootDir, IgnoreRulesFileName)
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+	return ReadIgnoreRules(filePath)
+}
+
+// findJfrogDirFile returns the path of fileName in the .jfrog directory closest to dir, looking in dir and then in its parent directories.
+// If no .jfrog directory is found or the closest one doesn't contain fileName, an empty path is returned.
+func findJfrogDirFile(dir, fileName string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if errorutils.CheckError(err) != nil {
+		return "", err
+	}
+	for {
+		jfrogDirExists, err := fileutils.IsDirExists(filepath.Join(dir, ".jfrog"), false)
+		if err != nil {
+			return "", err
+		}
+		if jfrogDirExists {
+			filePath := filepath.Join(dir, ".jfrog", fileName)
+			exists, err := fileutils.IsFileExists(filePath, false)
+			if err != nil || !exists {
+				return "", err
+			}
+			return filePath, nil
+		}
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return "", nil
+		}
+		dir = parentDir
+	}
+}
+
+// ReadIgnoreRules reads and validates an ignore rules file.
+// Relative rule paths are relative to the parent of the directory containing the file (the project's root).
+func ReadIgnoreRules(filePath string) (*IgnoreRules, error) {
+	content, err := os.ReadFile(filePath)
+	if errorutils.CheckError(err) != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(filePath)
+	if errorutils.CheckError(err) != nil {
+		return nil, err
+	}
+	ignoreRules := &IgnoreRules{rootDir: filepath.Dir(filepath.Dir(absPath))}
+	if err = yaml.Unmarshal(content, ignoreRules); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the ignore rules file %s: %s", filePath, err.Error())
+	}
+	for i := range ignoreRules.Rules {
+		if err = ignoreRules.Rules[i].init(); err != nil {
+			return nil, errorutils.CheckErrorf("invalid rule #%d in %s: %s", i+1, filePath, err.Error())
+		}
+	}
+	log.Debug(fmt.Sprintf("Loaded %d ignore rules from %s", len(ignoreRules.Rules), filePath))
+	return ignoreRules, nil
+}
+
+func (rule *IgnoreRule) init() (err error) {
+	if rule.Cve == "" && rule.IssueId == "" && rule.License == "" && rule.Component == "" {
+		return fmt.Errorf("at least one of the fields cve, issueId, license or component must be set")
+	}
+	if strings.TrimSpace(rule.Justification) == "" {
+		return fmt.Errorf("the justification field is mandatory")
+	}
+	if rule.Component != "" {
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(rule.Component), `\*`, ".*")
+		if rule.componentRegexp, err = regexp.Compile("^" + pattern + "$"); err != nil {
+			return
+		}
+	}
+	if rule.ExpiresAt != "" {
+		expiryDate, err := time.ParseInLocation(ignoreRulesDateLayout, rule.ExpiresAt, time.Local)
+		if err != nil {
+			return fmt.Errorf("expiresAt should be in the format YYYY-MM-DD: %s", err.Error())
+		}
+		// The rule is applied until the end of the expiry date
+		rule.expiryTime = expiryDate.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+func (rule *IgnoreRule) isExpired() bool {
+	return !rule.expiryTime.IsZero() && !time.Now().Before(rule.expiryTime)
+}
+
+// Returns true if the rule isn't limited to a path, or if scannedPath is the rule's path or one of its descendants.
+func (rule *IgnoreRule) matchPath(rootDir, scannedPath string) bool {
+	if rule.Path == "" {
+		return true
+	}
+	if scannedPath == "" {
+		return false
+	}
+	rulePath := rule.Path
+	if !filepath.IsAbs(rulePath) {
+		rulePath = filepath.Join(rootDir, rulePath)
+	}
+	scannedPath, err := filepath.Abs(scannedPath)
+	if err != nil {
+		return false
+	}
+	relPath, err := filepath.Rel(rulePath, scannedPath)
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+func (rule *IgnoreRule) matchComponent(componentId string) bool {
+	return rule.componentRegexp == nil || rule.componentRegexp.MatchString(componentId)
+}
+
+func (rule *IgnoreRule) matchCves(cves []services.Cve) bool {
+	if rule.Cve == "" {
+		return true
+	}
+	for _, cve := range cves {
+		if strings.EqualFold(cve.Id, rule.Cve) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *IgnoreRule) matchViolation(violation services.Violation, componentId string) bool {
+	return rule.matchComponent(componentId) &&
+		rule.matchCves(violation.Cves) &&
+		(rule.IssueId == "" || strings.EqualFold(rule.IssueId, violation.IssueId)) &&
+		(rule.License == "" || strings.EqualFold(rule.License, violation.LicenseKey))
+}
+
+func (rule *IgnoreRule) matchVulnerability(vulnerability services.Vulnerability, componentId string) bool {
+	return rule.License == "" &&
+		rule.matchComponent(componentId) &&
+		rule.matchCves(vulnerability.Cves) &&
+		(rule.IssueId == "" || strings.EqualFold(rule.IssueId, vulnerability.IssueId))
+}
+
+func (rule *IgnoreRule) matchLicense(license services.License, componentId string) bool {
+	return rule.Cve == "" && rule.IssueId == "" &&
+		rule.matchComponent(componentId) &&
+		(rule.License == "" || strings.EqualFold(rule.License, license.Key))
+}
+
+// SuppressIssues splits scan results into the issues that aren't suppressed by any of the rules, and the issues that are, grouped by the first rule that matches them.
+// scannedPaths holds the path of the project, descriptor or file each of the results belongs to (by index), and is used to apply path-scoped rules.
+// The remaining results keep the order of the given results (including results that are left with no issues), so scannedPaths apply to them too.
+// Expired rules are ignored. It is safe to call this method on a nil receiver, in which case no issue is suppressed.
+func (ir *IgnoreRules) SuppressIssues(results []services.ScanResponse, scannedPaths []string) (remaining []services.ScanResponse, suppressed []SuppressedScanResults) {
+	if ir == nil || len(ir.Rules) == 0 {
+		return results, nil
+	}
+	suppressedByRule := make([]SuppressedScanResults, len(ir.Rules))
+	for i := range ir.Rules {
+		if ir.Rules[i].isExpired() {
+			log.Warn(fmt.Sprintf("The Xray ignore rule '%s' expired on %s and is not applied.", ir.Rules[i].Justification, ir.Rules[i].ExpiresAt))
+		}
+	}
+	for resultIndex, response := range results {
+		scannedPath := ""
+		if resultIndex < len(scannedPaths) {
+			scannedPath = scannedPaths[resultIndex]
+		}
+		for ruleIndex := range ir.Rules {
+			rule := &ir.Rules[ruleIndex]
+			if rule.isExpired() || !rule.matchPath(ir.rootDir, scannedPath) {
+				continue
+			}
+			var matched services.ScanResponse
+			matched, response = splitScanResponse(response, rule)
+			if !IsEmptyScanResponse([]services.ScanResponse{matched}) {
+				suppressedByRule[ruleIndex].Results = append(suppressedByRule[ruleIndex].Results, matched)
+				suppressedByRule[ruleIndex].ScannedPaths = append(suppressedByRule[ruleIndex].ScannedPaths, scannedPath)
+			}
+		}
+		remaining = append(remaining, response)
+	}
+	for ruleIndex, ruleResults := range suppressedByRule {
+		if len(ruleResults.Results) > 0 {
+			ruleResults.Rule = ir.Rules[ruleIndex]
+			suppressed = append(suppressed, ruleResults)
+		}
+	}
+	return
+}
+
+// Returns all the results that were suppressed.
+func flattenSuppressedResults(suppressed []SuppressedScanResults) (results []services.ScanResponse) {
+	for _, suppressedResults := range suppressed {
+		results = append(results, suppressedResults.Results...)
+	}
+	return
+}
+
+func (rule *IgnoreRule) getSuppressionDetail() string {
+	detail := "Suppressed by a local ignore rule: " + rule.Justification
+	if rule.ExpiresAt != "" {
+		detail += " (expires at " + rule.ExpiresAt + ")"
+	}
+	return detail
+}
+
+// Calls handleIssue for each component impacted by each of the issues in the results, along with a short description of the issue.
+func forEachIssue(results []services.ScanResponse, handleIssue func(issueDescription, componentId string)) {
+	violations, vulnerabilities, licenses := splitScanResults(results)
+	for _, violation := range violations {
+		description := violation.ViolationType + " violation " + violation.IssueId
+		if violation.LicenseKey != "" {
+			description += " (" + violation.LicenseKey + ")"
+		}
+		for componentId := range violation.Components {
+			handleIssue(description, componentId)
+		}
+	}
+	for _, vulnerability := range vulnerabilities {
+		description := "vulnerability " + vulnerability.IssueId
+		if len(vulnerability.Cves) > 0 && vulnerability.Cves[0].Id != "" {
+			description += " (" + vulnerability.Cves[0].Id + ")"
+		}
+		for componentId := range vulnerability.Components {
+			handleIssue(description, componentId)
+		}
+	}
+	for _, license := range licenses {
+		for componentId := range license.Components {
+			handleIssue("license "+license.Key, componentId)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+const ignoreRulesContent = `
+rules:
+  - cve: CVE-2022-0001
+    justification: The vulnerable function is not used
+  - component: npm://lodash:*
+    path: frontend
+    justification: Lodash is only used in the build scripts
+  - license: GPL-3.0
+    justification: Approved by legal
+    expiresAt: 2000-01-01
+`
+
+func TestSuppressIssues(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".jfrog"), 0755))
+	ignoreRulesPath := filepath.Join(projectDir, ".jfrog", IgnoreRulesFileName)
+	assert.NoError(t, os.WriteFile(ignoreRulesPath, []byte(ignoreRulesContent), 0644))
+	ignoreRules, err := ReadIgnoreRules(ignoreRulesPath)
+	assert.NoError(t, err)
+
+	response := services.ScanResponse{
+		Vulnerabilities: []services.Vulnerability{
+			{IssueId: "XRAY-1", Cves: []services.Cve{{Id: "CVE-2022-0001"}}, Components: map[string]services.Component{"npm://debug:2.6.9": {}}},
+			{IssueId: "XRAY-2", Components: map[string]services.Component{"npm://lodash:4.17.20": {}, "npm://express:4.17.1": {}}},
+		},
+		Licenses: []services.License{{Key: "GPL-3.0", Components: map[string]services.Component{"npm://gpl-lib:1.0.0": {}}}},
+	}
+	frontendDir := filepath.Join(projectDir, "frontend", "app")
+	backendDir := filepath.Join(projectDir, "backend")
+	remaining, suppressed := ignoreRules.SuppressIssues([]services.ScanResponse{response, response}, []string{frontendDir, backendDir})
+
+	// The expired license rule isn't applied
+	assert.Len(t, remaining, 2)
+	for _, result := range remaining {
+		assert.Len(t, result.Licenses, 1)
+	}
+	// The lodash rule is applied to the frontend only
+	if assert.Len(t, remaining[0].Vulnerabilities, 1) {
+		assert.Equal(t, map[string]services.Component{"npm://express:4.17.1": {}}, remaining[0].Vulnerabilities[0].Components)
+	}
+	if assert.Len(t, remaining[1].Vulnerabilities, 1) {
+		assert.Len(t, remaining[1].Vulnerabilities[0].Components, 2)
+	}
+
+	if assert.Len(t, suppressed, 2) {
+		assert.Equal(t, "The vulnerable function is not used", suppressed[0].Rule.Justification)
+		assert.Len(t, suppressed[0].Results, 2)
+		assert.Equal(t, "Lodash is only used in the build scripts", suppressed[1].Rule.Justification)
+		if assert.Len(t, suppressed[1].Results, 1) {
+			assert.Equal(t, "XRAY-2", suppressed[1].Results[0].Vulnerabilities[0].IssueId)
+		}
+	}
+	assert.False(t, CheckIfFailBuild(remaining))
+
+	suppressedRows, err := PrepareSuppressedIssues(suppressed, true, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, suppressedRows, 3)
+
+	bom := createCycloneDxBom(remaining, suppressed, nil)
+	for _, vulnerability := range *bom.Vulnerabilities {
+		if vulnerability.BOMRef == "XRAY-1"+suppressedRefSuffix && assert.NotNil(t, vulnerability.Analysis) {
+			assert.Equal(t, "Suppressed by a local ignore rule: The vulnerable function is not used", vulnerability.Analysis.Detail)
+		}
+	}
+
+	// No rules
+	var noRules *IgnoreRules
+	remaining, suppressed = noRules.SuppressIssues([]services.ScanResponse{response}, nil)
+	assert.Equal(t, []services.ScanResponse{response}, remaining)
+	assert.Nil(t, suppressed)
+}
+
+func TestReadIgnoreRulesInvalid(t *testing.T) {
+	ignoreRulesPath := filepath.Join(t.TempDir(), IgnoreRulesFileName)
+	assert.NoError(t, os.WriteFile(ignoreRulesPath, []byte("rules:\n  - cve: CVE-2022-0001\n"), 0644))
+	_, err := ReadIgnoreRules(ignoreRulesPath)
+	assert.ErrorContains(t, err, "justification")
+
+	assert.NoError(t, os.WriteFile(ignoreRulesPath, []byte("rules:\n  - cve: CVE-2022-0001\n    justification: test\n    expiresAt: 01/01/2030\n"), 0644))
+	_, err = ReadIgnoreRules(ignoreRulesPath)
+	assert.ErrorContains(t, err, "YYYY-MM-DD")
+}
+
+func TestGetIgnoreRules(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".jfrog"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, ".jfrog", IgnoreRulesFileName), []byte(ignoreRulesContent), 0644))
+	subDir := filepath.Join(projectDir, "frontend", "app")
+	assert.NoError(t, os.MkdirAll(subDir, 0755))
+
+	// The file is found from a subdirectory of the project, regardless of the current directory.
+	ignoreRules, err := GetIgnoreRules(subDir)
+	assert.NoError(t, err)
+	if assert.NotNil(t, ignoreRules) {
+		assert.Len(t, ignoreRules.Rules, 3)
+		assert.Equal(t, projectDir, ignoreRules.rootDir)
+	}
+
+	// The closest .jfrog directory is used, even if it has no ignore rules file.
+	assert.NoError(t, os.MkdirAll(filepath.Join(subDir, ".jfrog"), 0755))
+	ignoreRules, err = GetIgnoreRules(subDir)
+	assert.NoError(t, err)
+	assert.Nil(t, ignoreRules)
+}
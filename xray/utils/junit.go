@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	junitReportName = "JFrog Xray"
+	// The test case added to empty reports, since some CI servers fail on reports without test cases.
+	junitNoIssuesTestName = "No issues were found"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// GenerateJunitReportFromScan renders the vulnerabilities (or violations) in the scan results as a JUnit XML report.
+// Each issue is a failing test case, and the test cases are grouped in test suites by technology.
+func GenerateJunitReportFromScan(results []services.ScanResponse, includeVulnerabilities, isMultipleRoots bool) (string, error) {
+	return generateJunitReport(results, nil, nil, includeVulnerabilities, isMultipleRoots)
+}
+
+// Issues are grouped in test suites by technology and by the descriptor of the project they were found in.
+// Suppressed issues are added as skipped test cases, with the justification of the ignore rule as the skip message.
+func generateJunitReport(results []services.ScanResponse, scannedPaths []string, suppressed []SuppressedScanResults, includeVulnerabilities, isMultipleRoots bool) (string, error) {
+	report := &junitTestSuites{Name: junitReportName}
+	suites := make(map[string]*junitTestSuite)
+	getSuite := func(packageType, descriptorPath string) *junitTestSuite {
+		suiteName := packageType
+		if suiteName == "" {
+			suiteName = junitReportName
+		}
+		if descriptorPath != "" {
+			suiteName += " (" + descriptorPath + ")"
+		}
+		suite, exists := suites[suiteName]
+		if !exists {
+			suite = &junitTestSuite{Name: suiteName}
+			suites[suiteName] = suite
+			report.Suites = append(report.Suites, suite)
+		}
+		return suite
+	}
+
+	issues := splitScanResultsWithPaths(results, scannedPaths)
+	var licenseRows []formats.LicenseViolationRow
+	var err error
+	if includeVulnerabilities {
+		vulnerabilitiesRows, err := prepareVulnerabilities(issues.vulnerabilities, issues.vulnerabilitiesPaths, isMultipleRoots, false)
+		if err != nil {
+			return "", err
+		}
+		for _, vulnerability := range vulnerabilitiesRows {
+			suite := getSuite(vulnerability.ImpactedPackageType, vulnerability.DescriptorPath)
+			suite.addFailure(getSecurityIssueTestName(vulnerability), vulnerability.Severity, getSecurityIssueMessage(vulnerability), getSecurityIssueDetails(vulnerability))
+		}
+		if licenseRows, err = prepareLicensePolicyViolations(issues.violations, issues.violationsPaths, isMultipleRoots, false); err != nil {
+			return "", err
+		}
+	} else {
+		var securityRows []formats.VulnerabilityOrViolationRow
+		var operationalRiskRows []formats.OperationalRiskViolationRow
+		securityRows, licenseRows, operationalRiskRows, err = prepareViolations(issues.violations, issues.violationsPaths, isMultipleRoots, false)
+		if err != nil {
+			return "", err
+		}
+		for _, security := range securityRows {
+			suite := getSuite(security.ImpactedPackageType, security.DescriptorPath)
+			suite.addFailure(getSecurityIssueTestName(security), security.Severity, getSecurityIssueMessage(security), getSecurityIssueDetails(security))
+		}
+		for _, operationalRisk := range operationalRiskRows {
+			suite := getSuite(operationalRisk.ImpactedPackageType, operationalRisk.DescriptorPath)
+			testName := "Operational risk: " + getPackageDescription(operationalRisk.ImpactedPackageName, operationalRisk.ImpactedPackageVersion)
+			message := fmt.Sprintf("[%s] Operational risk violation: %s", operationalRisk.Severity, operationalRisk.RiskReason)
+			suite.addFailure(testName, operationalRisk.Severity, message, getComponentsDetails(operationalRisk.Components))
+		}
+	}
+
+	for _, license := range licenseRows {
+		suite := getSuite(license.ImpactedPackageType, license.DescriptorPath)
+		testName := fmt.Sprintf("License %s: %s", license.LicenseKey, getPackageDescription(license.ImpactedPackageName, license.ImpactedPackageVersion))
+		message := fmt.Sprintf("[%s] License violation: %s", license.Severity, license.LicenseKey)
+		suite.addFailure(testName, license.Severity, message, getComponentsDetails(license.Components))
+	}
+
+	suppressedRows, err := PrepareSuppressedIssues(suppressed, includeVulnerabilities, false, isMultipleRoots)
+	if err != nil {
+		return "", err
+	}
+	for _, suppressedIssue := range suppressedRows {
+		suite := getSuite(suppressedIssue.ImpactedPackageType, suppressedIssue.DescriptorPath)
+		issueId := suppressedIssue.IssueId
+		if issueId == "" {
+			issueId = "License " + suppressedIssue.LicenseKey
+		}
+		suite.addSkipped(issueId+": "+getPackageDescription(suppressedIssue.ImpactedPackageName, suppressedIssue.ImpactedPackageVersion), "Suppressed by a local ignore rule: "+suppressedIssue.Justification)
+	}
+
+	if len(report.Suites) == 0 {
+		suite := getSuite("", "")
+		suite.TestCases = append(suite.TestCases, junitTestCase{Name: junitNoIssuesTestName, ClassName: suite.Name})
+		suite.Tests++
+	}
+	sort.SliceStable(report.Suites, func(i, j int) bool {
+		return report.Suites[i].Name < report.Suites[j].Name
+	})
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	return xml.Header + string(out), nil
+}
+
+func (suite *junitTestSuite) addFailure(testName, severity, message, details string) {
+	suite.TestCases = append(suite.TestCases, junitTestCase{
+		Name:      testName,
+		ClassName: suite.Name,
+		Failure:   &junitFailure{Message: message, Type: severity, Details: details},
+	})
+	suite.Tests++
+	suite.Failures++
+}
+
+func (suite *junitTestSuite) addSkipped(testName, message string) {
+	suite.TestCases = append(suite.TestCases, junitTestCase{
+		Name:      testName,
+		ClassName: suite.Name,
+		Skipped:   &junitSkipped{Message: message},
+	})
+	suite.Tests++
+	suite.Skipped++
+}
+
+func getSecurityIssueTestName(securityRow formats.VulnerabilityOrViolationRow) string {
+	issueId := securityRow.IssueId
+	if cves := getCveIds(securityRow.Cves); cves != "" {
+		issueId += " (" + cves + ")"
+	}
+	return issueId + ": " + getPackageDescription(securityRow.ImpactedPackageName, securityRow.ImpactedPackageVersion)
+}
+
+func getSecurityIssueMessage(securityRow formats.VulnerabilityOrViolationRow) string {
+	message := fmt.Sprintf("[%s] %s", securityRow.Severity, getPackageDescription(securityRow.ImpactedPackageName, securityRow.ImpactedPackageVersion))
+	if securityRow.Summary != "" {
+		message += ": " + securityRow.Summary
+	}
+	return message
+}
+
+func getSecurityIssueDetails(securityRow formats.VulnerabilityOrViolationRow) string {
+	details := []string{"Severity: " + securityRow.Severity}
+	if cves := getCveIds(securityRow.Cves); cves != "" {
+		details = append(details, "CVEs: "+cves)
+	}
+	if len(securityRow.FixedVersions) > 0 {
+		details = append(details, "Fixed versions: "+strings.Join(securityRow.FixedVersions, ", "))
+	} else {
+		details = append(details, "Fixed versions: none")
+	}
+	details = append(details, getComponentsDetails(securityRow.Components))
+	for _, impactPath := range securityRow.ImpactPaths {
+		var pathNodes []string
+		for _, node := range impactPath {
+			pathNodes = append(pathNodes, getPackageDescription(node.Name, node.Version))
+		}
+		details = append(details, "Impact path: "+strings.Join(pathNodes, " > "))
+	}
+	return strings.Join(details, "\n")
+}
+
+func getComponentsDetails(components []formats.ComponentRow) string {
+	var directComponents []string
+	for _, component := range components {
+		directComponents = append(directComponents, getPackageDescription(component.Name, component.Version))
+	}
+	return "Direct dependencies: " + strings.Join(directComponents, ", ")
+}
+
+func getCveIds(cves []formats.CveRow) string {
+	var cveIds []string
+	for _, cve := range cves {
+		if cve.Id != "" {
+			cveIds = append(cveIds, cve.Id)
+		}
+	}
+	return strings.Join(cveIds, ", ")
+}
+
+func getPackageDescription(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + ":" + version
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJunitReport(t *testing.T) {
+	results := []services.ScanResponse{
+		{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-1", Severity: "High", Summary: "Prototype pollution", Cves: []services.Cve{{Id: "CVE-2022-0001"}},
+			Components: map[string]services.Component{"npm://lodash:4.17.15": {FixedVersions: []string{"[4.17.21]"}}}}}},
+		{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-2", Severity: "Low", Components: map[string]services.Component{"gav://org.lib:lib:1.0.0": {}}}}},
+	}
+	suppressed := []SuppressedScanResults{{
+		Rule:         IgnoreRule{Justification: "Not exploitable"},
+		Results:      []services.ScanResponse{{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-3", Severity: "Medium", Components: map[string]services.Component{"npm://debug:2.6.8": {}}}}}},
+		ScannedPaths: []string{"/project/frontend/package.json"},
+	}}
+	report, err := generateJunitReport(results, []string{"/project/frontend/package.json", "/project/backend/pom.xml"}, suppressed, true, false)
+	assert.NoError(t, err)
+
+	var testSuites junitTestSuites
+	assert.NoError(t, xml.Unmarshal([]byte(report), &testSuites))
+	assert.Equal(t, 3, testSuites.Tests)
+	assert.Equal(t, 2, testSuites.Failures)
+	assert.Equal(t, 1, testSuites.Skipped)
+	if assert.Len(t, testSuites.Suites, 2) {
+		maven, npm := testSuites.Suites[0], testSuites.Suites[1]
+		assert.Equal(t, "Maven (/project/backend/pom.xml)", maven.Name)
+		assert.Equal(t, "npm (/project/frontend/package.json)", npm.Name)
+		if assert.Len(t, npm.TestCases, 2) {
+			assert.Equal(t, "XRAY-1 (CVE-2022-0001): lodash:4.17.15", npm.TestCases[0].Name)
+			assert.Equal(t, "[High] lodash:4.17.15: Prototype pollution", npm.TestCases[0].Failure.Message)
+			assert.Equal(t, "High", npm.TestCases[0].Failure.Type)
+			assert.Contains(t, npm.TestCases[0].Failure.Details, "Fixed versions: [4.17.21]")
+			assert.Equal(t, "Suppressed by a local ignore rule: Not exploitable", npm.TestCases[1].Skipped.Message)
+		}
+	}
+
+	// Reports with no issues have a single passing test case
+	report, err = GenerateJunitReportFromScan(nil, true, false)
+	assert.NoError(t, err)
+	testSuites = junitTestSuites{}
+	assert.NoError(t, xml.Unmarshal([]byte(report), &testSuites))
+	assert.Equal(t, 1, testSuites.Tests)
+	assert.Zero(t, testSuites.Failures)
+}
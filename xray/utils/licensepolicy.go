@@ -0,0 +1,340 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	LicensePolicyFileName = "xray-license-policy.yaml"
+	// The watch name of the license violations of the local license policy, which tells them apart from the violations of Xray policies.
+	LicensePolicyWatchName = "local-license-policy"
+	// The decisions of licenses, from the most permissive to the strictest.
+	LicenseAllow  = "allow"
+	LicenseReview = "review"
+	LicenseDeny   = "deny"
+	// Set as failOn to never fail the build.
+	licensePolicyFailOnNone = "none"
+)
+
+var licenseDecisionsRank = map[string]int{LicenseAllow: 0, LicenseReview: 1, LicenseDeny: 2}
+
+// LicensePolicy holds the content of the xray-license-policy.yaml file.
+// The licenses Xray finds are evaluated against the policy locally, without Xray policies and watches.
+// Each license is decided according to the list it appears in. The licenses listed are SPDX license identifiers (like MIT or GPL-3.0-only),
+// and the licenses found may be SPDX license expressions (like MIT OR Apache-2.0), in which the most permissive license of an OR and the
+// strictest license of an AND are decided by.
+type LicensePolicy struct {
+	Allow  []string `yaml:"allow,omitempty"`
+	Review []string `yaml:"review,omitempty"`
+	Deny   []string `yaml:"deny,omitempty"`
+	// The decision for licenses that aren't listed: allow, review or deny. The default is review.
+	Default string `yaml:"default,omitempty"`
+	// The decision from which the build fails: deny (the default), review (fails also on licenses that require a review) or none.
+	FailOn string `yaml:"failOn,omitempty"`
+
+	// The decisions of the listed licenses, by their normalized identifiers.
+	decisions map[string]string
+}
+
+// GetLicensePolicy looks for the .jfrog/xray-license-policy.yaml file in rootDir or in one of its parent directories.
+// If the file doesn't exist, nil is returned.
+func GetLicensePolicy(rootDir string) (*LicensePolicy, error) {
+	filePath, err := findJfrogDirFile(rootDir, LicensePolicyFileName)
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+	return ReadLicensePolicy(filePath)
+}
+
+// ReadLicensePolicy reads and validates a license policy file.
+func ReadLicensePolicy(filePath string) (*LicensePolicy, error) {
+	content, err := os.ReadFile(filePath)
+	if errorutils.CheckError(err) != nil {
+		return nil, err
+	}
+	licensePolicy := &LicensePolicy{}
+	if err = yaml.UnmarshalStrict(content, licensePolicy); err != nil {
+		return nil, errorutils.CheckErrorf("failed to parse the license policy file %s: %s", filePath, err.Error())
+	}
+	if err = licensePolicy.init(); err != nil {
+		return nil, errorutils.CheckErrorf("invalid license policy in %s: %s", filePath, err.Error())
+	}
+	log.Debug(fmt.Sprintf("Loaded the license policy from %s", filePath))
+	return licensePolicy, nil
+}
+
+func (lp *LicensePolicy) init() error {
+	if lp.Default == "" {
+		lp.Default = LicenseReview
+	}
+	lp.Default = strings.ToLower(lp.Default)
+	if _, exists := licenseDecisionsRank[lp.Default]; !exists {
+		return fmt.Errorf("invalid default decision '%s'. Possible values are: allow, review or deny", lp.Default)
+	}
+	if lp.FailOn == "" {
+		lp.FailOn = LicenseDeny
+	}
+	lp.FailOn = strings.ToLower(lp.FailOn)
+	if _, exists := licenseDecisionsRank[lp.FailOn]; (!exists || lp.FailOn == LicenseAllow) && lp.FailOn != licensePolicyFailOnNone {
+		return fmt.Errorf("invalid failOn value '%s'. Possible values are: review, deny or none", lp.FailOn)
+	}
+	lp.decisions = make(map[string]string)
+	for decision, licenses := range map[string][]string{LicenseAllow: lp.Allow, LicenseReview: lp.Review, LicenseDeny: lp.Deny} {
+		for _, license := range licenses {
+			licenseId := normalizeLicenseId(license)
+			if licenseId == "" {
+				return fmt.Errorf("empty license in the %s list", decision)
+			}
+			if strings.ContainsAny(licenseId, "()") || strings.Contains(" "+licenseId+" ", " AND ") || strings.Contains(" "+licenseId+" ", " OR ") {
+				return fmt.Errorf("the license '%s' in the %s list should be a license identifier rather than a license expression", license, decision)
+			}
+			if otherDecision, exists := lp.decisions[licenseId]; exists && otherDecision != decision {
+				return fmt.Errorf("the license '%s' is listed in both the %s and the %s lists", license, otherDecision, decision)
+			}
+			lp.decisions[licenseId] = decision
+		}
+	}
+	return nil
+}
+
+// Licenses are matched case-insensitively, and the '-only' and '-or-later' suffixes of SPDX identifiers match the bare and '+' identifiers
+// Xray reports (so GPL-3.0-only matches GPL-3.0, and GPL-3.0-or-later matches GPL-3.0+).
+func normalizeLicenseId(licenseId string) string {
+	licenseId = strings.ToUpper(strings.Join(strings.Fields(licenseId), " "))
+	if base, exception, found := strings.Cut(licenseId, " WITH "); found {
+		return normalizeLicenseId(base) + " WITH " + exception
+	}
+	if strings.HasSuffix(licenseId, "-OR-LATER") {
+		return strings.TrimSuffix(licenseId, "-OR-LATER") + "+"
+	}
+	return strings.TrimSuffix(licenseId, "-ONLY")
+}
+
+// Decide returns the decision of the policy for a license found: allow, review or deny.
+// The license may be a license identifier or an SPDX license expression. Licenses that can't be parsed as expressions are decided as identifiers.
+func (lp *LicensePolicy) Decide(license string) string {
+	expression, err := parseLicenseExpression(license)
+	if err != nil {
+		log.Debug(fmt.Sprintf("The license '%s' is evaluated as a single license: %s", license, err.Error()))
+		return lp.decideLicenseId(license)
+	}
+	return expression.decide(lp)
+}
+
+// A license with an exception (like GPL-2.0 WITH Classpath-exception-2.0) that isn't listed is decided by the license without the exception,
+// and a license that allows later versions (like GPL-2.0+) that isn't listed is decided by the license itself.
+func (lp *LicensePolicy) decideLicenseId(licenseId string) string {
+	licenseId = normalizeLicenseId(licenseId)
+	if decision, exists := lp.decisions[licenseId]; exists {
+		return decision
+	}
+	if base, _, found := strings.Cut(licenseId, " WITH "); found {
+		return lp.decideLicenseId(base)
+	}
+	if strings.HasSuffix(licenseId, "+") {
+		return lp.decideLicenseId(strings.TrimSuffix(licenseId, "+"))
+	}
+	return lp.Default
+}
+
+// AddLicenseViolations evaluates the licenses of the results against the policy, and adds a license violation for each license that is denied
+// or requires a review. The violations can be suppressed by local ignore rules, like the violations of Xray policies.
+// The results keep their order, so the scanned paths of the given results apply to them too. The given results aren't modified.
+// It is safe to call this method on a nil receiver, in which case the results are returned as is.
+func (lp *LicensePolicy) AddLicenseViolations(results []services.ScanResponse) []services.ScanResponse {
+	if lp == nil {
+		return results
+	}
+	policyResults := make([]services.ScanResponse, 0, len(results))
+	for _, result := range results {
+		var violations []services.Violation
+		for _, license := range result.Licenses {
+			if violation, isViolation := lp.toViolation(license); isViolation {
+				violations = append(violations, violation)
+			}
+		}
+		if len(violations) > 0 {
+			result.Violations = append(append([]services.Violation{}, result.Violations...), violations...)
+		}
+		policyResults = append(policyResults, result)
+	}
+	return policyResults
+}
+
+func (lp *LicensePolicy) toViolation(license services.License) (services.Violation, bool) {
+	violation := services.Violation{
+		ViolationType: "license",
+		LicenseKey:    license.Key,
+		LicenseName:   license.Name,
+		Components:    license.Components,
+		References:    license.References,
+		WatchName:     LicensePolicyWatchName,
+	}
+	decision := lp.Decide(license.Key)
+	switch decision {
+	case LicenseDeny:
+		violation.Severity = "High"
+		violation.Summary = "Denied by the local license policy"
+	case LicenseReview:
+		violation.Severity = "Medium"
+		violation.Summary = "Requires a review according to the local license policy"
+	default:
+		return services.Violation{}, false
+	}
+	violation.FailBuild = lp.FailOn != licensePolicyFailOnNone && licenseDecisionsRank[decision] >= licenseDecisionsRank[lp.FailOn]
+	return violation, true
+}
+
+func isLicensePolicyViolation(violation services.Violation) bool {
+	return violation.WatchName == LicensePolicyWatchName
+}
+
+// CheckIfLicensePolicyFailBuild returns true if one of the violations of the local license policy in the results is set to fail the build.
+func CheckIfLicensePolicyFailBuild(results []services.ScanResponse) bool {
+	for _, result := range results {
+		for _, violation := range result.Violations {
+			if isLicensePolicyViolation(violation) && violation.FailBuild {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func NewLicensePolicyFailBuildError() error {
+	return coreutils.CliError{ExitCode: coreutils.ExitCodeVulnerableBuild, ErrorMsg: "One or more of the licenses found are not permitted by the local license policy (" + LicensePolicyFileName + ")"}
+}
+
+// A parsed SPDX license expression. Either a license identifier (with an optional exception), or an operator with its operands.
+type licenseExpression struct {
+	licenseId string
+	// AND or OR
+	operator string
+	operands []*licenseExpression
+}
+
+func (le *licenseExpression) decide(lp *LicensePolicy) string {
+	if le.operator == "" {
+		return lp.decideLicenseId(le.licenseId)
+	}
+	decision := ""
+	for _, operand := range le.operands {
+		operandDecision := operand.decide(lp)
+		// The most permissive of the licenses can be chosen in OR, while all the licenses apply in AND
+		if decision == "" ||
+			(le.operator == "OR" && licenseDecisionsRank[operandDecision] < licenseDecisionsRank[decision]) ||
+			(le.operator == "AND" && licenseDecisionsRank[operandDecision] > licenseDecisionsRank[decision]) {
+			decision = operandDecision
+		}
+	}
+	return decision
+}
+
+// Parses an SPDX license expression, in which AND takes precedence over OR. Operators are case-insensitive.
+func parseLicenseExpression(expression string) (*licenseExpression, error) {
+	parser := &licenseExpressionParser{tokens: tokenizeLicenseExpression(expression)}
+	if len(parser.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	parsed, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(parser.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", parser.tokens[parser.position])
+	}
+	return parsed, nil
+}
+
+func tokenizeLicenseExpression(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+type licenseExpressionParser struct {
+	tokens   []string
+	position int
+}
+
+func (p *licenseExpressionParser) next() string {
+	if p.position >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.position]
+}
+
+func (p *licenseExpressionParser) nextIsOperator(operator string) bool {
+	return strings.EqualFold(p.next(), operator)
+}
+
+func (p *licenseExpressionParser) parseOr() (*licenseExpression, error) {
+	return p.parseOperator("OR", p.parseAnd)
+}
+
+func (p *licenseExpressionParser) parseAnd() (*licenseExpression, error) {
+	return p.parseOperator("AND", p.parseTerm)
+}
+
+func (p *licenseExpressionParser) parseOperator(operator string, parseOperand func() (*licenseExpression, error)) (*licenseExpression, error) {
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*licenseExpression{operand}
+	for p.nextIsOperator(operator) {
+		p.position++
+		if operand, err = parseOperand(); err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &licenseExpression{operator: operator, operands: operands}, nil
+}
+
+func (p *licenseExpressionParser) parseTerm() (*licenseExpression, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "(":
+		p.position++
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.position++
+		return expression, nil
+	case token == ")" || isLicenseOperator(token):
+		return nil, fmt.Errorf("unexpected '%s'", token)
+	}
+	p.position++
+	licenseId := token
+	if p.nextIsOperator("WITH") {
+		p.position++
+		exception := p.next()
+		if exception == "" || exception == "(" || exception == ")" || isLicenseOperator(exception) {
+			return nil, fmt.Errorf("missing exception after WITH")
+		}
+		p.position++
+		licenseId += " WITH " + exception
+	}
+	return &licenseExpression{licenseId: licenseId}, nil
+}
+
+func isLicenseOperator(token string) bool {
+	return strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") || strings.EqualFold(token, "WITH")
+}
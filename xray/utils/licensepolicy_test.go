@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+const licensePolicyContent = `
+allow: [MIT, Apache-2.0, BSD-3-Clause, GPL-2.0-only WITH Classpath-exception-2.0]
+review: [LGPL-2.1-only]
+deny: [GPL-2.0-only, GPL-3.0-or-later, AGPL-3.0-only]
+`
+
+func writeLicensePolicy(t *testing.T, content string) string {
+	policyPath := filepath.Join(t.TempDir(), LicensePolicyFileName)
+	assert.NoError(t, os.WriteFile(policyPath, []byte(content), 0644))
+	return policyPath
+}
+
+func TestLicensePolicyDecide(t *testing.T) {
+	licensePolicy, err := ReadLicensePolicy(writeLicensePolicy(t, licensePolicyContent))
+	assert.NoError(t, err)
+	tests := []struct {
+		license  string
+		expected string
+	}{
+		{"MIT", LicenseAllow},
+		{"mit", LicenseAllow},
+		{"GPL-2.0", LicenseDeny},
+		{"GPL-2.0+", LicenseDeny},
+		{"GPL-3.0+", LicenseDeny},
+		{"LGPL-2.1", LicenseReview},
+		{"GPL-2.0 WITH Classpath-exception-2.0", LicenseAllow},
+		{"GPL-2.0 WITH Other-exception", LicenseDeny},
+		// Licenses that aren't listed require a review by default
+		{"Unknown", LicenseReview},
+		{"Public Domain", LicenseReview},
+		{"MIT OR GPL-2.0", LicenseAllow},
+		{"MIT AND GPL-2.0", LicenseDeny},
+		{"(MIT OR GPL-2.0) AND LGPL-2.1", LicenseReview},
+		{"GPL-2.0 OR LGPL-2.1 AND MIT", LicenseReview},
+		{"Apache-2.0 and (GPL-3.0+ or BSD-3-Clause)", LicenseAllow},
+		// Licenses which aren't valid expressions are decided as single licenses
+		{"(MIT OR", LicenseReview},
+	}
+	for _, test := range tests {
+		t.Run(test.license, func(t *testing.T) {
+			assert.Equal(t, test.expected, licensePolicy.Decide(test.license))
+		})
+	}
+}
+
+func TestReadLicensePolicyErrors(t *testing.T) {
+	tests := []struct {
+		content       string
+		expectedError string
+	}{
+		{"allow: [MIT]\ndeny: [mit]", "is listed in both the"},
+		{"deny: [MIT OR GPL-2.0]", "should be a license identifier rather than a license expression"},
+		{"default: forbid", "invalid default decision 'forbid'"},
+		{"failOn: allow", "invalid failOn value 'allow'"},
+		{"denied: [MIT]", "failed to parse the license policy file"},
+	}
+	for _, test := range tests {
+		_, err := ReadLicensePolicy(writeLicensePolicy(t, test.content))
+		assert.ErrorContains(t, err, test.expectedError)
+	}
+}
+
+func TestAddLicenseViolations(t *testing.T) {
+	licensePolicy, err := ReadLicensePolicy(writeLicensePolicy(t, licensePolicyContent))
+	assert.NoError(t, err)
+	results := []services.ScanResponse{
+		{
+			Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-1", Severity: "Low", Components: map[string]services.Component{"npm://debug:2.6.8": {}}}},
+			Licenses: []services.License{
+				{Key: "MIT", Components: map[string]services.Component{"npm://debug:2.6.8": {}}},
+				{Key: "GPL-2.0", Components: map[string]services.Component{"npm://gpl-lib:1.0.0": {}}},
+			},
+		},
+		{Licenses: []services.License{{Key: "LGPL-2.1", Components: map[string]services.Component{"npm://lgpl-lib:1.0.0": {}}}}},
+	}
+	policyResults := licensePolicy.AddLicenseViolations(results)
+	// The given results aren't modified
+	assert.Empty(t, results[0].Violations)
+	if assert.Len(t, policyResults, 2) && assert.Len(t, policyResults[0].Violations, 1) && assert.Len(t, policyResults[1].Violations, 1) {
+		denied, review := policyResults[0].Violations[0], policyResults[1].Violations[0]
+		assert.Equal(t, "GPL-2.0", denied.LicenseKey)
+		assert.Equal(t, "High", denied.Severity)
+		assert.True(t, denied.FailBuild)
+		assert.Equal(t, "LGPL-2.1", review.LicenseKey)
+		assert.Equal(t, "Medium", review.Severity)
+		assert.False(t, review.FailBuild)
+	}
+	assert.True(t, CheckIfLicensePolicyFailBuild(policyResults))
+	assert.False(t, CheckIfLicensePolicyFailBuild(policyResults[1:]))
+	// The violations of the local license policy don't fail the build as violations of Xray policies
+	assert.False(t, CheckIfFailBuild(policyResults))
+	var nilPolicy *LicensePolicy
+	assert.Equal(t, results, nilPolicy.AddLicenseViolations(results))
+
+	// The license violations are reported along with the vulnerabilities
+	jsonTable, err := convertScanToSimpleJson(policyResults, []string{"/project/package.json", "/project/lib/package.json"}, nil, true, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, jsonTable.Vulnerabilities, 1)
+	if assert.Len(t, jsonTable.LicensesViolations, 2) {
+		assert.Equal(t, "GPL-2.0", jsonTable.LicensesViolations[0].LicenseKey)
+		assert.Equal(t, "/project/package.json", jsonTable.LicensesViolations[0].DescriptorPath)
+		assert.Equal(t, "LGPL-2.1", jsonTable.LicensesViolations[1].LicenseKey)
+	}
+	report, err := generateJunitReport(policyResults, nil, nil, true, false)
+	assert.NoError(t, err)
+	var testSuites junitTestSuites
+	assert.NoError(t, xml.Unmarshal([]byte(report), &testSuites))
+	assert.Equal(t, 3, testSuites.Failures)
+	var licenseViolationProperties []cdx.Property
+	for _, component := range *createCycloneDxBom(policyResults, nil, nil).Components {
+		if component.Properties != nil {
+			licenseViolationProperties = append(licenseViolationProperties, *component.Properties...)
+		}
+	}
+	assert.ElementsMatch(t, []cdx.Property{
+		{Name: xrayLicenseViolationKey, Value: "GPL-2.0 (High): Denied by the local license policy"},
+		{Name: xrayLicenseViolationKey, Value: "LGPL-2.1 (Medium): Requires a review according to the local license policy"},
+	}, licenseViolationProperties)
+
+	// With failOn: none, the policy never fails the build
+	licensePolicy, err = ReadLicensePolicy(writeLicensePolicy(t, licensePolicyContent+"failOn: none\n"))
+	assert.NoError(t, err)
+	assert.False(t, CheckIfLicensePolicyFailBuild(licensePolicy.AddLicenseViolations(results)))
+}
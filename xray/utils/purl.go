@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+const purlScheme = "pkg:"
+
+// Maps Xray package types to their package URL types.
+var purlTypes = map[string]string{
+	"gav":      "maven",
+	"docker":   "docker",
+	"rpm":      "rpm",
+	"deb":      "deb",
+	"nuget":    "nuget",
+	"npm":      "npm",
+	"pip":      "pypi",
+	"pypi":     "pypi",
+	"composer": "composer",
+	"go":       "golang",
+	"alpine":   "apk",
+	"cargo":    "cargo",
+	"gem":      "gem",
+}
+
+// Maps package URL types to the Xray package types of the components they're converted to.
+var xrayPackageTypes = map[string]string{
+	"maven":    "gav",
+	"docker":   "docker",
+	"rpm":      "rpm",
+	"deb":      "deb",
+	"nuget":    "nuget",
+	"npm":      "npm",
+	"pypi":     "pypi",
+	"composer": "composer",
+	"golang":   "go",
+	"apk":      "alpine",
+	"cargo":    "cargo",
+	"gem":      "gem",
+}
+
+// componentIdToPurl converts a Xray component ID to a package URL (https://github.com/package-url/purl-spec).
+// An empty string is returned in case the component's package type has no package URL equivalent, like generic components.
+// Examples:
+// 1. componentId: "gav://antparent:ant:1.6.5"
+//    Returned value: "pkg:maven/antparent/ant@1.6.5"
+// 2. componentId: "npm://@jfrog/npm_scoped:1.0.0"
+//    Returned value: "pkg:npm/%40jfrog/npm_scoped@1.0.0"
+func componentIdToPurl(componentId string) string {
+	compIdParts := strings.Split(componentId, "://")
+	if len(compIdParts) != 2 {
+		return ""
+	}
+	purlType, exists := purlTypes[compIdParts[0]]
+	if !exists {
+		return ""
+	}
+	compName, compVersion, _ := splitComponentId(componentId)
+	switch purlType {
+	case "maven":
+		compName = strings.Replace(compName, ":", "/", 1)
+	case "npm":
+		compName = strings.Replace(compName, "@", "%40", 1)
+	}
+	purl := purlScheme + purlType + "/" + compName
+	if compVersion != "" {
+		purl += "@" + compVersion
+	}
+	return purl
+}
+
+// PurlToComponentId converts a package URL (https://github.com/package-url/purl-spec) to a Xray component ID.
+// An empty string is returned in case the package URL is invalid or its type isn't supported by Xray.
+// Examples:
+// 1. purl: "pkg:maven/antparent/ant@1.6.5"
+//    Returned value: "gav://antparent:ant:1.6.5"
+// 2. purl: "pkg:npm/%40jfrog/npm_scoped@1.0.0?arch=x64"
+//    Returned value: "npm://@jfrog/npm_scoped:1.0.0"
+func PurlToComponentId(purl string) string {
+	if !strings.HasPrefix(purl, purlScheme) {
+		return ""
+	}
+	purl = strings.TrimPrefix(purl, purlScheme)
+	// Remove the subpath and qualifiers
+	if index := strings.IndexAny(purl, "#?"); index != -1 {
+		purl = purl[:index]
+	}
+	typeAndPath := strings.SplitN(strings.TrimPrefix(purl, "/"), "/", 2)
+	if len(typeAndPath) != 2 {
+		return ""
+	}
+	packageType, exists := xrayPackageTypes[strings.ToLower(typeAndPath[0])]
+	if !exists {
+		return ""
+	}
+	compPath, compVersion := typeAndPath[1], ""
+	if index := strings.LastIndex(compPath, "@"); index > 0 {
+		compPath, compVersion = compPath[:index], compPath[index+1:]
+	}
+	compName, err := url.PathUnescape(compPath)
+	if err != nil {
+		return ""
+	}
+	if compVersion, err = url.PathUnescape(compVersion); err != nil {
+		return ""
+	}
+	if packageType == "gav" {
+		compName = strings.Replace(compName, "/", ":", 1)
+	}
+	componentId := packageType + "://" + compName
+	if compVersion != "" {
+		componentId += ":" + compVersion
+	}
+	return componentId
+}
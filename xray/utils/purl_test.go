@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentIdToPurl(t *testing.T) {
+	tests := []struct {
+		componentId  string
+		expectedPurl string
+	}{
+		{"gav://antparent:ant:1.6.5", "pkg:maven/antparent/ant@1.6.5"},
+		{"npm://mocha:2.4.5", "pkg:npm/mocha@2.4.5"},
+		{"npm://@jfrog/npm_scoped:1.0.0", "pkg:npm/%40jfrog/npm_scoped@1.0.0"},
+		{"pypi://raven:5.13.0", "pkg:pypi/raven@5.13.0"},
+		{"go://github.com/ethereum/go-ethereum:1.8.2", "pkg:golang/github.com/ethereum/go-ethereum@1.8.2"},
+		{"nuget://log4net:9.0.1", "pkg:nuget/log4net@9.0.1"},
+		{"generic://sha256:244fd47e07d1004f0aed9c156aa09083c82bf8944eceb67c946ff7430510a77b/foo.jar", ""},
+		{"invalid-component-id:1.0.0", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedPurl, componentIdToPurl(test.componentId))
+	}
+}
+
+func TestPurlToComponentId(t *testing.T) {
+	tests := []struct {
+		purl                string
+		expectedComponentId string
+	}{
+		{"pkg:maven/antparent/ant@1.6.5", "gav://antparent:ant:1.6.5"},
+		{"pkg:npm/mocha@2.4.5", "npm://mocha:2.4.5"},
+		{"pkg:npm/%40jfrog/npm_scoped@1.0.0?arch=x64", "npm://@jfrog/npm_scoped:1.0.0"},
+		{"pkg:npm/@jfrog/npm_scoped@1.0.0", "npm://@jfrog/npm_scoped:1.0.0"},
+		{"pkg:pypi/raven@5.13.0", "pypi://raven:5.13.0"},
+		{"pkg:golang/github.com/ethereum/go-ethereum@1.8.2#cmd", "go://github.com/ethereum/go-ethereum:1.8.2"},
+		{"pkg:nuget/log4net@9.0.1", "nuget://log4net:9.0.1"},
+		{"pkg:github/package-url/purl-spec@244fd47e07d1004", ""},
+		{"invalid-purl", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedComponentId, PurlToComponentId(test.purl))
+	}
+}
+
+func TestXrayPackageTypes(t *testing.T) {
+	// Each package URL type is converted to one of the Xray package types that are converted to it
+	for purlType, xrayType := range xrayPackageTypes {
+		assert.Equal(t, purlType, purlTypes[xrayType])
+	}
+	for _, purlType := range purlTypes {
+		assert.Contains(t, xrayPackageTypes, purlType)
+	}
+}
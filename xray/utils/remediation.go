@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jfrog/gofrog/version"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+type RemediationMode string
+
+const (
+	// Only suggests upgrades of the vulnerable direct dependencies.
+	RemediationSuggest RemediationMode = "suggest"
+	// Suggests upgrades and prints a unified diff of the changes in the descriptors of the projects.
+	RemediationDiff RemediationMode = "diff"
+	// Suggests upgrades and applies them to the descriptors of the projects.
+	RemediationPatch RemediationMode = "patch"
+)
+
+var RemediationModes = []string{string(RemediationSuggest), string(RemediationDiff), string(RemediationPatch)}
+
+// GetRemediationMode converts a remediation mode name to a RemediationMode. An empty name means no remediation.
+func GetRemediationMode(mode string) (RemediationMode, error) {
+	if mode == "" {
+		return "", nil
+	}
+	for _, remediationMode := range RemediationModes {
+		if strings.EqualFold(mode, remediationMode) {
+			return RemediationMode(remediationMode), nil
+		}
+	}
+	return "", errorutils.CheckErrorf("only the following remediation modes are supported: " + coreutils.ListToText(RemediationModes))
+}
+
+// The issues found in a direct dependency, either in the dependency itself or in one of its transitive dependencies.
+type directDependencyIssues struct {
+	row formats.RemediationRow
+	// The fixed versions of each of the issues found in the direct dependency itself, by issue ID.
+	directIssues map[string][]string
+	// The issues found in the transitive dependencies, by issue ID and impacted package.
+	transitiveIssues map[string]formats.TransitiveIssueRow
+}
+
+// GetRemediations suggests an upgrade for each of the direct dependencies impacted by the vulnerabilities (or security violations) in the results.
+// The suggested version is the smallest of the fixed versions that fixes the most issues found in the direct dependency itself.
+// Issues in transitive dependencies are reported with the fixed versions of the transitive dependencies, since the versions of the direct dependency that fix them
+// can't be deduced from the scan results. The direct dependency should be upgraded to a version that depends on one of them.
+// scannedPaths holds the path of the descriptor each of the results was created from (by index), and may be nil.
+func GetRemediations(results []services.ScanResponse, scannedPaths []string, includeVulnerabilities, multipleRoots bool) ([]formats.RemediationRow, error) {
+	issues := splitScanResultsWithPaths(results, scannedPaths)
+	var securityRows []formats.VulnerabilityOrViolationRow
+	var err error
+	if includeVulnerabilities {
+		securityRows, err = prepareVulnerabilities(issues.vulnerabilities, issues.vulnerabilitiesPaths, multipleRoots, false)
+	} else {
+		securityRows, _, _, err = prepareViolations(issues.violations, issues.violationsPaths, multipleRoots, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dependenciesIssues := make(map[string]*directDependencyIssues)
+	var dependenciesKeys []string
+	for _, securityRow := range securityRows {
+		issueId := getRemediationIssueId(securityRow)
+		for _, component := range securityRow.Components {
+			key := strings.Join([]string{securityRow.DescriptorPath, securityRow.ImpactedPackageType, component.Name, component.Version}, "|")
+			dependencyIssues, exist := dependenciesIssues[key]
+			if !exist {
+				dependencyIssues = &directDependencyIssues{
+					row: formats.RemediationRow{
+						DirectDependencyName:    component.Name,
+						DirectDependencyVersion: component.Version,
+						DirectDependencyType:    securityRow.ImpactedPackageType,
+						DescriptorPath:          securityRow.DescriptorPath,
+					},
+					directIssues:     make(map[string][]string),
+					transitiveIssues: make(map[string]formats.TransitiveIssueRow),
+				}
+				dependenciesIssues[key] = dependencyIssues
+				dependenciesKeys = append(dependenciesKeys, key)
+			}
+			if component.Name == securityRow.ImpactedPackageName && component.Version == securityRow.ImpactedPackageVersion {
+				dependencyIssues.directIssues[issueId] = append(dependencyIssues.directIssues[issueId], securityRow.FixedVersions...)
+			} else {
+				dependencyIssues.transitiveIssues[strings.Join([]string{issueId, securityRow.ImpactedPackageName, securityRow.ImpactedPackageVersion}, "|")] = formats.TransitiveIssueRow{
+					IssueId:                issueId,
+					ImpactedPackageName:    securityRow.ImpactedPackageName,
+					ImpactedPackageVersion: securityRow.ImpactedPackageVersion,
+					FixedVersions:          securityRow.FixedVersions,
+				}
+			}
+		}
+	}
+
+	sort.Strings(dependenciesKeys)
+	var remediations []formats.RemediationRow
+	for _, key := range dependenciesKeys {
+		remediations = append(remediations, dependenciesIssues[key].getRemediation())
+	}
+	return remediations, nil
+}
+
+func (dpi *directDependencyIssues) getRemediation() formats.RemediationRow {
+	row := dpi.row
+	// Try each of the fixed versions above the current version, and choose the smallest one that fixes the most issues.
+	var bestFixedIssues []string
+	for _, candidate := range dpi.getCandidateVersions() {
+		var fixedIssues []string
+		for issueId, fixedVersions := range dpi.directIssues {
+			if isFixedByUpgrade(fixedVersions, candidate) {
+				fixedIssues = append(fixedIssues, issueId)
+			}
+		}
+		if len(fixedIssues) > len(bestFixedIssues) {
+			row.SuggestedVersion, bestFixedIssues = candidate, fixedIssues
+		}
+	}
+	fixed := make(map[string]bool)
+	for _, issueId := range bestFixedIssues {
+		fixed[issueId] = true
+	}
+	for issueId := range dpi.directIssues {
+		if !fixed[issueId] {
+			row.RemainingIssues = append(row.RemainingIssues, issueId)
+		}
+	}
+	var transitiveIssuesKeys []string
+	for key, transitiveIssue := range dpi.transitiveIssues {
+		if _, isDirect := dpi.directIssues[transitiveIssue.IssueId]; !isDirect {
+			transitiveIssuesKeys = append(transitiveIssuesKeys, key)
+		}
+	}
+	sort.Strings(transitiveIssuesKeys)
+	for _, key := range transitiveIssuesKeys {
+		row.TransitiveIssues = append(row.TransitiveIssues, dpi.transitiveIssues[key])
+	}
+	row.FixedIssues = bestFixedIssues
+	sort.Strings(row.FixedIssues)
+	sort.Strings(row.RemainingIssues)
+	return row
+}
+
+// PrintRemediationsTable prints the upgrades suggested to fix the issues found in a table.
+// Set printExtended to true to print fields with 'extended' tag.
+func PrintRemediationsTable(remediations []formats.RemediationRow, printExtended bool) error {
+	tableRows := formats.ConvertToRemediationTableRow(remediations)
+	for i := range tableRows {
+		tableRows[i].DescriptorPath = getDescriptorPath([]string{tableRows[i].DescriptorPath}, 0, true)
+	}
+	return coreutils.PrintTable(tableRows, "Remediation Suggestions", "No remediation is needed", printExtended)
+}
+
+// Returns the fixed versions of the direct issues that are above the current version, sorted in ascending order.
+func (dpi *directDependencyIssues) getCandidateVersions() (candidates []string) {
+	currentVersion := dpi.row.DirectDependencyVersion
+	exists := make(map[string]bool)
+	for _, fixedVersions := range dpi.directIssues {
+		for _, fixedVersion := range parseFixedVersions(fixedVersions) {
+			if !exists[fixedVersion] && compareVersions(fixedVersion, currentVersion) > 0 {
+				exists[fixedVersion] = true
+				candidates = append(candidates, fixedVersion)
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return compareVersions(candidates[i], candidates[j]) < 0
+	})
+	return
+}
+
+// Xray returns fixed versions as ranges, like [1.2.3] or [1.2.3,2.0.0). Returns the lowest version of each range.
+func parseFixedVersions(fixedVersions []string) (versions []string) {
+	for _, fixedVersion := range fixedVersions {
+		for _, rangeBound := range strings.Split(strings.Trim(fixedVersion, "[]() "), ",") {
+			if rangeBound = strings.TrimSpace(rangeBound); rangeBound != "" {
+				versions = append(versions, rangeBound)
+				break
+			}
+		}
+	}
+	return
+}
+
+// Returns true if upgrading to targetVersion fixes an issue with the given fixed versions.
+// An issue may be fixed in several release lines (like 3.10.2 and 4.17.21), so the target version must be at least the fixed version of its own major version,
+// or above all the fixed versions.
+func isFixedByUpgrade(fixedVersions []string, targetVersion string) bool {
+	versions := parseFixedVersions(fixedVersions)
+	if len(versions) == 0 {
+		return false
+	}
+	maxFixedVersion := versions[0]
+	for _, fixedVersion := range versions {
+		if getMajorVersion(fixedVersion) == getMajorVersion(targetVersion) && compareVersions(targetVersion, fixedVersion) >= 0 {
+			return true
+		}
+		if compareVersions(fixedVersion, maxFixedVersion) > 0 {
+			maxFixedVersion = fixedVersion
+		}
+	}
+	return compareVersions(targetVersion, maxFixedVersion) >= 0
+}
+
+// Returns a positive number if firstVersion is greater than secondVersion, a negative number if it is lower, and zero if they are equal.
+// The 'v' prefix of Go modules versions is ignored.
+func compareVersions(firstVersion, secondVersion string) int {
+	return version.NewVersion(strings.TrimPrefix(secondVersion, "v")).Compare(strings.TrimPrefix(firstVersion, "v"))
+}
+
+func getMajorVersion(versionStr string) string {
+	return strings.Split(strings.TrimPrefix(versionStr, "v"), ".")[0]
+}
+
+// Issues are identified by their Xray issue ID, or by their first CVE if the issue ID is missing.
+func getRemediationIssueId(securityRow formats.VulnerabilityOrViolationRow) string {
+	if securityRow.IssueId == "" && len(securityRow.Cves) > 0 {
+		return securityRow.Cves[0].Id
+	}
+	return securityRow.IssueId
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRemediations(t *testing.T) {
+	directPath := [][]services.ImpactPathNode{{{ComponentId: "npm://root:1.0.0"}, {ComponentId: "npm://lodash:4.17.15"}}}
+	transitivePath := [][]services.ImpactPathNode{{{ComponentId: "npm://root:1.0.0"}, {ComponentId: "npm://express:4.17.1"}, {ComponentId: "npm://qs:6.7.0"}}}
+	results := []services.ScanResponse{{
+		Vulnerabilities: []services.Vulnerability{
+			{IssueId: "XRAY-1", Components: map[string]services.Component{"npm://lodash:4.17.15": {FixedVersions: []string{"[4.17.19]"}, ImpactPaths: directPath}}},
+			{IssueId: "XRAY-2", Components: map[string]services.Component{"npm://lodash:4.17.15": {FixedVersions: []string{"[4.17.21]"}, ImpactPaths: directPath}}},
+			// Fixed in two release lines. 4.17.21 is above both.
+			{IssueId: "XRAY-3", Components: map[string]services.Component{"npm://lodash:4.17.15": {FixedVersions: []string{"[3.10.2]", "[4.17.20]"}, ImpactPaths: directPath}}},
+			{IssueId: "XRAY-4", Components: map[string]services.Component{"npm://lodash:4.17.15": {ImpactPaths: directPath}}},
+			{IssueId: "XRAY-5", Components: map[string]services.Component{"npm://qs:6.7.0": {FixedVersions: []string{"[6.7.3]"}, ImpactPaths: transitivePath}}},
+		},
+	}}
+
+	remediations, err := GetRemediations(results, []string{"/project/package.json"}, true, false)
+	assert.NoError(t, err)
+	if assert.Len(t, remediations, 2) {
+		express, lodash := remediations[0], remediations[1]
+		assert.Equal(t, "express", express.DirectDependencyName)
+		assert.Empty(t, express.SuggestedVersion)
+		assert.Empty(t, express.RemainingIssues)
+		// The transitive issue is reported on the direct dependency at the head of its impact path, with the fixed versions of the transitive dependency
+		assert.Equal(t, []formats.TransitiveIssueRow{{IssueId: "XRAY-5", ImpactedPackageName: "qs", ImpactedPackageVersion: "6.7.0", FixedVersions: []string{"[6.7.3]"}}}, express.TransitiveIssues)
+
+		assert.Equal(t, "lodash", lodash.DirectDependencyName)
+		assert.Equal(t, "4.17.15", lodash.DirectDependencyVersion)
+		assert.Equal(t, "4.17.21", lodash.SuggestedVersion)
+		assert.Equal(t, []string{"XRAY-1", "XRAY-2", "XRAY-3"}, lodash.FixedIssues)
+		assert.Equal(t, []string{"XRAY-4"}, lodash.RemainingIssues)
+		assert.Empty(t, lodash.TransitiveIssues)
+		assert.Equal(t, "/project/package.json", lodash.DescriptorPath)
+	}
+}
+
+func TestIsFixedByUpgrade(t *testing.T) {
+	testCases := []struct {
+		fixedVersions []string
+		targetVersion string
+		expected      bool
+	}{
+		{[]string{"[1.2.3]"}, "1.2.3", true},
+		{[]string{"[1.2.3]"}, "1.2.2", false},
+		{[]string{"[1.2.3]"}, "2.0.0", true},
+		{[]string{"[3.10.2]", "[4.17.21]"}, "3.10.2", true},
+		{[]string{"[3.10.2]", "[4.17.21]"}, "4.0.0", false},
+		{[]string{"[v0.3.8]"}, "v0.3.10", true},
+		{[]string{"[2.0.0,3.0.0)"}, "2.5.0", true},
+		{nil, "2.5.0", false},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, isFixedByUpgrade(testCase.fixedVersions, testCase.targetVersion), "fixed versions: %v, target version: %s", testCase.fixedVersions, testCase.targetVersion)
+	}
+}
+
+func TestGetRemediationMode(t *testing.T) {
+	mode, err := GetRemediationMode("Diff")
+	assert.NoError(t, err)
+	assert.Equal(t, RemediationDiff, mode)
+	_, err = GetRemediationMode("upgrade")
+	assert.Error(t, err)
+}
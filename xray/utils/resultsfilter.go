@@ -0,0 +1,70 @@
+package utils
+
+import "github.com/jfrog/jfrog-client-go/xray/services"
+
+// issueMatcher decides whether an issue found in a scan matches some criteria, for each of the components it impacts.
+type issueMatcher interface {
+	matchViolation(violation services.Violation, componentId string) bool
+	matchVulnerability(vulnerability services.Vulnerability, componentId string) bool
+	matchLicense(license services.License, componentId string) bool
+}
+
+// Splits the issues of a scan response into the issues that match the matcher and the issues that don't.
+// An issue that impacts several components may be split between the two responses.
+func splitScanResponse(response services.ScanResponse, matcher issueMatcher) (matched, unmatched services.ScanResponse) {
+	matched, unmatched = response, response
+	matched.Violations, matched.Vulnerabilities, matched.Licenses = nil, nil, nil
+	unmatched.Violations, unmatched.Vulnerabilities, unmatched.Licenses = nil, nil, nil
+	for _, violation := range response.Violations {
+		matchedComponents, unmatchedComponents := splitComponentsByMatch(violation.Components, func(componentId string) bool {
+			return matcher.matchViolation(violation, componentId)
+		})
+		if len(matchedComponents) > 0 {
+			violation.Components = matchedComponents
+			matched.Violations = append(matched.Violations, violation)
+		}
+		if len(unmatchedComponents) > 0 {
+			violation.Components = unmatchedComponents
+			unmatched.Violations = append(unmatched.Violations, violation)
+		}
+	}
+	for _, vulnerability := range response.Vulnerabilities {
+		matchedComponents, unmatchedComponents := splitComponentsByMatch(vulnerability.Components, func(componentId string) bool {
+			return matcher.matchVulnerability(vulnerability, componentId)
+		})
+		if len(matchedComponents) > 0 {
+			vulnerability.Components = matchedComponents
+			matched.Vulnerabilities = append(matched.Vulnerabilities, vulnerability)
+		}
+		if len(unmatchedComponents) > 0 {
+			vulnerability.Components = unmatchedComponents
+			unmatched.Vulnerabilities = append(unmatched.Vulnerabilities, vulnerability)
+		}
+	}
+	for _, license := range response.Licenses {
+		matchedComponents, unmatchedComponents := splitComponentsByMatch(license.Components, func(componentId string) bool {
+			return matcher.matchLicense(license, componentId)
+		})
+		if len(matchedComponents) > 0 {
+			license.Components = matchedComponents
+			matched.Licenses = append(matched.Licenses, license)
+		}
+		if len(unmatchedComponents) > 0 {
+			license.Components = unmatchedComponents
+			unmatched.Licenses = append(unmatched.Licenses, license)
+		}
+	}
+	return
+}
+
+func splitComponentsByMatch(components map[string]services.Component, match func(componentId string) bool) (matched, unmatched map[string]services.Component) {
+	matched, unmatched = make(map[string]services.Component), make(map[string]services.Component)
+	for componentId, component := range components {
+		if match(componentId) {
+			matched[componentId] = component
+		} else {
+			unmatched[componentId] = component
+		}
+	}
+	return
+}
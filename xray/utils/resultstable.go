@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,37 +27,59 @@ const noContextMessage = "Note: no context was provided, so no policy could be d
 // In case one (or more) of the violations contains the field FailBuild set to true, CliError with exit code 3 will be returned.
 // Set printExtended to true to print fields with 'extended' tag.
 func PrintViolationsTable(violations []services.Violation, multipleRoots, printExtended bool) error {
-	securityViolationsRows, licenseViolationsRows, operationalRiskViolationsRows, err := prepareViolations(violations, multipleRoots, true)
+	return printViolationsTable(violations, nil, multipleRoots, printExtended, "")
+}
+
+// The titlePrefix is added to the title of each table, like "New " or "Resolved " in baseline mode.
+// descriptorPaths holds the path of the descriptor each of the violations was found in (by index), and may be nil.
+func printViolationsTable(violations []services.Violation, descriptorPaths []string, multipleRoots, printExtended bool, titlePrefix string) error {
+	securityViolationsRows, licenseViolationsRows, operationalRiskViolationsRows, err := prepareViolations(violations, descriptorPaths, multipleRoots, true)
 	if err != nil {
 		return err
 	}
 
 	// Print tables
-	err = coreutils.PrintTable(formats.ConvertToVulnerabilityTableRow(securityViolationsRows), "Security Violations", "No security violations were found", printExtended)
+	err = coreutils.PrintTable(formats.ConvertToVulnerabilityTableRow(securityViolationsRows), titlePrefix+"Security Violations", "No "+strings.ToLower(titlePrefix)+"security violations were found", printExtended)
 	if err != nil {
 		return err
 	}
-	err = coreutils.PrintTable(formats.ConvertToLicenseViolationTableRow(licenseViolationsRows), "License Compliance Violations", "No license compliance violations were found", printExtended)
+	err = coreutils.PrintTable(formats.ConvertToLicenseViolationTableRow(licenseViolationsRows), titlePrefix+"License Compliance Violations", "No "+strings.ToLower(titlePrefix)+"license compliance violations were found", printExtended)
 	if err != nil {
 		return err
 	}
 	if len(operationalRiskViolationsRows) > 0 {
-		return coreutils.PrintTable(formats.ConvertToOperationalRiskViolationTableRow(operationalRiskViolationsRows), "Operational Risk Violations", "No operational risk violations were found", printExtended)
+		return coreutils.PrintTable(formats.ConvertToOperationalRiskViolationTableRow(operationalRiskViolationsRows), titlePrefix+"Operational Risk Violations", "No "+strings.ToLower(titlePrefix)+"operational risk violations were found", printExtended)
 	}
 	return nil
 }
 
 // Prepare violations for all non-table formats (without style or emoji)
 func PrepareViolations(violations []services.Violation, multipleRoots bool) ([]formats.VulnerabilityOrViolationRow, []formats.LicenseViolationRow, []formats.OperationalRiskViolationRow, error) {
-	return prepareViolations(violations, multipleRoots, false)
+	return prepareViolations(violations, nil, multipleRoots, false)
+}
+
+// When no watches are provided, the only violations in the results are the license violations of the local license policy (see LicensePolicy).
+// They are printed along with the vulnerabilities, in a table of their own.
+func printLicensePolicyViolationsTable(violations []services.Violation, descriptorPaths []string, multipleRoots, printExtended bool, titlePrefix string) error {
+	licenseViolationsRows, err := prepareLicensePolicyViolations(violations, descriptorPaths, multipleRoots, true)
+	if err != nil || len(licenseViolationsRows) == 0 {
+		return err
+	}
+	return coreutils.PrintTable(formats.ConvertToLicenseViolationTableRow(licenseViolationsRows), titlePrefix+"License Policy Violations", "", printExtended)
 }
 
-func prepareViolations(violations []services.Violation, multipleRoots, isTable bool) ([]formats.VulnerabilityOrViolationRow, []formats.LicenseViolationRow, []formats.OperationalRiskViolationRow, error) {
+func prepareLicensePolicyViolations(violations []services.Violation, descriptorPaths []string, multipleRoots, isTable bool) ([]formats.LicenseViolationRow, error) {
+	_, licenseViolationsRows, _, err := prepareViolations(violations, descriptorPaths, multipleRoots, isTable)
+	return licenseViolationsRows, err
+}
+
+func prepareViolations(violations []services.Violation, descriptorPaths []string, multipleRoots, isTable bool) ([]formats.VulnerabilityOrViolationRow, []formats.LicenseViolationRow, []formats.OperationalRiskViolationRow, error) {
 	var securityViolationsRows []formats.VulnerabilityOrViolationRow
 	var licenseViolationsRows []formats.LicenseViolationRow
 	var operationalRiskViolationsRows []formats.OperationalRiskViolationRow
 
-	for _, violation := range violations {
+	for violationIndex, violation := range violations {
+		descriptorPath := getDescriptorPath(descriptorPaths, violationIndex, isTable)
 		impactedPackagesNames, impactedPackagesVersions, impactedPackagesTypes, fixedVersions, components, impactPaths, err := splitComponents(violation.Components, multipleRoots)
 		if err != nil {
 			return nil, nil, nil, err
@@ -82,6 +106,7 @@ func prepareViolations(violations []services.Violation, multipleRoots, isTable b
 						JfrogResearchInformation: jfrogResearchInfo,
 						ImpactPaths:              impactPaths[compIndex],
 						Technology:               coreutils.Technology(violation.Technology),
+						DescriptorPath:           descriptorPath,
 					},
 				)
 			}
@@ -96,6 +121,7 @@ func prepareViolations(violations []services.Violation, multipleRoots, isTable b
 						ImpactedPackageVersion: impactedPackagesVersions[compIndex],
 						ImpactedPackageType:    impactedPackagesTypes[compIndex],
 						Components:             components[compIndex],
+						DescriptorPath:         descriptorPath,
 					},
 				)
 			}
@@ -117,6 +143,7 @@ func prepareViolations(violations []services.Violation, multipleRoots, isTable b
 					LatestVersion:          violationOpRiskData.latestVersion,
 					RiskReason:             violationOpRiskData.riskReason,
 					EolMessage:             violationOpRiskData.eolMessage,
+					DescriptorPath:         descriptorPath,
 				}
 				operationalRiskViolationsRows = append(operationalRiskViolationsRows, *operationalRiskViolationsRow)
 			}
@@ -148,24 +175,28 @@ func prepareViolations(violations []services.Violation, multipleRoots, isTable b
 // Set printExtended to true to print fields with 'extended' tag.
 func PrintVulnerabilitiesTable(vulnerabilities []services.Vulnerability, multipleRoots, printExtended bool) error {
 	log.Output(noContextMessage + "Below are all vulnerabilities detected.")
+	return printVulnerabilitiesTable(vulnerabilities, nil, multipleRoots, printExtended, "")
+}
 
-	vulnerabilitiesRows, err := prepareVulnerabilities(vulnerabilities, multipleRoots, true)
+func printVulnerabilitiesTable(vulnerabilities []services.Vulnerability, descriptorPaths []string, multipleRoots, printExtended bool, titlePrefix string) error {
+	vulnerabilitiesRows, err := prepareVulnerabilities(vulnerabilities, descriptorPaths, multipleRoots, true)
 	if err != nil {
 		return err
 	}
 
-	return coreutils.PrintTable(formats.ConvertToVulnerabilityTableRow(vulnerabilitiesRows), "Vulnerabilities", "✨ No vulnerabilities were found ✨", printExtended)
+	return coreutils.PrintTable(formats.ConvertToVulnerabilityTableRow(vulnerabilitiesRows), titlePrefix+"Vulnerabilities", "✨ No "+strings.ToLower(titlePrefix)+"vulnerabilities were found ✨", printExtended)
 }
 
 // Prepare vulnerabilities for all non-table formats (without style or emoji)
 func PrepareVulnerabilities(vulnerabilities []services.Vulnerability, multipleRoots bool) ([]formats.VulnerabilityOrViolationRow, error) {
-	return prepareVulnerabilities(vulnerabilities, multipleRoots, false)
+	return prepareVulnerabilities(vulnerabilities, nil, multipleRoots, false)
 }
 
-func prepareVulnerabilities(vulnerabilities []services.Vulnerability, multipleRoots, isTable bool) ([]formats.VulnerabilityOrViolationRow, error) {
+func prepareVulnerabilities(vulnerabilities []services.Vulnerability, descriptorPaths []string, multipleRoots, isTable bool) ([]formats.VulnerabilityOrViolationRow, error) {
 	var vulnerabilitiesRows []formats.VulnerabilityOrViolationRow
 
-	for _, vulnerability := range vulnerabilities {
+	for vulnerabilityIndex, vulnerability := range vulnerabilities {
+		descriptorPath := getDescriptorPath(descriptorPaths, vulnerabilityIndex, isTable)
 		impactedPackagesNames, impactedPackagesVersions, impactedPackagesTypes, fixedVersions, components, impactPaths, err := splitComponents(vulnerability.Components, multipleRoots)
 		if err != nil {
 			return nil, err
@@ -190,6 +221,7 @@ func prepareVulnerabilities(vulnerabilities []services.Vulnerability, multipleRo
 					JfrogResearchInformation: jfrogResearchInfo,
 					ImpactPaths:              impactPaths[compIndex],
 					Technology:               coreutils.Technology(vulnerability.Technology),
+					DescriptorPath:           descriptorPath,
 				},
 			)
 		}
@@ -209,18 +241,27 @@ func prepareVulnerabilities(vulnerabilities []services.Vulnerability, multipleRo
 // In case multipleRoots is true, the field Component will show the root of each impact path, otherwise it will show the root's child.
 // Set printExtended to true to print fields with 'extended' tag.
 func PrintLicensesTable(licenses []services.License, multipleRoots, printExtended bool) error {
-	licensesRows, err := PrepareLicenses(licenses, multipleRoots)
+	return printLicensesTable(licenses, nil, multipleRoots, printExtended, "")
+}
+
+func printLicensesTable(licenses []services.License, descriptorPaths []string, multipleRoots, printExtended bool, titlePrefix string) error {
+	licensesRows, err := prepareLicenses(licenses, descriptorPaths, multipleRoots, true)
 	if err != nil {
 		return err
 	}
 
-	return coreutils.PrintTable(formats.ConvertToLicenseTableRow(licensesRows), "Licenses", "No licenses were found", printExtended)
+	return coreutils.PrintTable(formats.ConvertToLicenseTableRow(licensesRows), titlePrefix+"Licenses", "No "+strings.ToLower(titlePrefix)+"licenses were found", printExtended)
 }
 
 func PrepareLicenses(licenses []services.License, multipleRoots bool) ([]formats.LicenseRow, error) {
+	return prepareLicenses(licenses, nil, multipleRoots, false)
+}
+
+func prepareLicenses(licenses []services.License, descriptorPaths []string, multipleRoots, isTable bool) ([]formats.LicenseRow, error) {
 	var licensesRows []formats.LicenseRow
 
-	for _, license := range licenses {
+	for licenseIndex, license := range licenses {
+		descriptorPath := getDescriptorPath(descriptorPaths, licenseIndex, isTable)
 		impactedPackagesNames, impactedPackagesVersions, impactedPackagesTypes, _, components, impactPaths, err := splitComponents(license.Components, multipleRoots)
 		if err != nil {
 			return nil, err
@@ -234,6 +275,7 @@ func PrepareLicenses(licenses []services.License, multipleRoots bool) ([]formats
 					ImpactedPackageType:    impactedPackagesTypes[compIndex],
 					Components:             components[compIndex],
 					ImpactPaths:            impactPaths[compIndex],
+					DescriptorPath:         descriptorPath,
 				},
 			)
 		}
@@ -242,6 +284,108 @@ func PrepareLicenses(licenses []services.License, multipleRoots bool) ([]formats
 	return licensesRows, nil
 }
 
+// PrintSuppressedIssuesTable prints the issues that were suppressed by local ignore rules in a table, along with the justification of each rule.
+// Vulnerabilities are printed if includeVulnerabilities is true, otherwise violations are printed. Licenses are printed if includeLicenses is true.
+// Set printExtended to true to print fields with 'extended' tag.
+func PrintSuppressedIssuesTable(suppressed []SuppressedScanResults, includeVulnerabilities, includeLicenses, multipleRoots, printExtended bool) error {
+	suppressedRows, err := prepareSuppressedIssues(suppressed, includeVulnerabilities, includeLicenses, multipleRoots, true)
+	if err != nil {
+		return err
+	}
+
+	return coreutils.PrintTable(formats.ConvertToSuppressedIssueTableRow(suppressedRows), "Suppressed Issues", "No issues were suppressed", printExtended)
+}
+
+// Prepare suppressed issues for all non-table formats (without style or emoji)
+func PrepareSuppressedIssues(suppressed []SuppressedScanResults, includeVulnerabilities, includeLicenses, multipleRoots bool) ([]formats.SuppressedIssueRow, error) {
+	return prepareSuppressedIssues(suppressed, includeVulnerabilities, includeLicenses, multipleRoots, false)
+}
+
+func prepareSuppressedIssues(suppressed []SuppressedScanResults, includeVulnerabilities, includeLicenses, multipleRoots, isTable bool) ([]formats.SuppressedIssueRow, error) {
+	var suppressedRows []formats.SuppressedIssueRow
+	for _, suppressedResults := range suppressed {
+		issues := splitScanResultsWithPaths(suppressedResults.Results, suppressedResults.ScannedPaths)
+		newRow := func(packageName, packageVersion, packageType string, components []formats.ComponentRow, descriptorPath string) formats.SuppressedIssueRow {
+			return formats.SuppressedIssueRow{
+				ImpactedPackageName:    packageName,
+				ImpactedPackageVersion: packageVersion,
+				ImpactedPackageType:    packageType,
+				Components:             components,
+				Justification:          suppressedResults.Rule.Justification,
+				ExpiresAt:              suppressedResults.Rule.ExpiresAt,
+				DescriptorPath:         descriptorPath,
+			}
+		}
+		var securityRows []formats.VulnerabilityOrViolationRow
+		var licenseViolationRows []formats.LicenseViolationRow
+		var err error
+		if includeVulnerabilities {
+			if securityRows, err = prepareVulnerabilities(issues.vulnerabilities, issues.vulnerabilitiesPaths, multipleRoots, isTable); err != nil {
+				return nil, err
+			}
+			if licenseViolationRows, err = prepareLicensePolicyViolations(issues.violations, issues.violationsPaths, multipleRoots, isTable); err != nil {
+				return nil, err
+			}
+		} else {
+			var operationalRiskRows []formats.OperationalRiskViolationRow
+			if securityRows, licenseViolationRows, operationalRiskRows, err = prepareViolations(issues.violations, issues.violationsPaths, multipleRoots, isTable); err != nil {
+				return nil, err
+			}
+			for _, operationalRisk := range operationalRiskRows {
+				row := newRow(operationalRisk.ImpactedPackageName, operationalRisk.ImpactedPackageVersion, operationalRisk.ImpactedPackageType, operationalRisk.Components, operationalRisk.DescriptorPath)
+				row.Severity, row.SeverityNumValue = operationalRisk.Severity, operationalRisk.SeverityNumValue
+				suppressedRows = append(suppressedRows, row)
+			}
+		}
+		for _, licenseViolation := range licenseViolationRows {
+			row := newRow(licenseViolation.ImpactedPackageName, licenseViolation.ImpactedPackageVersion, licenseViolation.ImpactedPackageType, licenseViolation.Components, licenseViolation.DescriptorPath)
+			row.LicenseKey, row.Severity, row.SeverityNumValue = licenseViolation.LicenseKey, licenseViolation.Severity, licenseViolation.SeverityNumValue
+			suppressedRows = append(suppressedRows, row)
+		}
+		for _, security := range securityRows {
+			row := newRow(security.ImpactedPackageName, security.ImpactedPackageVersion, security.ImpactedPackageType, security.Components, security.DescriptorPath)
+			row.IssueId, row.Cves, row.Severity, row.SeverityNumValue = security.IssueId, security.Cves, security.Severity, security.SeverityNumValue
+			suppressedRows = append(suppressedRows, row)
+		}
+		if includeLicenses {
+			licenseRows, err := prepareLicenses(issues.licenses, issues.licensesPaths, multipleRoots, isTable)
+			if err != nil {
+				return nil, err
+			}
+			for _, license := range licenseRows {
+				row := newRow(license.ImpactedPackageName, license.ImpactedPackageVersion, license.ImpactedPackageType, license.Components, license.DescriptorPath)
+				row.LicenseKey = license.LicenseKey
+				suppressedRows = append(suppressedRows, row)
+			}
+		}
+	}
+
+	sort.SliceStable(suppressedRows, func(i, j int) bool {
+		return suppressedRows[i].SeverityNumValue > suppressedRows[j].SeverityNumValue
+	})
+	return suppressedRows, nil
+}
+
+// Returns the descriptor path of the issue in the given index, or an empty string if it's unknown.
+// In tables, paths under the working directory are shown relative to it, to keep the table narrow.
+func getDescriptorPath(descriptorPaths []string, index int, isTable bool) string {
+	if index >= len(descriptorPaths) || descriptorPaths[index] == "" {
+		return ""
+	}
+	descriptorPath := descriptorPaths[index]
+	if !isTable {
+		return descriptorPath
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return descriptorPath
+	}
+	if relativePath, err := filepath.Rel(wd, descriptorPath); err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return relativePath
+	}
+	return descriptorPath
+}
+
 func convertCves(cves []services.Cve) []formats.CveRow {
 	var cveRows []formats.CveRow
 	for _, cveObj := range cves {
@@ -302,6 +446,8 @@ var packageTypes = map[string]string{
 	"composer": "Composer",
 	"go":       "Go",
 	"alpine":   "Alpine",
+	"cargo":    "Cargo",
+	"gem":      "RubyGems",
 }
 
 // splitComponentId splits a Xray component ID to the component name, version and package type.
@@ -120,3 +120,17 @@ func newInt64Ptr(v int64) *int64 {
 func newFloat64Ptr(v float64) *float64 {
 	return &v
 }
+
+func TestConvertScanToSimpleJsonDescriptorPaths(t *testing.T) {
+	results := []services.ScanResponse{
+		{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-1", Components: map[string]services.Component{"npm://debug:2.6.9": {}}}}},
+		{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-2", Components: map[string]services.Component{"gav://org.lib:lib:1.0.0": {}}}}},
+	}
+	simpleJson, err := convertScanToSimpleJson(results, []string{"/project/frontend/package.json", "/project/backend/pom.xml"}, nil, true, false, false)
+	assert.NoError(t, err)
+	descriptorPaths := map[string]string{}
+	for _, row := range simpleJson.Vulnerabilities {
+		descriptorPaths[row.IssueId] = row.DescriptorPath
+	}
+	assert.Equal(t, map[string]string{"XRAY-1": "/project/frontend/package.json", "XRAY-2": "/project/backend/pom.xml"}, descriptorPaths)
+}
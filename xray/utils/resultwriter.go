@@ -20,72 +20,310 @@ type OutputFormat string
 
 const (
 	// OutputFormat values
-	Table      OutputFormat = "table"
-	Json       OutputFormat = "json"
-	SimpleJson OutputFormat = "simple-json"
-	Sarif      OutputFormat = "sarif"
+	Table        OutputFormat = "table"
+	Json         OutputFormat = "json"
+	SimpleJson   OutputFormat = "simple-json"
+	Sarif        OutputFormat = "sarif"
+	CycloneDx    OutputFormat = "cyclonedx"
+	Spdx         OutputFormat = "spdx"
+	SpdxTagValue OutputFormat = "spdx-tag-value"
+	Junit        OutputFormat = "junit"
 )
 
 const missingCveScore = "0"
 const maxPossibleCve = 10.0
 
-var OutputFormats = []string{string(Table), string(Json), string(SimpleJson), string(Sarif)}
+var OutputFormats = []string{string(Table), string(Json), string(SimpleJson), string(Sarif), string(CycloneDx), string(Spdx), string(SpdxTagValue), string(Junit)}
+
+type ResultsWriter struct {
+	// The scan results to print
+	results []services.ScanResponse
+	// The path of the descriptor (or file) each of the results was created from, by index. Each of the issues is tagged with its path.
+	scannedPaths []string
+	// The dependency trees that were sent to Xray. Used to list all the scanned components in SBOM formats.
+	dependencyTrees []*services.GraphNode
+	// Errors that occurred during the scan. Printed only on SimpleJson format.
+	errors []formats.SimpleJsonError
+	// The results of a previous scan. If set, only issues that were added or resolved since are printed.
+	baseline []services.ScanResponse
+	// The issues that were suppressed by local ignore rules. Printed separately from the results.
+	suppressed []SuppressedScanResults
+	// Upgrades of direct dependencies suggested to fix the issues found. Printed only on Table and SimpleJson formats.
+	remediations           []formats.RemediationRow
+	format                 OutputFormat
+	includeVulnerabilities bool
+	includeLicenses        bool
+	isMultipleRoots        bool
+	printExtended          bool
+}
+
+func NewResultsWriter(results []services.ScanResponse) *ResultsWriter {
+	return &ResultsWriter{results: results}
+}
+
+// SetScannedPaths sets the path of the descriptor or file each of the results was created from (by index), like the package.json of an audited project.
+func (rw *ResultsWriter) SetScannedPaths(scannedPaths []string) *ResultsWriter {
+	rw.scannedPaths = scannedPaths
+	return rw
+}
+
+func (rw *ResultsWriter) SetDependencyTrees(dependencyTrees []*services.GraphNode) *ResultsWriter {
+	rw.dependencyTrees = dependencyTrees
+	return rw
+}
+
+func (rw *ResultsWriter) SetErrors(errors []formats.SimpleJsonError) *ResultsWriter {
+	rw.errors = errors
+	return rw
+}
+
+// SetBaselineResults sets the results of a previous scan (see ReadBaselineResults), to print only the issues that were added or resolved since.
+func (rw *ResultsWriter) SetBaselineResults(baseline []services.ScanResponse) *ResultsWriter {
+	rw.baseline = baseline
+	return rw
+}
+
+// SetSuppressedResults sets the issues that were suppressed by local ignore rules (see IgnoreRules.SuppressIssues).
+func (rw *ResultsWriter) SetSuppressedResults(suppressed []SuppressedScanResults) *ResultsWriter {
+	rw.suppressed = suppressed
+	return rw
+}
+
+// SetRemediations sets the upgrades suggested to fix the issues found (see GetRemediations).
+func (rw *ResultsWriter) SetRemediations(remediations []formats.RemediationRow) *ResultsWriter {
+	rw.remediations = remediations
+	return rw
+}
+
+func (rw *ResultsWriter) SetOutputFormat(format OutputFormat) *ResultsWriter {
+	rw.format = format
+	return rw
+}
+
+func (rw *ResultsWriter) SetIncludeVulnerabilities(includeVulnerabilities bool) *ResultsWriter {
+	rw.includeVulnerabilities = includeVulnerabilities
+	return rw
+}
+
+func (rw *ResultsWriter) SetIncludeLicenses(includeLicenses bool) *ResultsWriter {
+	rw.includeLicenses = includeLicenses
+	return rw
+}
+
+func (rw *ResultsWriter) SetIsMultipleRootProject(isMultipleRoots bool) *ResultsWriter {
+	rw.isMultipleRoots = isMultipleRoots
+	return rw
+}
+
+func (rw *ResultsWriter) SetPrintExtendedTable(printExtended bool) *ResultsWriter {
+	rw.printExtended = printExtended
+	return rw
+}
 
 // PrintScanResults prints Xray scan results in the given format.
 // Note that errors are printed only on SimpleJson format.
 func PrintScanResults(results []services.ScanResponse, errors []formats.SimpleJsonError, format OutputFormat, includeVulnerabilities, includeLicenses, isMultipleRoots, printExtended bool) error {
-	switch format {
-	case Table:
-		var err error
-		violations, vulnerabilities, licenses := splitScanResults(results)
+	return NewResultsWriter(results).
+		SetErrors(errors).
+		SetOutputFormat(format).
+		SetIncludeVulnerabilities(includeVulnerabilities).
+		SetIncludeLicenses(includeLicenses).
+		SetIsMultipleRootProject(isMultipleRoots).
+		SetPrintExtendedTable(printExtended).
+		PrintScanResults()
+}
 
-		if len(results) > 0 {
-			resultsPath, err := writeJsonResults(results)
+// PrintScanResults prints the writer's scan results in its output format.
+// In baseline mode, the SBOM formats list all the scanned components and licenses, but only the new vulnerabilities and violations.
+// Suppressed issues are listed separately in all formats, and are never considered as resolved compared to the baseline.
+func (rw *ResultsWriter) PrintScanResults() error {
+	newResults, newResultsPaths, resolvedResults := rw.results, rw.scannedPaths, []services.ScanResponse(nil)
+	if rw.isBaselineMode() {
+		newResults, newResultsPaths = subtractScanResultsWithPaths(rw.results, rw.scannedPaths, rw.baseline)
+		resolvedResults = subtractScanResults(rw.baseline, rw.getAllResults())
+	}
+	switch rw.format {
+	case Table:
+		return rw.printTables(newResults, newResultsPaths, resolvedResults)
+	case SimpleJson:
+		jsonTable, err := convertScanToSimpleJson(newResults, newResultsPaths, rw.errors, rw.includeVulnerabilities, rw.isMultipleRoots, rw.includeLicenses)
+		if err != nil {
+			return err
+		}
+		if jsonTable.SuppressedIssues, err = PrepareSuppressedIssues(rw.suppressed, rw.includeVulnerabilities, rw.includeLicenses, rw.isMultipleRoots); err != nil {
+			return err
+		}
+		jsonTable.Remediations = rw.remediations
+		if rw.isBaselineMode() {
+			resolvedJsonTable, err := convertScanToSimpleJson(resolvedResults, nil, nil, rw.includeVulnerabilities, rw.isMultipleRoots, rw.includeLicenses)
+			if err != nil {
+				return err
+			}
+			jsonTable.Resolved = &resolvedJsonTable
+		}
+		return printJson(jsonTable)
+	case Json:
+		// The output remains an array of the results, so the suppressed and the resolved issues are written to separate files
+		if len(rw.suppressed) > 0 {
+			suppressedPath, err := writeJsonResults(rw.suppressed)
+			if err != nil {
+				return err
+			}
+			log.Info("Issues that were suppressed by the ignore rules aren't included in the results. They are available here: " + suppressedPath)
+		}
+		if !rw.isBaselineMode() {
+			return printJson(rw.results)
+		}
+		if len(resolvedResults) > 0 {
+			resolvedPath, err := writeJsonResults(resolvedResults)
 			if err != nil {
 				return err
 			}
-			log.Output("The full scan results are available here: " + resultsPath)
+			log.Info("Issues that were resolved compared to the baseline are available here: " + resolvedPath)
 		}
-		if includeVulnerabilities {
-			err = PrintVulnerabilitiesTable(vulnerabilities, isMultipleRoots, printExtended)
-		} else {
-			err = PrintViolationsTable(violations, isMultipleRoots, printExtended)
+		if newResults == nil {
+			newResults = []services.ScanResponse{}
 		}
+		return printJson(newResults)
+	case Sarif:
+		sarifFile, err := generateSarifFileFromScan(newResults, newResultsPaths, rw.suppressed, rw.includeVulnerabilities, rw.isMultipleRoots)
 		if err != nil {
 			return err
 		}
-		if includeLicenses {
-			err = PrintLicensesTable(licenses, isMultipleRoots, printExtended)
+		log.Output(sarifFile)
+	case Junit:
+		report, err := generateJunitReport(newResults, newResultsPaths, rw.suppressed, rw.includeVulnerabilities, rw.isMultipleRoots)
+		if err != nil {
+			return err
 		}
-		return err
-	case SimpleJson:
-		jsonTable, err := convertScanToSimpleJson(results, errors, includeVulnerabilities, isMultipleRoots, includeLicenses)
+		log.Output(report)
+	case CycloneDx:
+		bom, err := GenerateCycloneDxBomFromScan(rw.getSbomResults(newResults), rw.suppressed, rw.dependencyTrees)
 		if err != nil {
 			return err
 		}
-		return printJson(jsonTable)
-	case Json:
-		return printJson(results)
-	case Sarif:
-		sarifFile, err := GenerateSarifFileFromScan(results, includeVulnerabilities, isMultipleRoots)
+		log.Output(bom)
+	case Spdx, SpdxTagValue:
+		document, err := GenerateSpdxDocumentFromScan(rw.getSbomResults(newResults), rw.suppressed, rw.dependencyTrees, rw.format == SpdxTagValue)
 		if err != nil {
 			return err
 		}
-		log.Output(sarifFile)
+		log.Output(document)
+	}
+	return nil
+}
+
+func (rw *ResultsWriter) isBaselineMode() bool {
+	return rw.baseline != nil
+}
+
+func (rw *ResultsWriter) printTables(newResults []services.ScanResponse, newResultsPaths []string, resolvedResults []services.ScanResponse) error {
+	if allResults := rw.getAllResults(); len(allResults) > 0 {
+		// The full results (including the suppressed issues) are written also in baseline mode, so they can be used as the baseline of future scans.
+		resultsPath, err := writeJsonResults(allResults)
+		if err != nil {
+			return err
+		}
+		log.Output("The full scan results are available here: " + resultsPath)
+	}
+	if !rw.isBaselineMode() {
+		if rw.includeVulnerabilities {
+			log.Output(noContextMessage + "Below are all vulnerabilities detected.")
+		}
+		if err := rw.printTablesWithTitlePrefix(rw.results, rw.scannedPaths, ""); err != nil {
+			return err
+		}
+	} else {
+		if rw.includeVulnerabilities {
+			log.Output(noContextMessage + "Below are the vulnerabilities that were added or resolved compared to the baseline.")
+		}
+		if err := rw.printTablesWithTitlePrefix(newResults, newResultsPaths, "New "); err != nil {
+			return err
+		}
+		if err := rw.printTablesWithTitlePrefix(resolvedResults, nil, "Resolved "); err != nil {
+			return err
+		}
+	}
+	if len(rw.suppressed) > 0 {
+		if err := PrintSuppressedIssuesTable(rw.suppressed, rw.includeVulnerabilities, rw.includeLicenses, rw.isMultipleRoots, rw.printExtended); err != nil {
+			return err
+		}
+	}
+	if rw.remediations != nil {
+		return PrintRemediationsTable(rw.remediations, rw.printExtended)
 	}
 	return nil
 }
 
+// Returns the results along with the suppressed issues.
+func (rw *ResultsWriter) getAllResults() []services.ScanResponse {
+	if len(rw.suppressed) == 0 {
+		return rw.results
+	}
+	return append(append([]services.ScanResponse{}, rw.results...), flattenSuppressedResults(rw.suppressed)...)
+}
+
+func (rw *ResultsWriter) printTablesWithTitlePrefix(results []services.ScanResponse, scannedPaths []string, titlePrefix string) error {
+	issues := splitScanResultsWithPaths(results, scannedPaths)
+	var err error
+	if rw.includeVulnerabilities {
+		err = printVulnerabilitiesTable(issues.vulnerabilities, issues.vulnerabilitiesPaths, rw.isMultipleRoots, rw.printExtended, titlePrefix)
+		if err == nil {
+			err = printLicensePolicyViolationsTable(issues.violations, issues.violationsPaths, rw.isMultipleRoots, rw.printExtended, titlePrefix)
+		}
+	} else {
+		err = printViolationsTable(issues.violations, issues.violationsPaths, rw.isMultipleRoots, rw.printExtended, titlePrefix)
+	}
+	if err != nil {
+		return err
+	}
+	if rw.includeLicenses {
+		err = printLicensesTable(issues.licenses, issues.licensesPaths, rw.isMultipleRoots, rw.printExtended, titlePrefix)
+	}
+	return err
+}
+
+// SBOM documents describe the full inventory of the scanned components, so the licenses of all the components are kept in baseline mode.
+func (rw *ResultsWriter) getSbomResults(newResults []services.ScanResponse) []services.ScanResponse {
+	if !rw.isBaselineMode() {
+		return rw.results
+	}
+	_, _, licenses := splitScanResults(rw.results)
+	sbomResults := []services.ScanResponse{{Licenses: licenses}}
+	for _, result := range newResults {
+		result.Licenses = nil
+		sbomResults = append(sbomResults, result)
+	}
+	return sbomResults
+}
+
 func GenerateSarifFileFromScan(currentScan []services.ScanResponse, includeVulnerabilities, isMultipleRoots bool) (string, error) {
+	return generateSarifFileFromScan(currentScan, nil, nil, includeVulnerabilities, isMultipleRoots)
+}
+
+// The location of each result is the declaration of the issue's direct dependency in the descriptor of the scanned project (or of one of its nested modules),
+// according to scannedPaths, which holds the path of the descriptor each of the scan results was created from (by index).
+// Suppressed issues are added to the SARIF run as results with an accepted external suppression, that holds the ignore rule's justification.
+func generateSarifFileFromScan(currentScan []services.ScanResponse, scannedPaths []string, suppressed []SuppressedScanResults, includeVulnerabilities, isMultipleRoots bool) (string, error) {
 	report, err := sarif.New(sarif.Version210)
 	if err != nil {
 		return "", errorutils.CheckError(err)
 	}
 	run := sarif.NewRunWithInformationURI("JFrog Xray", "https://jfrog.com/xray/")
-	err = convertScanToSarif(run, currentScan, includeVulnerabilities, isMultipleRoots)
+	locationResolver := newSarifLocationResolver()
+	err = convertScanToSarif(run, locationResolver, currentScan, scannedPaths, includeVulnerabilities, isMultipleRoots)
 	if err != nil {
 		return "", err
 	}
+	for _, suppressedResults := range suppressed {
+		firstSuppressedResult := len(run.Results)
+		if err = convertScanToSarif(run, locationResolver, suppressedResults.Results, suppressedResults.ScannedPaths, includeVulnerabilities, isMultipleRoots); err != nil {
+			return "", err
+		}
+		for _, result := range run.Results[firstSuppressedResult:] {
+			result.AddSuppression(sarif.NewSuppression("external").WithStatus("accepted").WithJustifcation(suppressedResults.Rule.getSuppressionDetail()))
+		}
+	}
 	report.AddRun(run)
 	out, err := json.Marshal(report)
 	if err != nil {
@@ -95,18 +333,21 @@ func GenerateSarifFileFromScan(currentScan []services.ScanResponse, includeVulne
 	return clientUtils.IndentJson(out), nil
 }
 
-func convertScanToSimpleJson(results []services.ScanResponse, errors []formats.SimpleJsonError, includeVulnerabilities, isMultipleRoots, includeLicenses bool) (formats.SimpleJsonResults, error) {
-	violations, vulnerabilities, licenses := splitScanResults(results)
+func convertScanToSimpleJson(results []services.ScanResponse, scannedPaths []string, errors []formats.SimpleJsonError, includeVulnerabilities, isMultipleRoots, includeLicenses bool) (formats.SimpleJsonResults, error) {
+	issues := splitScanResultsWithPaths(results, scannedPaths)
 	jsonTable := formats.SimpleJsonResults{}
 	if includeVulnerabilities {
 		log.Info(noContextMessage + "All vulnerabilities detected will be included in the output JSON.")
-		vulJsonTable, err := PrepareVulnerabilities(vulnerabilities, isMultipleRoots)
+		vulJsonTable, err := prepareVulnerabilities(issues.vulnerabilities, issues.vulnerabilitiesPaths, isMultipleRoots, false)
 		if err != nil {
 			return formats.SimpleJsonResults{}, err
 		}
 		jsonTable.Vulnerabilities = vulJsonTable
+		if jsonTable.LicensesViolations, err = prepareLicensePolicyViolations(issues.violations, issues.violationsPaths, isMultipleRoots, false); err != nil {
+			return formats.SimpleJsonResults{}, err
+		}
 	} else {
-		secViolationsJsonTable, licViolationsJsonTable, opRiskViolationsJsonTable, err := PrepareViolations(violations, isMultipleRoots)
+		secViolationsJsonTable, licViolationsJsonTable, opRiskViolationsJsonTable, err := prepareViolations(issues.violations, issues.violationsPaths, isMultipleRoots, false)
 		if err != nil {
 			return formats.SimpleJsonResults{}, err
 		}
@@ -116,7 +357,7 @@ func convertScanToSimpleJson(results []services.ScanResponse, errors []formats.S
 	}
 
 	if includeLicenses {
-		licJsonTable, err := PrepareLicenses(licenses, isMultipleRoots)
+		licJsonTable, err := prepareLicenses(issues.licenses, issues.licensesPaths, isMultipleRoots, false)
 		if err != nil {
 			return formats.SimpleJsonResults{}, err
 		}
@@ -127,15 +368,14 @@ func convertScanToSimpleJson(results []services.ScanResponse, errors []formats.S
 	return jsonTable, nil
 }
 
-func convertScanToSarif(run *sarif.Run, currentScan []services.ScanResponse, includeVulnerabilities, isMultipleRoots bool) error {
+func convertScanToSarif(run *sarif.Run, locationResolver *sarifLocationResolver, currentScan []services.ScanResponse, scannedPaths []string, includeVulnerabilities, isMultipleRoots bool) error {
 	var errors []formats.SimpleJsonError
-	jsonTable, err := convertScanToSimpleJson(currentScan, errors, includeVulnerabilities, isMultipleRoots, false)
+	jsonTable, err := convertScanToSimpleJson(currentScan, scannedPaths, errors, includeVulnerabilities, isMultipleRoots, false)
 	if err != nil {
 		return err
 	}
 	if len(jsonTable.SecurityViolations) > 0 {
 		violations := jsonTable.SecurityViolations
-		licenses := jsonTable.LicensesViolations
 		for i := 0; i < len(jsonTable.SecurityViolations); i++ {
 			impactedPackageFull := violations[i].ImpactedPackageName + ":" + violations[i].ImpactedPackageVersion
 			if violations[i].FixedVersions != nil {
@@ -145,17 +385,8 @@ func convertScanToSarif(run *sarif.Run, currentScan []services.ScanResponse, inc
 			if err != nil {
 				return err
 			}
-			err = addScanResultsToSarifRun(run, severity, violations[i].IssueId, impactedPackageFull, violations[i].Summary, violations[i].Technology)
-			if err != nil {
-				return err
-			}
-		}
-		for i := 0; i < len(licenses); i++ {
-			impactedPackageFull := licenses[i].ImpactedPackageName + ":" + licenses[i].ImpactedPackageVersion
-			if err != nil {
-				return err
-			}
-			err = addScanResultsToSarifRun(run, "", licenses[i].ImpactedPackageVersion, impactedPackageFull, licenses[i].LicenseKey, coreutils.Technology(strings.ToLower(licenses[i].ImpactedPackageType)))
+			location, fixes := locationResolver.resolve(violations[i].DescriptorPath, violations[i].Technology, violations[i].ImpactedPackageName, violations[i].ImpactedPackageVersion, violations[i].FixedVersions, violations[i].Components)
+			err = addScanResultsToSarifRun(run, severity, violations[i].IssueId, impactedPackageFull, violations[i].Summary, location, fixes)
 			if err != nil {
 				return err
 			}
@@ -174,19 +405,29 @@ func convertScanToSarif(run *sarif.Run, currentScan []services.ScanResponse, inc
 			if err != nil {
 				return err
 			}
-			err = addScanResultsToSarifRun(run, severity, vulnerabilities[i].IssueId, impactedPackageFull, vulnerabilities[i].Summary, vulnerabilities[i].Technology)
+			location, fixes := locationResolver.resolve(vulnerabilities[i].DescriptorPath, vulnerabilities[i].Technology, vulnerabilities[i].ImpactedPackageName, vulnerabilities[i].ImpactedPackageVersion, vulnerabilities[i].FixedVersions, vulnerabilities[i].Components)
+			err = addScanResultsToSarifRun(run, severity, vulnerabilities[i].IssueId, impactedPackageFull, vulnerabilities[i].Summary, location, fixes)
 			if err != nil {
 				return err
 			}
 		}
 	}
+	// License violations are added also without security violations, and along with the vulnerabilities (violations of the local license policy).
+	licenses := jsonTable.LicensesViolations
+	for i := 0; i < len(licenses); i++ {
+		impactedPackageFull := licenses[i].ImpactedPackageName + ":" + licenses[i].ImpactedPackageVersion
+		location, _ := locationResolver.resolve(licenses[i].DescriptorPath, coreutils.Technology(strings.ToLower(licenses[i].ImpactedPackageType)), licenses[i].ImpactedPackageName, licenses[i].ImpactedPackageVersion, nil, licenses[i].Components)
+		err = addScanResultsToSarifRun(run, "", licenses[i].LicenseKey, impactedPackageFull, licenses[i].LicenseKey, location, nil)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 // Adding the Xray scan results details to the sarif struct, for each issue found in the scan
-func addScanResultsToSarifRun(run *sarif.Run, severity string, issueId string, impactedPackage string, description string, technology coreutils.Technology) error {
-	techPackageDescriptor := technology.GetPackageDescriptor()
+func addScanResultsToSarifRun(run *sarif.Run, severity string, issueId string, impactedPackage string, description string, location *sarif.Location, fixes []*sarif.Fix) error {
 	pb := sarif.NewPropertyBag()
 	if severity != missingCveScore {
 		pb.Add("security-severity", severity)
@@ -194,16 +435,12 @@ func addScanResultsToSarifRun(run *sarif.Run, severity string, issueId string, i
 	run.AddRule(issueId).
 		WithProperties(pb.Properties).
 		WithFullDescription(sarif.NewMultiformatMessageString(description))
-	run.CreateResultForRule(issueId).
-		WithMessage(sarif.NewTextMessage(impactedPackage)).
-		AddLocation(
-			sarif.NewLocationWithPhysicalLocation(
-				sarif.NewPhysicalLocation().
-					WithArtifactLocation(
-						sarif.NewSimpleArtifactLocation(techPackageDescriptor),
-					),
-			),
-		)
+	result := run.CreateResultForRule(issueId).
+		WithMessage(sarif.NewTextMessage(impactedPackage))
+	result.AddLocation(location)
+	for _, fix := range fixes {
+		result.AddFix(fix)
+	}
 
 	return nil
 }
@@ -244,7 +481,42 @@ func splitScanResults(results []services.ScanResponse) ([]services.Violation, []
 	return violations, vulnerabilities, licenses
 }
 
-func writeJsonResults(results []services.ScanResponse) (resultsPath string, err error) {
+// Holds the issues of scan results, along with the path of the descriptor or file each of them was found in (by index).
+type scanIssues struct {
+	violations           []services.Violation
+	violationsPaths      []string
+	vulnerabilities      []services.Vulnerability
+	vulnerabilitiesPaths []string
+	licenses             []services.License
+	licensesPaths        []string
+}
+
+// Same as splitScanResults, but keeps the path each of the issues was found in. scannedPaths holds the path of each of the results (by index), and may be nil.
+func splitScanResultsWithPaths(results []services.ScanResponse, scannedPaths []string) (issues scanIssues) {
+	for i, result := range results {
+		scannedPath := ""
+		if i < len(scannedPaths) {
+			scannedPath = scannedPaths[i]
+		}
+		issues.violations = append(issues.violations, result.Violations...)
+		issues.violationsPaths = append(issues.violationsPaths, RepeatString(scannedPath, len(result.Violations))...)
+		issues.vulnerabilities = append(issues.vulnerabilities, result.Vulnerabilities...)
+		issues.vulnerabilitiesPaths = append(issues.vulnerabilitiesPaths, RepeatString(scannedPath, len(result.Vulnerabilities))...)
+		issues.licenses = append(issues.licenses, result.Licenses...)
+		issues.licensesPaths = append(issues.licensesPaths, RepeatString(scannedPath, len(result.Licenses))...)
+	}
+	return
+}
+
+// RepeatString returns a slice that holds str count times.
+func RepeatString(str string, count int) (strs []string) {
+	for i := 0; i < count; i++ {
+		strs = append(strs, str)
+	}
+	return
+}
+
+func writeJsonResults(results interface{}) (resultsPath string, err error) {
 	out, err := fileutils.CreateTempFile()
 	if errorutils.CheckError(err) != nil {
 		return
@@ -255,7 +527,7 @@ func writeJsonResults(results []services.ScanResponse) (resultsPath string, err
 			err = e
 		}
 	}()
-	bytesRes, err := json.Marshal(&results)
+	bytesRes, err := json.Marshal(results)
 	if errorutils.CheckError(err) != nil {
 		return
 	}
@@ -281,10 +553,12 @@ func printJson(output interface{}) error {
 	return nil
 }
 
+// CheckIfFailBuild returns true if one of the violations of Xray policies in the results is set to fail the build.
+// The violations of the local license policy are checked by CheckIfLicensePolicyFailBuild.
 func CheckIfFailBuild(results []services.ScanResponse) bool {
 	for _, result := range results {
 		for _, violation := range result.Violations {
-			if violation.FailBuild {
+			if violation.FailBuild && !isLicensePolicyViolation(violation) {
 				return true
 			}
 		}
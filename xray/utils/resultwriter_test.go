@@ -1,9 +1,16 @@
 package utils
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/tests"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/jfrog/jfrog-client-go/xray/services"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestGenerateSarifFileFromScan(t *testing.T) {
@@ -36,3 +43,63 @@ func TestGenerateSarifFileFromScan(t *testing.T) {
 	expected := "{\n  \"version\": \"2.1.0\",\n  \"$schema\": \"https://json.schemastore.org/sarif-2.1.0-rtm.5.json\",\n  \"runs\": [\n    {\n      \"tool\": {\n        \"driver\": {\n          \"informationUri\": \"https://jfrog.com/xray/\",\n          \"name\": \"JFrog Xray\",\n          \"rules\": [\n            {\n              \"id\": \"XRAY-1\",\n              \"shortDescription\": null,\n              \"fullDescription\": {\n                \"text\": \"summary-1. Fixed in Versions: [2.1.3]\"\n              },\n              \"properties\": {\n                \"security-severity\": \"9.0\"\n              }\n            }\n          ]\n        }\n      },\n      \"results\": [\n        {\n          \"ruleId\": \"XRAY-1\",\n          \"ruleIndex\": 0,\n          \"message\": {\n            \"text\": \"component-G:\"\n          },\n          \"locations\": [\n            {\n              \"physicalLocation\": {\n                \"artifactLocation\": {\n                  \"uri\": \"go.mod\"\n                }\n              }\n            }\n          ]\n        }\n      ]\n    }\n  ]\n}"
 	assert.Equal(t, expected, sarif)
 }
+
+func TestPrintJsonResultsWithSuppressedIssues(t *testing.T) {
+	outputBuffer, stderrBuffer, previousLog := tests.RedirectLogOutputToBuffer()
+	defer log.SetLogger(previousLog)
+	results := []services.ScanResponse{{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-1", Components: map[string]services.Component{"npm://debug:2.6.8": {}}}}}}
+	suppressed := []SuppressedScanResults{{
+		Rule:    IgnoreRule{Justification: "Not exploitable"},
+		Results: []services.ScanResponse{{Vulnerabilities: []services.Vulnerability{{IssueId: "XRAY-2", Components: map[string]services.Component{"npm://lodash:4.17.20": {}}}}}},
+	}}
+	assert.NoError(t, NewResultsWriter(results).SetSuppressedResults(suppressed).SetOutputFormat(Json).SetIncludeVulnerabilities(true).PrintScanResults())
+
+	// The output remains an array of the results, and the suppressed issues are written to a separate file
+	var printedResults []services.ScanResponse
+	assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &printedResults))
+	if assert.Len(t, printedResults, 1) {
+		assert.Equal(t, "XRAY-1", printedResults[0].Vulnerabilities[0].IssueId)
+	}
+	_, suppressedPath, found := strings.Cut(strings.TrimSpace(stderrBuffer.String()), "They are available here: ")
+	if assert.True(t, found) {
+		content, err := os.ReadFile(suppressedPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "XRAY-2")
+		assert.Contains(t, string(content), "Not exploitable")
+	}
+}
+
+func TestPrintJsonResultsInBaselineMode(t *testing.T) {
+	outputBuffer, stderrBuffer, previousLog := tests.RedirectLogOutputToBuffer()
+	defer log.SetLogger(previousLog)
+	baseline := []services.ScanResponse{{Vulnerabilities: []services.Vulnerability{
+		{IssueId: "XRAY-1", Components: map[string]services.Component{"npm://debug:2.6.8": {}}},
+		{IssueId: "XRAY-2", Components: map[string]services.Component{"npm://lodash:4.17.20": {}}},
+	}}}
+	results := []services.ScanResponse{{Vulnerabilities: []services.Vulnerability{
+		{IssueId: "XRAY-1", Components: map[string]services.Component{"npm://debug:2.6.8": {}}},
+		{IssueId: "XRAY-3", Components: map[string]services.Component{"npm://minimist:1.2.5": {}}},
+	}}}
+	assert.NoError(t, NewResultsWriter(results).SetBaselineResults(baseline).SetOutputFormat(Json).SetIncludeVulnerabilities(true).PrintScanResults())
+
+	// The output is an array of the new issues, which can be used as a baseline, and the resolved issues are written to a separate file
+	baselineFile := filepath.Join(t.TempDir(), "results.json")
+	assert.NoError(t, os.WriteFile(baselineFile, outputBuffer.Bytes(), 0644))
+	printedResults, err := ReadBaselineResults(baselineFile)
+	assert.NoError(t, err)
+	if assert.Len(t, printedResults, 1) && assert.Len(t, printedResults[0].Vulnerabilities, 1) {
+		assert.Equal(t, "XRAY-3", printedResults[0].Vulnerabilities[0].IssueId)
+	}
+	_, resolvedPath, found := strings.Cut(strings.TrimSpace(stderrBuffer.String()), "compared to the baseline are available here: ")
+	if assert.True(t, found) {
+		content, err := os.ReadFile(resolvedPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "XRAY-2")
+		assert.NotContains(t, string(content), "XRAY-3")
+	}
+
+	// Without new issues, the output is an empty array
+	outputBuffer.Reset()
+	assert.NoError(t, NewResultsWriter(baseline[:0]).SetBaselineResults(baseline).SetOutputFormat(Json).SetIncludeVulnerabilities(true).PrintScanResults())
+	assert.Equal(t, "[]", strings.TrimSpace(outputBuffer.String()))
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// Resolves the locations of the issues' direct dependencies in the descriptors of the scanned projects, for the SARIF format.
+type sarifLocationResolver struct {
+	wd string
+	// The content of the descriptors read so far, by path.
+	descriptorsContent map[string]string
+	// The descriptor of each project along with the descriptors of its nested modules, by the path of the project's descriptor.
+	modulesDescriptors map[string][]string
+}
+
+func newSarifLocationResolver() *sarifLocationResolver {
+	wd, _ := os.Getwd()
+	return &sarifLocationResolver{wd: wd, descriptorsContent: make(map[string]string), modulesDescriptors: make(map[string][]string)}
+}
+
+// Returns the location of the declaration of the first direct dependency of the issue that can be found in the project's descriptor or in the descriptors of its nested modules,
+// and the fixes upgrading the dependency to each of the fixed versions (if the direct dependency is the impacted package itself).
+// descriptorPath is the descriptor of the scanned project, or its directory. If it's empty, the project in the working directory is assumed.
+// If the descriptor can't be found, the location is the technology's descriptor name, with no region.
+func (slr *sarifLocationResolver) resolve(descriptorPath string, technology coreutils.Technology, impactedPackageName, impactedPackageVersion string, fixedVersions []string, directComponents []formats.ComponentRow) (*sarif.Location, []*sarif.Fix) {
+	projectDescriptor := slr.getProjectDescriptor(descriptorPath, technology)
+	if projectDescriptor == "" {
+		return newSarifLocation(technology.GetPackageDescriptor(), nil), nil
+	}
+	for _, component := range directComponents {
+		for _, moduleDescriptor := range slr.getModulesDescriptors(projectDescriptor) {
+			locate := getDependencyLocator(moduleDescriptor)
+			if locate == nil {
+				continue
+			}
+			content := slr.getContent(moduleDescriptor)
+			declarations := locate(content, component.Name)
+			if len(declarations) == 0 {
+				continue
+			}
+			declaration := declarations[0]
+			uri := slr.toSarifUri(moduleDescriptor)
+			line, column := getLineAndColumn(content, declaration.nameOffset)
+			location := newSarifLocation(uri, sarif.NewRegion().WithStartLine(line).WithStartColumn(column))
+			var fixes []*sarif.Fix
+			if declaration.hasVersion() && component.Name == impactedPackageName && component.Version == impactedPackageVersion {
+				deletedRegion := getSarifRegion(content, declaration.versionStart, declaration.versionEnd)
+				for _, fixedVersion := range parseFixedVersions(fixedVersions) {
+					fixes = append(fixes, sarif.NewFix().
+						WithDescriptionText(fmt.Sprintf("Upgrade %s to version %s", component.Name, fixedVersion)).
+						WithArtifactChanges([]*sarif.ArtifactChange{sarif.NewArtifactChange(sarif.NewSimpleArtifactLocation(uri)).
+							WithReplacement(sarif.NewReplacement(deletedRegion).
+								WithInsertedContent(sarif.NewArtifactContent().WithText(formatDescriptorVersion(moduleDescriptor, fixedVersion))))}))
+				}
+			}
+			return location, fixes
+		}
+	}
+	return newSarifLocation(slr.toSarifUri(projectDescriptor), nil), nil
+}
+
+// Returns the path of the project's descriptor file, or an empty string if it can't be found.
+func (slr *sarifLocationResolver) getProjectDescriptor(descriptorPath string, technology coreutils.Technology) string {
+	if descriptorPath == "" {
+		descriptorPath = slr.wd
+	}
+	fileInfo, err := os.Stat(descriptorPath)
+	if err != nil {
+		return ""
+	}
+	if fileInfo.IsDir() {
+		descriptorPath = coreutils.GetDescriptorPath(descriptorPath, technology)
+		if fileInfo, err = os.Stat(descriptorPath); err != nil || fileInfo.IsDir() {
+			return ""
+		}
+	}
+	return descriptorPath
+}
+
+// Returns the project's descriptor, followed by the descriptors of the same type in its subdirectories (like the pom.xml files of the modules of a Maven project).
+func (slr *sarifLocationResolver) getModulesDescriptors(projectDescriptor string) []string {
+	if descriptors, exist := slr.modulesDescriptors[projectDescriptor]; exist {
+		return descriptors
+	}
+	descriptors := []string{projectDescriptor}
+	descriptorType := getDescriptorType(projectDescriptor)
+	projectDir := filepath.Dir(projectDescriptor)
+	// Errors are ignored, since the nested modules are optional
+	_ = filepath.WalkDir(projectDir, func(currentPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if currentPath != projectDir && isDefaultExcludedDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if currentPath != projectDescriptor && getDescriptorType(currentPath) == descriptorType {
+			descriptors = append(descriptors, currentPath)
+		}
+		return nil
+	})
+	slr.modulesDescriptors[projectDescriptor] = descriptors
+	return descriptors
+}
+
+func (slr *sarifLocationResolver) getContent(descriptorPath string) string {
+	content, exist := slr.descriptorsContent[descriptorPath]
+	if !exist {
+		// Unreadable descriptors are considered empty
+		contentBytes, _ := os.ReadFile(descriptorPath)
+		content = string(contentBytes)
+		slr.descriptorsContent[descriptorPath] = content
+	}
+	return content
+}
+
+// Paths under the working directory are relative to it, so code scanning tools can match them with the files in the repository.
+func (slr *sarifLocationResolver) toSarifUri(path string) string {
+	if relativePath, err := filepath.Rel(slr.wd, path); err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(relativePath)
+	}
+	return "file://" + filepath.ToSlash(path)
+}
+
+// Descriptors of the same type have the same name, except for project files like .csproj, which are identified by their extension.
+func getDescriptorType(descriptorPath string) string {
+	fileName := filepath.Base(descriptorPath)
+	for _, suffix := range []string{".csproj", ".gradle.kts", ".gradle"} {
+		if strings.HasSuffix(fileName, suffix) {
+			return suffix
+		}
+	}
+	return fileName
+}
+
+func isDefaultExcludedDir(dirName string) bool {
+	for _, excludedDir := range coreutils.DefaultProjectsExcludePatterns {
+		if dirName == excludedDir {
+			return true
+		}
+	}
+	return false
+}
+
+func newSarifLocation(uri string, region *sarif.Region) *sarif.Location {
+	physicalLocation := sarif.NewPhysicalLocation().WithArtifactLocation(sarif.NewSimpleArtifactLocation(uri))
+	if region != nil {
+		physicalLocation.WithRegion(region)
+	}
+	return sarif.NewLocationWithPhysicalLocation(physicalLocation)
+}
+
+// Converts byte offsets in the content to a region with 1-based lines and columns. The end column is exclusive.
+func getSarifRegion(content string, startOffset, endOffset int) *sarif.Region {
+	startLine, startColumn := getLineAndColumn(content, startOffset)
+	endLine, endColumn := getLineAndColumn(content, endOffset)
+	return sarif.NewRegion().WithStartLine(startLine).WithStartColumn(startColumn).WithEndLine(endLine).WithEndColumn(endColumn)
+}
+
+func getLineAndColumn(content string, offset int) (line, column int) {
+	lineStart := strings.LastIndex(content[:offset], "\n") + 1
+	return strings.Count(content[:offset], "\n") + 1, utf8.RuneCountInString(content[lineStart:offset]) + 1
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-core/v2/xray/formats"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	parentPomContent = "<project>\n  <modules>\n    <module>app</module>\n  </modules>\n</project>\n"
+	modulePomContent = "<project>\n  <properties>\n    <jackson.version>2.9.8</jackson.version>\n  </properties>\n  <dependencies>\n" +
+		"    <dependency>\n      <groupId>com.fasterxml.jackson.core</groupId>\n      <artifactId>jackson-databind</artifactId>\n      <version>${jackson.version}</version>\n    </dependency>\n" +
+		"  </dependencies>\n</project>\n"
+)
+
+func TestResolveSarifLocation(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(projectDir, "app"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "pom.xml"), []byte(parentPomContent), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "app", "pom.xml"), []byte(modulePomContent), 0644))
+	resolver := &sarifLocationResolver{wd: projectDir, descriptorsContent: make(map[string]string), modulesDescriptors: make(map[string][]string)}
+
+	// The dependency is declared in a nested module
+	components := []formats.ComponentRow{{Name: "com.fasterxml.jackson.core:jackson-databind", Version: "2.9.8"}}
+	location, fixes := resolver.resolve(filepath.Join(projectDir, "pom.xml"), coreutils.Maven, "com.fasterxml.jackson.core:jackson-databind", "2.9.8", []string{"[2.9.10]"}, components)
+	assert.Equal(t, "app/pom.xml", *location.PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 8, *location.PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 7, *location.PhysicalLocation.Region.StartColumn)
+	if assert.Len(t, fixes, 1) {
+		replacement := fixes[0].ArtifactChanges[0].Replacements[0]
+		assert.Equal(t, "2.9.10", *replacement.InsertedContent.Text)
+		assert.Equal(t, 3, *replacement.DeletedRegion.StartLine)
+		assert.Equal(t, 22, *replacement.DeletedRegion.StartColumn)
+		assert.Equal(t, 27, *replacement.DeletedRegion.EndColumn)
+	}
+
+	// Transitive dependencies have no fixes
+	location, fixes = resolver.resolve(projectDir, coreutils.Maven, "com.fasterxml.jackson.core:jackson-core", "2.9.8", []string{"[2.9.10]"}, components)
+	assert.Equal(t, "app/pom.xml", *location.PhysicalLocation.ArtifactLocation.URI)
+	assert.Empty(t, fixes)
+
+	// Dependencies that aren't declared are located in the project's descriptor
+	location, _ = resolver.resolve(projectDir, coreutils.Maven, "org.lib:lib", "1.0.0", nil, []formats.ComponentRow{{Name: "org.lib:lib", Version: "1.0.0"}})
+	assert.Equal(t, "pom.xml", *location.PhysicalLocation.ArtifactLocation.URI)
+	assert.Nil(t, location.PhysicalLocation.Region)
+
+	// Projects without a descriptor
+	location, _ = resolver.resolve(filepath.Join(projectDir, "app", "src"), coreutils.Go, "github.com/lib/lib", "v1.0.0", nil, nil)
+	assert.Equal(t, "go.mod", *location.PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestDependencyLocators(t *testing.T) {
+	testCases := []struct {
+		fileName        string
+		content         string
+		dependencyName  string
+		expectedName    string
+		expectedVersion string
+	}{
+		{"package.json", `{"dependencies": {"lodash": "~4.17.15"}}`, "lodash", "lodash", "4.17.15"},
+		{"package.json", `{"dependencies": {"lodash": "github:lodash/lodash"}}`, "lodash", "lodash", ""},
+		{"go.mod", "module app\n\nrequire github.com/gin-gonic/gin v1.7.0\n", "github.com/gin-gonic/gin", "github.com/gin-gonic/gin", "v1.7.0"},
+		{"requirements.txt", "pyyaml\n", "PyYAML", "pyyaml", ""},
+		{"setup.py", "install_requires=['requests[socks]==2.25.0']", "requests", "requests", "2.25.0"},
+		{"pyproject.toml", "[tool.poetry.dependencies]\nrequests = \"^2.25.0\"\n", "requests", "requests", "2.25.0"},
+		{"build.gradle", "implementation 'org.lib:lib:1.0.0'", "org.lib:lib", "org.lib:lib", "1.0.0"},
+		{"build.gradle.kts", "implementation(group = \"org.lib\", name = \"lib\", version = \"1.0.0\")", "org.lib:lib", "group = \"org.lib\", name = \"lib\"", "1.0.0"},
+		{"app.csproj", `<PackageReference Include="Newtonsoft.Json" Version="12.0.1" />`, "newtonsoft.json", "Newtonsoft.Json", "12.0.1"},
+		{"packages.config", `<package id="Newtonsoft.Json" version="12.0.1" />`, "Newtonsoft.Json", "Newtonsoft.Json", "12.0.1"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.fileName, func(t *testing.T) {
+			declarations := getDependencyLocator(testCase.fileName)(testCase.content, testCase.dependencyName)
+			if assert.Len(t, declarations, 1) {
+				declaration := declarations[0]
+				assert.Equal(t, testCase.expectedName, testCase.content[declaration.nameOffset:declaration.nameOffset+len(testCase.expectedName)])
+				if testCase.expectedVersion == "" {
+					assert.False(t, declaration.hasVersion())
+				} else {
+					assert.Equal(t, testCase.expectedVersion, testCase.content[declaration.versionStart:declaration.versionEnd])
+				}
+			}
+		})
+	}
+	assert.Nil(t, getDependencyLocator("Dockerfile"))
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const maxCvssScore = 10.0
+
+// SeverityThresholds decides whether to fail the build according to the severity and the CVSS score of the vulnerabilities and security violations found.
+// Unlike CheckIfFailBuild, it doesn't depend on Xray policies and watches.
+// An issue exceeds the thresholds if its severity is at least MinSeverity, or if the highest CVSS score of its CVEs is at least MinCvssScore.
+type SeverityThresholds struct {
+	// One of: Low, Medium, High or Critical. Empty means no severity threshold.
+	MinSeverity string
+	// A CVSS v3 score between 0.1 and 10 (CVSS v2 scores are used for CVEs without a v3 score). Zero means no CVSS threshold.
+	MinCvssScore float64
+	// If true, only issues that have a fixed version for the impacted component are counted.
+	FixableOnly bool
+}
+
+// IsSet returns true if at least one threshold is set.
+func (st *SeverityThresholds) IsSet() bool {
+	return st != nil && (st.MinSeverity != "" || st.MinCvssScore > 0)
+}
+
+func (st *SeverityThresholds) Validate() error {
+	if st == nil {
+		return nil
+	}
+	if st.MinSeverity != "" {
+		if _, exist := severities[toSeverityTitle(st.MinSeverity)]; !exist {
+			return errorutils.CheckErrorf("invalid minimum severity '%s'. Possible values are: Low, Medium, High or Critical", st.MinSeverity)
+		}
+	}
+	if st.MinCvssScore < 0 || st.MinCvssScore > maxCvssScore {
+		return errorutils.CheckErrorf("invalid minimum CVSS score %v. The score should be between 0 and 10", st.MinCvssScore)
+	}
+	if st.FixableOnly && !st.IsSet() {
+		return errorutils.CheckErrorf("the fixable-only option requires a minimum severity or a minimum CVSS score")
+	}
+	return nil
+}
+
+func (st *SeverityThresholds) String() string {
+	var thresholds []string
+	if st.MinSeverity != "" {
+		thresholds = append(thresholds, "minimum severity "+toSeverityTitle(st.MinSeverity))
+	}
+	if st.MinCvssScore > 0 {
+		thresholds = append(thresholds, fmt.Sprintf("minimum CVSS score %v", st.MinCvssScore))
+	}
+	description := strings.Join(thresholds, " or ")
+	if st.FixableOnly {
+		description += ", fixable issues only"
+	}
+	return description
+}
+
+// CheckIfExceedThresholds returns true if one of the vulnerabilities or security violations in the results exceeds the thresholds.
+// If no threshold is set, false is returned.
+func CheckIfExceedThresholds(results []services.ScanResponse, thresholds *SeverityThresholds) bool {
+	if !thresholds.IsSet() {
+		return false
+	}
+	for _, result := range results {
+		if exceeding, _ := splitScanResponse(result, thresholds); !IsEmptyScanResponse([]services.ScanResponse{exceeding}) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewThresholdsFailBuildError(thresholds *SeverityThresholds) error {
+	return coreutils.CliError{ExitCode: coreutils.ExitCodeVulnerableBuild, ErrorMsg: "One or more of the issues found exceed the configured thresholds: " + thresholds.String()}
+}
+
+func (st *SeverityThresholds) matchViolation(violation services.Violation, componentId string) bool {
+	return violation.ViolationType == "security" && st.exceeds(violation.Severity, violation.Cves, violation.Components[componentId])
+}
+
+func (st *SeverityThresholds) matchVulnerability(vulnerability services.Vulnerability, componentId string) bool {
+	return st.exceeds(vulnerability.Severity, vulnerability.Cves, vulnerability.Components[componentId])
+}
+
+func (st *SeverityThresholds) matchLicense(services.License, string) bool {
+	return false
+}
+
+func (st *SeverityThresholds) exceeds(severityTitle string, cves []services.Cve, component services.Component) bool {
+	if st.FixableOnly && len(component.FixedVersions) == 0 {
+		return false
+	}
+	if st.MinSeverity != "" && getSeverity(severityTitle).numValue >= getSeverity(toSeverityTitle(st.MinSeverity)).numValue {
+		return true
+	}
+	return st.MinCvssScore > 0 && getMaxCvssScore(cves) >= st.MinCvssScore
+}
+
+// Returns the highest CVSS score of the CVEs. The CVSS v2 score is used for CVEs without a CVSS v3 score.
+func getMaxCvssScore(cves []services.Cve) (maxScore float64) {
+	for _, cve := range cves {
+		scoreStr := cve.CvssV3Score
+		if scoreStr == "" {
+			scoreStr = cve.CvssV2Score
+		}
+		if score, err := strconv.ParseFloat(scoreStr, 64); err == nil && score > maxScore {
+			maxScore = score
+		}
+	}
+	return
+}
+
+// Converts a severity name in any case to its title, like 'high' to 'High'.
+func toSeverityTitle(severityName string) string {
+	if severityName == "" {
+		return ""
+	}
+	return strings.ToUpper(severityName[:1]) + strings.ToLower(severityName[1:])
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIfExceedThresholds(t *testing.T) {
+	results := []services.ScanResponse{{
+		Vulnerabilities: []services.Vulnerability{
+			{IssueId: "XRAY-1", Severity: "High", Cves: []services.Cve{{Id: "CVE-2022-0001", CvssV3Score: "7.5"}}, Components: map[string]services.Component{"npm://debug:2.6.9": {}}},
+			{IssueId: "XRAY-2", Severity: "Medium", Cves: []services.Cve{{Id: "CVE-2022-0002", CvssV2Score: "5.0"}}, Components: map[string]services.Component{"npm://lodash:4.17.20": {FixedVersions: []string{"[4.17.21]"}}}},
+		},
+		Violations: []services.Violation{
+			{IssueId: "XRAY-3", ViolationType: "license", Severity: "Critical", Components: map[string]services.Component{"npm://gpl-lib:1.0.0": {}}},
+		},
+	}}
+
+	testCases := []struct {
+		name       string
+		thresholds *SeverityThresholds
+		expected   bool
+	}{
+		{name: "no thresholds", thresholds: nil, expected: false},
+		{name: "severity reached", thresholds: &SeverityThresholds{MinSeverity: "high"}, expected: true},
+		{name: "severity not reached", thresholds: &SeverityThresholds{MinSeverity: "Critical"}, expected: false},
+		{name: "cvss reached", thresholds: &SeverityThresholds{MinCvssScore: 7}, expected: true},
+		{name: "cvss not reached", thresholds: &SeverityThresholds{MinCvssScore: 8}, expected: false},
+		{name: "fixable only", thresholds: &SeverityThresholds{MinSeverity: "High", FixableOnly: true}, expected: false},
+		{name: "fixable only v2 score", thresholds: &SeverityThresholds{MinCvssScore: 5, FixableOnly: true}, expected: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.NoError(t, testCase.thresholds.Validate())
+			assert.Equal(t, testCase.expected, CheckIfExceedThresholds(results, testCase.thresholds))
+		})
+	}
+}
+
+func TestValidateSeverityThresholds(t *testing.T) {
+	assert.Error(t, (&SeverityThresholds{MinSeverity: "Severe"}).Validate())
+	assert.Error(t, (&SeverityThresholds{MinCvssScore: 11}).Validate())
+	assert.Error(t, (&SeverityThresholds{FixableOnly: true}).Validate())
+	assert.Equal(t, "minimum severity High or minimum CVSS score 7.5, fixable issues only", (&SeverityThresholds{MinSeverity: "HIGH", MinCvssScore: 7.5, FixableOnly: true}).String())
+}
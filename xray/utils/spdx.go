@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	clientUtils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/xray/services"
+)
+
+const (
+	spdxVersion             = "SPDX-2.3"
+	spdxDataLicense         = "CC0-1.0"
+	spdxDocumentId          = "SPDXRef-DOCUMENT"
+	spdxPackageIdPrefix     = "SPDXRef-Package-"
+	spdxNamespacePrefix     = "https://jfrog.com/spdx/"
+	spdxNoAssertion         = "NOASSERTION"
+	spdxDescribes           = "DESCRIBES"
+	spdxDependsOn           = "DEPENDS_ON"
+	spdxPackageManager      = "PACKAGE-MANAGER"
+	spdxPurlReferenceType   = "purl"
+	spdxDefaultDocumentName = "jfrog-xray-scan"
+	xrayUnknownLicense      = "Unknown"
+	spdxReviewAnnotation    = "REVIEW"
+)
+
+var spdxInvalidIdChars = regexp.MustCompile(`[^a-zA-Z0-9.\-]+`)
+var spdxInvalidLicenseChars = regexp.MustCompile(`[^a-zA-Z0-9.\-+]+`)
+
+type SpdxDocument struct {
+	SpdxVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SpdxId            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SpdxCreationInfo   `json:"creationInfo"`
+	Packages          []SpdxPackage      `json:"packages"`
+	Relationships     []SpdxRelationship `json:"relationships"`
+}
+
+type SpdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SpdxPackage struct {
+	Name             string            `json:"name"`
+	SpdxId           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	ExternalRefs     []SpdxExternalRef `json:"externalRefs,omitempty"`
+	Annotations      []SpdxAnnotation  `json:"annotations,omitempty"`
+}
+
+type SpdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type SpdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SpdxRelationship struct {
+	SpdxElementId      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
+
+// GenerateSpdxDocumentFromScan creates an SPDX 2.3 document from the dependency trees calculated by the audit builders (or by the indexer).
+// The document lists the packages of the trees, the dependency relationships between them and the licenses Xray returned for them.
+// Issues that were suppressed by local ignore rules are listed as review annotations of the impacted packages.
+// Set tagValue to true to generate the document in the tag-value format. Otherwise, the document is generated in the JSON format.
+func GenerateSpdxDocumentFromScan(results []services.ScanResponse, suppressed []SuppressedScanResults, dependencyTrees []*services.GraphNode, tagValue bool) (string, error) {
+	document := createSpdxDocument(results, suppressed, dependencyTrees)
+	document.DocumentNamespace = spdxNamespacePrefix + document.Name + "-" + uuid.New().String()
+	document.CreationInfo.Created = time.Now().UTC().Format(time.RFC3339)
+	for i := range document.Packages {
+		for j := range document.Packages[i].Annotations {
+			document.Packages[i].Annotations[j].AnnotationDate = document.CreationInfo.Created
+		}
+	}
+	if tagValue {
+		return document.toTagValue(), nil
+	}
+	out, err := json.Marshal(document)
+	if err != nil {
+		return "", errorutils.CheckError(err)
+	}
+	return clientUtils.IndentJson(out), nil
+}
+
+// The SPDX document is created out of the CycloneDX BOM, so that both formats describe the scanned components the same way.
+func createSpdxDocument(results []services.ScanResponse, suppressed []SuppressedScanResults, dependencyTrees []*services.GraphNode) *SpdxDocument {
+	bom := createCycloneDxBom(results, suppressed, dependencyTrees)
+	document := &SpdxDocument{
+		SpdxVersion:  spdxVersion,
+		DataLicense:  spdxDataLicense,
+		SpdxId:       spdxDocumentId,
+		Name:         spdxDefaultDocumentName,
+		CreationInfo: SpdxCreationInfo{},
+	}
+	for _, tool := range *bom.Metadata.Tools {
+		creator := "Tool: " + tool.Name
+		if tool.Version != "" {
+			creator += "-" + tool.Version
+		}
+		document.CreationInfo.Creators = append(document.CreationInfo.Creators, creator)
+	}
+
+	ids := newSpdxIdGenerator()
+	var roots []cdx.Component
+	if bom.Metadata.Component != nil {
+		root := *bom.Metadata.Component
+		roots = append(roots, root)
+		document.Name = spdxInvalidIdChars.ReplaceAllString(root.Name, "-")
+		document.Packages = append(document.Packages, toSpdxPackage(root, ids.get(root.BOMRef)))
+	}
+	for _, component := range *bom.Components {
+		if component.Type == cdx.ComponentTypeApplication {
+			roots = append(roots, component)
+		}
+		document.Packages = append(document.Packages, toSpdxPackage(component, ids.get(component.BOMRef)))
+	}
+	for _, root := range roots {
+		document.Relationships = append(document.Relationships, SpdxRelationship{SpdxElementId: spdxDocumentId, RelationshipType: spdxDescribes, RelatedSpdxElement: ids.get(root.BOMRef)})
+	}
+	for _, dependency := range *bom.Dependencies {
+		for _, dependsOn := range *dependency.Dependencies {
+			document.Relationships = append(document.Relationships, SpdxRelationship{SpdxElementId: ids.get(dependency.Ref), RelationshipType: spdxDependsOn, RelatedSpdxElement: ids.get(dependsOn)})
+		}
+	}
+	annotate := document.newReviewAnnotator(ids)
+	document.addLicenseViolationAnnotations(bom, annotate)
+	document.addSuppressionAnnotations(suppressed, annotate)
+	return document
+}
+
+// Returns a function that adds a review annotation with the given comment to the package of a component, if the document contains it.
+func (sd *SpdxDocument) newReviewAnnotator(ids *spdxIdGenerator) func(componentId, comment string) {
+	packageIndexes := make(map[string]int)
+	for i, spdxPackage := range sd.Packages {
+		packageIndexes[spdxPackage.SpdxId] = i
+	}
+	annotator := xrayToolName
+	if len(sd.CreationInfo.Creators) > 0 {
+		annotator = strings.TrimPrefix(sd.CreationInfo.Creators[len(sd.CreationInfo.Creators)-1], "Tool: ")
+	}
+	return func(componentId, comment string) {
+		if i, exist := packageIndexes[ids.get(componentId)]; exist {
+			sd.Packages[i].Annotations = append(sd.Packages[i].Annotations, SpdxAnnotation{
+				AnnotationType: spdxReviewAnnotation,
+				Annotator:      "Tool: " + annotator,
+				Comment:        comment,
+			})
+		}
+	}
+}
+
+// SPDX has no violations, so the license violations the BOM components hold are listed as review annotations of their packages.
+func (sd *SpdxDocument) addLicenseViolationAnnotations(bom *cdx.BOM, annotate func(componentId, comment string)) {
+	for _, component := range *bom.Components {
+		if component.Properties == nil {
+			continue
+		}
+		for _, property := range *component.Properties {
+			if property.Name == xrayLicenseViolationKey {
+				annotate(component.BOMRef, "License violation "+property.Value)
+			}
+		}
+	}
+}
+
+func (sd *SpdxDocument) addSuppressionAnnotations(suppressed []SuppressedScanResults, annotate func(componentId, comment string)) {
+	for _, suppressedResults := range suppressed {
+		detail := suppressedResults.Rule.getSuppressionDetail()
+		forEachIssue(suppressedResults.Results, func(issueDescription, componentId string) {
+			annotate(componentId, issueDescription+" - "+detail)
+		})
+	}
+}
+
+func toSpdxPackage(component cdx.Component, spdxId string) SpdxPackage {
+	name := component.Name
+	if component.Group != "" {
+		name = component.Group + ":" + component.Name
+	}
+	spdxPackage := SpdxPackage{
+		Name:             name,
+		SpdxId:           spdxId,
+		VersionInfo:      component.Version,
+		DownloadLocation: spdxNoAssertion,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  toSpdxLicenseExpression(component.Licenses),
+	}
+	if component.PackageURL != "" {
+		spdxPackage.ExternalRefs = []SpdxExternalRef{{ReferenceCategory: spdxPackageManager, ReferenceType: spdxPurlReferenceType, ReferenceLocator: component.PackageURL}}
+	}
+	return spdxPackage
+}
+
+// Converts the licenses Xray returned for a component to an SPDX license expression.
+// License keys which aren't valid SPDX identifiers are converted to LicenseRef identifiers.
+func toSpdxLicenseExpression(licenses *cdx.Licenses) string {
+	if licenses == nil {
+		return spdxNoAssertion
+	}
+	var licenseIds []string
+	for _, license := range *licenses {
+		if license.License == nil || license.License.ID == "" || license.License.ID == xrayUnknownLicense {
+			continue
+		}
+		licenseId := license.License.ID
+		if spdxInvalidLicenseChars.MatchString(licenseId) {
+			licenseId = "LicenseRef-" + spdxInvalidIdChars.ReplaceAllString(licenseId, "-")
+		}
+		licenseIds = append(licenseIds, licenseId)
+	}
+	if len(licenseIds) == 0 {
+		return spdxNoAssertion
+	}
+	return strings.Join(licenseIds, " AND ")
+}
+
+func (sd *SpdxDocument) toTagValue() string {
+	var content strings.Builder
+	writeTag := func(tag, value string) {
+		if value != "" {
+			content.WriteString(fmt.Sprintf("%s: %s\n", tag, value))
+		}
+	}
+	writeTag("SPDXVersion", sd.SpdxVersion)
+	writeTag("DataLicense", sd.DataLicense)
+	writeTag("SPDXID", sd.SpdxId)
+	writeTag("DocumentName", sd.Name)
+	writeTag("DocumentNamespace", sd.DocumentNamespace)
+	for _, creator := range sd.CreationInfo.Creators {
+		writeTag("Creator", creator)
+	}
+	writeTag("Created", sd.CreationInfo.Created)
+	for _, spdxPackage := range sd.Packages {
+		content.WriteString("\n##### Package: " + spdxPackage.Name + "\n\n")
+		writeTag("PackageName", spdxPackage.Name)
+		writeTag("SPDXID", spdxPackage.SpdxId)
+		writeTag("PackageVersion", spdxPackage.VersionInfo)
+		writeTag("PackageDownloadLocation", spdxPackage.DownloadLocation)
+		writeTag("FilesAnalyzed", fmt.Sprint(spdxPackage.FilesAnalyzed))
+		writeTag("PackageLicenseConcluded", spdxPackage.LicenseConcluded)
+		writeTag("PackageLicenseDeclared", spdxPackage.LicenseDeclared)
+		for _, externalRef := range spdxPackage.ExternalRefs {
+			writeTag("ExternalRef", strings.Join([]string{externalRef.ReferenceCategory, externalRef.ReferenceType, externalRef.ReferenceLocator}, " "))
+		}
+		for _, annotation := range spdxPackage.Annotations {
+			writeTag("Annotator", annotation.Annotator)
+			writeTag("AnnotationDate", annotation.AnnotationDate)
+			writeTag("AnnotationType", annotation.AnnotationType)
+			writeTag("SPDXREF", spdxPackage.SpdxId)
+			writeTag("AnnotationComment", "<text>"+annotation.Comment+"</text>")
+		}
+	}
+	if len(sd.Relationships) > 0 {
+		content.WriteString("\n##### Relationships\n\n")
+	}
+	for _, relationship := range sd.Relationships {
+		writeTag("Relationship", strings.Join([]string{relationship.SpdxElementId, relationship.RelationshipType, relationship.RelatedSpdxElement}, " "))
+	}
+	return content.String()
+}
+
+// Generates unique SPDX identifiers for Xray component IDs.
+// SPDX identifiers may contain only letters, numbers, '.' and '-'.
+type spdxIdGenerator struct {
+	ids     map[string]string
+	usedIds map[string]bool
+}
+
+func newSpdxIdGenerator() *spdxIdGenerator {
+	return &spdxIdGenerator{ids: make(map[string]string), usedIds: make(map[string]bool)}
+}
+
+func (sig *spdxIdGenerator) get(componentId string) string {
+	if spdxId, exist := sig.ids[componentId]; exist {
+		return spdxId
+	}
+	baseId := spdxPackageIdPrefix + strings.Trim(spdxInvalidIdChars.ReplaceAllString(componentId, "-"), "-")
+	spdxId := baseId
+	for i := 1; sig.usedIds[spdxId]; i++ {
+		spdxId = fmt.Sprintf("%s-%d", baseId, i)
+	}
+	sig.ids[componentId] = spdxId
+	sig.usedIds[spdxId] = true
+	return spdxId
+}
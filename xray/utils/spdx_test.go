@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-client-go/xray/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSpdxDocument(t *testing.T) {
+	dependencyTree := &services.GraphNode{
+		Id: "gav://org.example:app:1.0.0",
+		Nodes: []*services.GraphNode{
+			{Id: "gav://junit:junit:4.13.2", Nodes: []*services.GraphNode{{Id: "gav://org.hamcrest:hamcrest-core:1.3"}}},
+		},
+	}
+	results := []services.ScanResponse{{
+		Licenses: []services.License{
+			{Key: "EPL-1.0", Components: map[string]services.Component{"gav://junit:junit:4.13.2": {}}},
+			{Key: "BSD 3-Clause", Components: map[string]services.Component{"gav://org.hamcrest:hamcrest-core:1.3": {}}},
+		},
+	}}
+
+	document := createSpdxDocument(results, nil, []*services.GraphNode{dependencyTree})
+	assert.Equal(t, "SPDX-2.3", document.SpdxVersion)
+	assert.Equal(t, "app", document.Name)
+	expectedPackages := []SpdxPackage{
+		{Name: "org.example:app", SpdxId: "SPDXRef-Package-gav-org.example-app-1.0.0", VersionInfo: "1.0.0", DownloadLocation: "NOASSERTION", LicenseConcluded: "NOASSERTION", LicenseDeclared: "NOASSERTION",
+			ExternalRefs: []SpdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:maven/org.example/app@1.0.0"}}},
+		{Name: "junit:junit", SpdxId: "SPDXRef-Package-gav-junit-junit-4.13.2", VersionInfo: "4.13.2", DownloadLocation: "NOASSERTION", LicenseConcluded: "NOASSERTION", LicenseDeclared: "EPL-1.0",
+			ExternalRefs: []SpdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:maven/junit/junit@4.13.2"}}},
+		{Name: "org.hamcrest:hamcrest-core", SpdxId: "SPDXRef-Package-gav-org.hamcrest-hamcrest-core-1.3", VersionInfo: "1.3", DownloadLocation: "NOASSERTION", LicenseConcluded: "NOASSERTION", LicenseDeclared: "LicenseRef-BSD-3-Clause",
+			ExternalRefs: []SpdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:maven/org.hamcrest/hamcrest-core@1.3"}}},
+	}
+	assert.Equal(t, expectedPackages, document.Packages)
+	expectedRelationships := []SpdxRelationship{
+		{SpdxElementId: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSpdxElement: "SPDXRef-Package-gav-org.example-app-1.0.0"},
+		{SpdxElementId: "SPDXRef-Package-gav-junit-junit-4.13.2", RelationshipType: "DEPENDS_ON", RelatedSpdxElement: "SPDXRef-Package-gav-org.hamcrest-hamcrest-core-1.3"},
+		{SpdxElementId: "SPDXRef-Package-gav-org.example-app-1.0.0", RelationshipType: "DEPENDS_ON", RelatedSpdxElement: "SPDXRef-Package-gav-junit-junit-4.13.2"},
+	}
+	assert.Equal(t, expectedRelationships, document.Relationships)
+
+	tagValue := document.toTagValue()
+	assert.True(t, strings.HasPrefix(tagValue, "SPDXVersion: SPDX-2.3\nDataLicense: CC0-1.0\nSPDXID: SPDXRef-DOCUMENT\nDocumentName: app\n"))
+	assert.Contains(t, tagValue, "PackageLicenseDeclared: EPL-1.0\nExternalRef: PACKAGE-MANAGER purl pkg:maven/junit/junit@4.13.2\n")
+	assert.Contains(t, tagValue, "Relationship: SPDXRef-Package-gav-org.example-app-1.0.0 DEPENDS_ON SPDXRef-Package-gav-junit-junit-4.13.2\n")
+}